@@ -0,0 +1,292 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"path/filepath"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
+	stream "gopkg.in/GetStream/stream-go2.v3"
+
+	"github.com/wisnuanggoro/go-getstream/cache"
+	"github.com/wisnuanggoro/go-getstream/config"
+	"github.com/wisnuanggoro/go-getstream/draft"
+	"github.com/wisnuanggoro/go-getstream/eventbus"
+	"github.com/wisnuanggoro/go-getstream/getstream"
+	"github.com/wisnuanggoro/go-getstream/graphqlapi"
+	"github.com/wisnuanggoro/go-getstream/handler"
+	"github.com/wisnuanggoro/go-getstream/middleware"
+	"github.com/wisnuanggoro/go-getstream/pubsub"
+	"github.com/wisnuanggoro/go-getstream/ratelimit"
+	"github.com/wisnuanggoro/go-getstream/realtime"
+	"github.com/wisnuanggoro/go-getstream/schedule"
+	"github.com/wisnuanggoro/go-getstream/streamwebhook"
+	"github.com/wisnuanggoro/go-getstream/tenant"
+	"github.com/wisnuanggoro/go-getstream/webhook"
+)
+
+// tenantApp is everything serving one tenant, from its Stream client to its
+// routes. Tenants share nothing but the process, the cache and the bus.
+type tenantApp struct {
+	tenant           tenant.Tenant
+	getstreamSvc     getstream.Service
+	router           *gin.Engine
+	websocketHandler handler.WebsocketHandler
+	workers          []func(ctx context.Context)
+}
+
+// tenantDeps are the parts of the process shared by every tenantApp
+type tenantDeps struct {
+	cfg            config.Config
+	topology       getstream.Topology
+	logger         *slog.Logger
+	registry       *prometheus.Registry
+	cache          cache.Cache
+	eventPublisher eventbus.Publisher
+	userRateLimits map[string]ratelimit.Limit
+	ipRateLimits   map[string]ratelimit.Limit
+	// Store files get the tenant ID in their name, unless a single tenant is
+	// configured from the environment
+	tenantFiles bool
+}
+
+func newTenantApp(deps tenantDeps, t tenant.Tenant) (*tenantApp, error) {
+	cfg := deps.cfg
+	app := &tenantApp{tenant: t}
+
+	// Metrics of each tenant are told apart by their tenant label
+	registerer := prometheus.WrapRegistererWith(prometheus.Labels{"tenant": t.ID}, deps.registry)
+	filePath := func(path string) string {
+		if !deps.tenantFiles {
+			return path
+		}
+		ext := filepath.Ext(path)
+		return strings.TrimSuffix(path, ext) + "." + t.ID + ext
+	}
+
+	// Initialize Getstream Client
+	getstreamClient, err := stream.NewClient(
+		t.APIKey,
+		t.APISecret,
+		stream.WithAPIRegion(t.APIRegion),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("initialize getstream client: %w", err)
+	}
+
+	// Initialize stores
+	scheduleStore, err := schedule.NewStore(cfg.ScheduleStore, filePath(cfg.ScheduleFilePath))
+	if err != nil {
+		return nil, fmt.Errorf("initialize schedule store: %w", err)
+	}
+
+	webhookStore, err := webhook.NewStore(cfg.WebhookStore, filePath(cfg.WebhookFilePath))
+	if err != nil {
+		return nil, fmt.Errorf("initialize webhook store: %w", err)
+	}
+
+	getstreamStore, err := getstream.NewStore(cfg.GetstreamStore, filePath(cfg.GetstreamFilePath))
+	if err != nil {
+		return nil, fmt.Errorf("initialize getstream store: %w", err)
+	}
+
+	draftStore := draft.NewMemoryStore()
+
+	// Initialize services
+	getstreamEvents := getstream.NewEvents()
+	getstreamSvc := getstream.NewService(getstreamClient, getstreamStore, deps.topology, cfg.SuggestionCacheTTL, getstreamEvents)
+	getstreamSvc = getstream.NewMetricsService(getstreamSvc, getstream.NewMetrics(registerer))
+	getstreamSvc = getstream.NewLoggingService(getstreamSvc, deps.logger, deps.topology)
+	getstreamSvc = getstream.NewResilientService(getstreamSvc, getstream.ResiliencePolicy{
+		MaxAttempts:      cfg.RetryMaxAttempts,
+		InitialBackoff:   cfg.RetryInitialBackoff,
+		MaxBackoff:       cfg.RetryMaxBackoff,
+		FailureThreshold: cfg.BreakerFailureThreshold,
+		OpenTimeout:      cfg.BreakerOpenTimeout,
+	})
+	var cacheInvalidator *getstream.CacheInvalidator
+	if deps.cache != nil {
+		getstreamCache := cache.Prefixed(deps.cache, "tenant:"+t.ID+":")
+		cacheInvalidator = getstream.NewCacheInvalidator(getstreamCache, deps.topology, getstreamSvc)
+		getstreamEvents.Listen(cacheInvalidator.OnEvent)
+		getstreamSvc = getstream.NewCachingService(getstreamSvc, getstreamCache, cfg.CacheDefaultTTL, cfg.CacheTTLs)
+	}
+	getstreamSvc = getstream.NewTracingService(getstreamSvc, deps.topology)
+	app.getstreamSvc = getstreamSvc
+	scheduleSvc := schedule.NewService(scheduleStore)
+	draftSvc := draft.NewService(draftStore)
+	webhookSvc := webhook.NewService(webhookStore)
+
+	// Publish changes to realtime clients
+	realtimeBroker := pubsub.NewBroker(cfg.RealtimeHistorySize, cfg.RealtimeHistoryTTL, cfg.RealtimeBufferSize)
+	getstreamEvents.Listen(realtime.NewHub(getstreamSvc, realtimeBroker).OnEvent)
+
+	// Queue partner webhooks of every change
+	getstreamEvents.Listen(func(ctx context.Context, event getstream.Event) {
+		if err := webhookSvc.Enqueue(event); err != nil {
+			slog.ErrorContext(ctx, "enqueue webhook deliveries", slog.String("event_id", event.ID), slog.String("error", err.Error()))
+		}
+	})
+
+	// Publish domain events to the message bus through the outbox
+	if deps.eventPublisher != nil {
+		eventOutbox, err := eventbus.NewOutbox(cfg.EventBusOutbox, filePath(cfg.EventBusOutboxFilePath))
+		if err != nil {
+			return nil, fmt.Errorf("initialize event outbox: %w", err)
+		}
+
+		eventRelay := eventbus.NewRelay(t.ID, eventOutbox, deps.eventPublisher, eventbus.RelayPolicy{
+			BatchSize:      cfg.EventBusBatchSize,
+			PollInterval:   cfg.EventBusPollInterval,
+			InitialBackoff: cfg.EventBusInitialBackoff,
+			MaxBackoff:     cfg.EventBusMaxBackoff,
+		})
+		getstreamEvents.Listen(eventRelay.OnEvent)
+		app.workers = append(app.workers, eventRelay.Run)
+	}
+
+	// Handle Stream callbacks
+	streamWebhooks := streamwebhook.NewDispatcher()
+	streamwebhook.RegisterMetrics(streamWebhooks, registerer)
+	if cacheInvalidator != nil {
+		streamWebhooks.OnActivityAdded(func(ctx context.Context, event streamwebhook.ActivityAdded) error {
+			return cacheInvalidator.InvalidateFeed(ctx, event.Feed)
+		})
+		streamWebhooks.OnActivityRemoved(func(ctx context.Context, event streamwebhook.ActivityRemoved) error {
+			return cacheInvalidator.InvalidateFeed(ctx, event.Feed)
+		})
+		streamWebhooks.OnReactionAdded(func(ctx context.Context, event streamwebhook.ReactionAdded) error {
+			return cacheInvalidator.InvalidateLikes(ctx, event.Reaction.ActivityID)
+		})
+		streamWebhooks.OnReactionRemoved(func(ctx context.Context, event streamwebhook.ReactionRemoved) error {
+			return cacheInvalidator.InvalidateLikes(ctx, event.Reaction.ActivityID)
+		})
+	}
+
+	// Initialize workers
+	scheduleWorker := schedule.NewWorker(scheduleStore, getstreamSvc, cfg.SchedulePollInterval, cfg.ScheduleMaxAttempts)
+	webhookWorker := webhook.NewWorker(webhookStore, webhook.DeliveryPolicy{
+		PollInterval:   cfg.WebhookPollInterval,
+		Timeout:        cfg.WebhookTimeout,
+		Concurrency:    cfg.WebhookConcurrency,
+		MaxAttempts:    cfg.WebhookMaxAttempts,
+		InitialBackoff: cfg.WebhookInitialBackoff,
+		MaxBackoff:     cfg.WebhookMaxBackoff,
+		Retention:      cfg.WebhookRetention,
+	})
+	app.workers = append(app.workers, scheduleWorker.Run, webhookWorker.Run)
+
+	// Initialize handlers
+	getstreamHandler := handler.NewGetstreamHandler(getstreamSvc, scheduleSvc)
+	scheduleHandler := handler.NewScheduleHandler(scheduleSvc)
+	draftHandler := handler.NewDraftHandler(draftSvc)
+	timelineStreamHandler := handler.NewTimelineStreamHandler(getstreamSvc, realtimeBroker, cfg.SSEHeartbeatInterval)
+	webhookHandler := handler.NewWebhookHandler(webhookSvc)
+	streamWebhookHandler := handler.NewStreamWebhookHandler(t.APISecret, streamWebhooks)
+	app.websocketHandler = handler.NewWebsocketHandler(getstreamSvc, realtimeBroker, cfg.WSAllowedOrigins, cfg.WSMaxSubscriptions, cfg.WSSendBufferSize, cfg.WSPingInterval)
+	graphqlHandler, err := graphqlapi.NewHandler(getstreamSvc, cfg.GraphQLMaxComplexity, cfg.GraphQLMaxDepth)
+	if err != nil {
+		return nil, fmt.Errorf("build GraphQL schema: %w", err)
+	}
+
+	// Initialize gin router, requests reach it through the tenant
+	// middleware of the main router
+	router := gin.New()
+	router.Use(
+		gin.Recovery(),
+		otelgin.Middleware(cfg.TracingServiceName),
+		middleware.Logger(deps.logger),
+		middleware.Metrics(registerer),
+	)
+	router.GET("/graphql", graphqlHandler.Serve)
+	router.POST("/graphql", graphqlHandler.Serve)
+
+	v1 := router.Group("/api/v1")
+	v1.Use(middleware.RateLimit(ratelimit.NewLimiter(), deps.userRateLimits, deps.ipRateLimits))
+	{
+		// Posting
+		v1.POST("/post", getstreamHandler.AddPostByUserSerial)
+		v1.GET("/post/:userSerial/summary", getstreamHandler.GetPostByUserSerial)
+		v1.GET("/post/:userSerial/detail", getstreamHandler.GetPostDetailByUserSerial)
+		v1.PUT("/post", getstreamHandler.EditPostByPostID)
+		// Takes a post ID, gin panics unless it's named like its siblings' wildcard
+		v1.GET("/post/:userSerial/history", getstreamHandler.GetPostHistoryByPostID)
+		v1.DELETE("/post", getstreamHandler.DeletePostByPostID)
+
+		// Scheduled posts
+		v1.GET("/schedule/:userSerial", scheduleHandler.GetScheduledPostsByUserSerial)
+		v1.PUT("/schedule", scheduleHandler.ReschedulePost)
+		v1.DELETE("/schedule", scheduleHandler.CancelScheduledPost)
+
+		// Drafts
+		v1.POST("/draft", draftHandler.CreateDraft)
+		v1.GET("/draft/:userSerial", draftHandler.GetDraftsByUserSerial)
+		v1.GET("/draft/:userSerial/:draftID", draftHandler.GetDraftByDraftID)
+		v1.PUT("/draft", draftHandler.UpdateDraft)
+		v1.DELETE("/draft", draftHandler.DeleteDraft)
+
+		// Timeline
+		v1.GET("/timeline/:userSerial/summary", getstreamHandler.GetTimelineByUserSerial)
+		v1.GET("/timeline/:userSerial/detail", getstreamHandler.GetDetailTimelineByUserSerial)
+		v1.GET("/timeline/:userSerial/stream", timelineStreamHandler.StreamTimelineByUserSerial)
+
+		// Realtime timeline, notification and reaction updates
+		v1.GET("/ws", app.websocketHandler.Serve)
+
+		// Follower
+		v1.POST("/user/follow", getstreamHandler.Follow)
+		v1.POST("/user/unfollow", getstreamHandler.Unfollow)
+		v1.GET("/user/follower/:userSerial", getstreamHandler.GetFeedFollowersByUserSerial)
+		v1.GET("/user/followed/:userSerial", getstreamHandler.GetFollowedFeedsByUserSerial)
+		v1.GET("/user/:userSerial/suggestions", getstreamHandler.GetFollowSuggestionsByUserSerial)
+		v1.GET("/user/:userSerial/relationship/:otherSerial", getstreamHandler.GetRelationship)
+		v1.GET("/user/:userSerial/relationships", getstreamHandler.GetRelationships)
+
+		// Private accounts and follow requests, following a private
+		// user through `/user/follow` creates a pending request
+		v1.PUT("/user/:userSerial/privacy", getstreamHandler.SetPrivateAccount)
+		v1.GET("/user/:userSerial/follow-requests/incoming", getstreamHandler.GetIncomingFollowRequests)
+		v1.GET("/user/:userSerial/follow-requests/outgoing", getstreamHandler.GetOutgoingFollowRequests)
+		v1.POST("/user/follow-requests/:requestID/approve", getstreamHandler.ApproveFollowRequest)
+		v1.POST("/user/follow-requests/:requestID/reject", getstreamHandler.RejectFollowRequest)
+
+		// Block and mute
+		v1.POST("/user/block", getstreamHandler.BlockUser)
+		v1.POST("/user/unblock", getstreamHandler.UnblockUser)
+		v1.GET("/user/blocked/:userSerial", getstreamHandler.GetBlockedUsersByUserSerial)
+		v1.POST("/user/mute", getstreamHandler.MuteUser)
+		v1.POST("/user/unmute", getstreamHandler.UnmuteUser)
+		v1.GET("/user/muted/:userSerial", getstreamHandler.GetMutedUsersByUserSerial)
+
+		// Like Reaction
+		v1.POST("/like", getstreamHandler.AddLikeToPostID)
+		v1.GET("/like/:postID", getstreamHandler.RetrieveLikeDetailOnPostID)
+		v1.GET("/like/:postID/:nextLikeID", getstreamHandler.RetrieveLikeDetailOnPostIDWithPagination)
+		v1.DELETE("/like/:reactionID", getstreamHandler.RemoveLikeByReactionID)
+
+		// Callbacks, each Stream app signs with its own secret
+		v1.POST("/webhooks/stream", streamWebhookHandler.ReceiveStreamWebhook)
+
+		// Partner webhooks
+		v1.POST("/webhooks/subscriptions", webhookHandler.CreateSubscription)
+		v1.GET("/webhooks/subscriptions", webhookHandler.GetSubscriptions)
+		v1.DELETE("/webhooks/subscriptions/:subscriptionID", webhookHandler.DeleteSubscription)
+		v1.GET("/webhooks/subscriptions/:subscriptionID/deliveries", webhookHandler.GetDeliveriesBySubscriptionID)
+		v1.POST("/webhooks/deliveries/:deliveryID/replay", webhookHandler.ReplayDelivery)
+	}
+	app.router = router
+
+	return app, nil
+}
+
+// run starts the workers of the tenant, their logs are tagged with it
+func (a *tenantApp) run(ctx context.Context) {
+	ctx = tenant.WithID(ctx, a.tenant.ID)
+	for _, worker := range a.workers {
+		go worker(ctx)
+	}
+}
@@ -0,0 +1,27 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Cache stores opaque values under string keys for a limited time
+type Cache interface {
+	// Get returns the value stored under key, ok is false on a miss
+	Get(ctx context.Context, key string) (value []byte, ok bool, err error)
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	Delete(ctx context.Context, keys ...string) error
+}
+
+// New builds the cache selected by backend, either `memory` or `redis`
+func New(backend string, memorySize int, redisAddr, redisPassword string, redisDB int) (Cache, error) {
+	switch backend {
+	case "memory":
+		return NewMemoryCache(memorySize), nil
+	case "redis":
+		return NewRedisCache(redisAddr, redisPassword, redisDB), nil
+	default:
+		return nil, fmt.Errorf("unknown cache backend %q", backend)
+	}
+}
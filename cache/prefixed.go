@@ -0,0 +1,36 @@
+package cache
+
+import (
+	"context"
+	"time"
+)
+
+// prefixed keeps the keys of one user of a shared cache apart from the others
+type prefixed struct {
+	next   Cache
+	prefix string
+}
+
+// Prefixed stores every key of c under prefix
+func Prefixed(c Cache, prefix string) Cache {
+	return &prefixed{
+		next:   c,
+		prefix: prefix,
+	}
+}
+
+func (p *prefixed) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	return p.next.Get(ctx, p.prefix+key)
+}
+
+func (p *prefixed) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	return p.next.Set(ctx, p.prefix+key, value, ttl)
+}
+
+func (p *prefixed) Delete(ctx context.Context, keys ...string) error {
+	prefixedKeys := make([]string, len(keys))
+	for i, key := range keys {
+		prefixedKeys[i] = p.prefix + key
+	}
+	return p.next.Delete(ctx, prefixedKeys...)
+}
@@ -1,18 +1,147 @@
 package config
 
 import (
+	"errors"
+	"strconv"
+	"time"
+
 	"github.com/kelseyhightower/envconfig"
 )
 
 // Config struct to implement model of this service's configuration
 type Config struct {
-	// Setting port for gin
-	Port string `envconfig:"PORT" default:""`
+	// Logging level, one of debug, info, warn or error
+	LogLevel string `envconfig:"LOG_LEVEL" default:"info"`
+
+	// Tracing exporter, one of none, stdout or otlp. The otlp exporter reads
+	// the standard OTEL_EXPORTER_OTLP_* variables
+	TracingExporter    string  `envconfig:"TRACING_EXPORTER" default:"none"`
+	TracingServiceName string  `envconfig:"TRACING_SERVICE_NAME" default:"go-getstream"`
+	TracingSampleRatio float64 `envconfig:"TRACING_SAMPLE_RATIO" default:"1"`
+
+	// HTTP server, TLS is enabled when both cert and key files are set
+	Host                  string        `envconfig:"HOST" default:""`
+	Port                  string        `envconfig:"PORT" default:"8080"`
+	ServerReadTimeout     time.Duration `envconfig:"SERVER_READ_TIMEOUT" default:"10s"`
+	ServerWriteTimeout    time.Duration `envconfig:"SERVER_WRITE_TIMEOUT" default:"30s"`
+	ServerIdleTimeout     time.Duration `envconfig:"SERVER_IDLE_TIMEOUT" default:"120s"`
+	ServerShutdownTimeout time.Duration `envconfig:"SERVER_SHUTDOWN_TIMEOUT" default:"20s"`
+	TLSCertFile           string        `envconfig:"TLS_CERT_FILE" default:""`
+	TLSKeyFile            string        `envconfig:"TLS_KEY_FILE" default:""`
+
+	// gRPC server, disabled when the port is empty
+	GRPCPort string `envconfig:"GRPC_PORT" default:"9090"`
+
+	// GraphQL limits, complexity counts each field once per item of the
+	// lists it's nested in
+	GraphQLMaxComplexity int `envconfig:"GRAPHQL_MAX_COMPLEXITY" default:"5000"`
+	GraphQLMaxDepth      int `envconfig:"GRAPHQL_MAX_DEPTH" default:"8"`
 
-	// GoStream
+	// Realtime timeline events, recent events of each timeline are kept so
+	// reconnecting clients can resume from the last one they received
+	RealtimeHistorySize  int           `envconfig:"REALTIME_HISTORY_SIZE" default:"100"`
+	RealtimeHistoryTTL   time.Duration `envconfig:"REALTIME_HISTORY_TTL" default:"5m"`
+	RealtimeBufferSize   int           `envconfig:"REALTIME_BUFFER_SIZE" default:"64"`
+	SSEHeartbeatInterval time.Duration `envconfig:"SSE_HEARTBEAT_INTERVAL" default:"15s"`
+
+	// WebSocket gateway, browsers are only accepted from the allowed origins
+	// or from the same origin when none are set
+	WSAllowedOrigins   []string      `envconfig:"WS_ALLOWED_ORIGINS" default:""`
+	WSMaxSubscriptions int           `envconfig:"WS_MAX_SUBSCRIPTIONS" default:"20"`
+	WSSendBufferSize   int           `envconfig:"WS_SEND_BUFFER_SIZE" default:"256"`
+	WSPingInterval     time.Duration `envconfig:"WS_PING_INTERVAL" default:"30s"`
+
+	// GoStream, the app of the single `default` tenant when no tenants file
+	// is set
 	GoStreamAPIKey    string `envconfig:"GOSTREAM_API_KEY" default:""`
 	GoStreamAPISecret string `envconfig:"GOSTREAM_API_SECRET" default:""`
 	GoStreamAPIRegion string `envconfig:"GOSTREAM_API_REGION" default:""`
+
+	// Tenants, each with its own Stream app, as a JSON array of
+	// `{"id", "apiKey", "apiSecret", "apiRegion", "hosts"}`. Requests are
+	// resolved by their host, on other hosts they name their tenant in the
+	// tenant header.
+	TenantsFile  string `envconfig:"TENANTS_FILE" default:""`
+	TenantHeader string `envconfig:"TENANT_HEADER" default:"X-Tenant-ID"`
+
+	// Stream feed groups, notification and aggregated groups are optional.
+	// Follow rules are `<follower group>:<followed group>` pairs, when a user
+	// follows another their follower feed follows the other's followed feed.
+	FeedGroupPost         string   `envconfig:"FEED_GROUP_POST" default:"user"`
+	FeedGroupTimeline     string   `envconfig:"FEED_GROUP_TIMELINE" default:"timeline"`
+	FeedGroupNotification string   `envconfig:"FEED_GROUP_NOTIFICATION" default:""`
+	FeedGroupAggregated   string   `envconfig:"FEED_GROUP_AGGREGATED" default:""`
+	FeedPostVerb          string   `envconfig:"FEED_POST_VERB" default:"post"`
+	FeedFollowRules       []string `envconfig:"FEED_FOLLOW_RULES" default:"timeline:user,user:user"`
+
+	// Retries of idempotent Stream reads and per operation circuit breaker
+	RetryMaxAttempts        int           `envconfig:"RETRY_MAX_ATTEMPTS" default:"3"`
+	RetryInitialBackoff     time.Duration `envconfig:"RETRY_INITIAL_BACKOFF" default:"100ms"`
+	RetryMaxBackoff         time.Duration `envconfig:"RETRY_MAX_BACKOFF" default:"2s"`
+	BreakerFailureThreshold int           `envconfig:"BREAKER_FAILURE_THRESHOLD" default:"5"`
+	BreakerOpenTimeout      time.Duration `envconfig:"BREAKER_OPEN_TIMEOUT" default:"30s"`
+
+	// Rate limits of write routes as `<METHOD> <route template>=<requests>/<period>`,
+	// per acting user and per client IP. Route templates may hold `:param`.
+	RateLimits   []string `envconfig:"RATE_LIMITS" default:"POST /api/v1/post=10/1m,POST /api/v1/like=60/1m,POST /api/v1/user/follow=30/1m"`
+	RateLimitsIP []string `envconfig:"RATE_LIMITS_IP" default:"POST /api/v1/post=50/1m,POST /api/v1/like=300/1m,POST /api/v1/user/follow=150/1m"`
+
+	// Read-through cache of Service reads, backend is none, memory or redis.
+	// CACHE_TTLS overrides the TTL per Service method, e.g.
+	// `GetTimelineByUserSerial:10s,RetrieveLikeDetailOnPostID:5s`
+	CacheBackend    string                   `envconfig:"CACHE_BACKEND" default:"none"`
+	CacheMemorySize int                      `envconfig:"CACHE_MEMORY_SIZE" default:"10000"`
+	CacheDefaultTTL time.Duration            `envconfig:"CACHE_DEFAULT_TTL" default:"30s"`
+	CacheTTLs       map[string]time.Duration `envconfig:"CACHE_TTLS" default:""`
+	RedisAddr       string                   `envconfig:"REDIS_ADDR" default:"localhost:6379"`
+	RedisPassword   string                   `envconfig:"REDIS_PASSWORD" default:""`
+	RedisDB         int                      `envconfig:"REDIS_DB" default:"0"`
+
+	// Private accounts, follow requests, blocks, mutes and edit history,
+	// store is either `memory` or `file`
+	GetstreamStore    string `envconfig:"GETSTREAM_STORE" default:"memory"`
+	GetstreamFilePath string `envconfig:"GETSTREAM_FILE_PATH" default:"getstream_state.json"`
+
+	// Scheduled posts, store is either `memory` or `file`
+	ScheduleStore        string        `envconfig:"SCHEDULE_STORE" default:"memory"`
+	ScheduleFilePath     string        `envconfig:"SCHEDULE_FILE_PATH" default:"scheduled_posts.json"`
+	SchedulePollInterval time.Duration `envconfig:"SCHEDULE_POLL_INTERVAL" default:"10s"`
+	// Attempts before a scheduled post is given up as failed, one per poll
+	ScheduleMaxAttempts int `envconfig:"SCHEDULE_MAX_ATTEMPTS" default:"3"`
+
+	// Partner webhooks, store is either `memory` or `file`. Failed deliveries
+	// are retried with a jittered exponential backoff.
+	WebhookStore          string        `envconfig:"WEBHOOK_STORE" default:"memory"`
+	WebhookFilePath       string        `envconfig:"WEBHOOK_FILE_PATH" default:"webhooks.json"`
+	WebhookPollInterval   time.Duration `envconfig:"WEBHOOK_POLL_INTERVAL" default:"1s"`
+	WebhookTimeout        time.Duration `envconfig:"WEBHOOK_TIMEOUT" default:"10s"`
+	WebhookConcurrency    int           `envconfig:"WEBHOOK_CONCURRENCY" default:"8"`
+	WebhookMaxAttempts    int           `envconfig:"WEBHOOK_MAX_ATTEMPTS" default:"8"`
+	WebhookInitialBackoff time.Duration `envconfig:"WEBHOOK_INITIAL_BACKOFF" default:"10s"`
+	WebhookMaxBackoff     time.Duration `envconfig:"WEBHOOK_MAX_BACKOFF" default:"1h"`
+	WebhookRetention      time.Duration `envconfig:"WEBHOOK_RETENTION" default:"168h"`
+
+	// Domain events published to a message bus, backend is none, memory,
+	// nats or kafka. Events wait in the outbox, `memory` or `file`, until
+	// the bus accepts them.
+	EventBusBackend        string        `envconfig:"EVENTBUS_BACKEND" default:"none"`
+	EventBusOutbox         string        `envconfig:"EVENTBUS_OUTBOX" default:"memory"`
+	EventBusOutboxFilePath string        `envconfig:"EVENTBUS_OUTBOX_FILE_PATH" default:"event_outbox.json"`
+	EventBusBatchSize      int           `envconfig:"EVENTBUS_BATCH_SIZE" default:"100"`
+	EventBusPollInterval   time.Duration `envconfig:"EVENTBUS_POLL_INTERVAL" default:"1s"`
+	EventBusInitialBackoff time.Duration `envconfig:"EVENTBUS_INITIAL_BACKOFF" default:"500ms"`
+	EventBusMaxBackoff     time.Duration `envconfig:"EVENTBUS_MAX_BACKOFF" default:"30s"`
+	NATSURL                string        `envconfig:"NATS_URL" default:"nats://localhost:4222"`
+	NATSSubjectPrefix      string        `envconfig:"NATS_SUBJECT_PREFIX" default:"getstream.events"`
+	KafkaBrokers           []string      `envconfig:"KAFKA_BROKERS" default:"localhost:9092"`
+	KafkaTopic             string        `envconfig:"KAFKA_TOPIC" default:"getstream-events"`
+
+	// How long computed follow suggestions are reused
+	SuggestionCacheTTL time.Duration `envconfig:"SUGGESTION_CACHE_TTL" default:"10m"`
+
+	// Readiness checks
+	HealthCheckTimeout  time.Duration `envconfig:"HEALTH_CHECK_TIMEOUT" default:"3s"`
+	HealthCheckCacheTTL time.Duration `envconfig:"HEALTH_CHECK_CACHE_TTL" default:"10s"`
 }
 
 // Get to get defined configuration
@@ -21,3 +150,57 @@ func Get() Config {
 	envconfig.MustProcess("", &cfg)
 	return cfg
 }
+
+// Validate reports configuration that would keep this service from working
+func (c Config) Validate() error {
+	if c.TenantsFile == "" && (c.GoStreamAPIKey == "" || c.GoStreamAPISecret == "") {
+		return errors.New("GOSTREAM_API_KEY and GOSTREAM_API_SECRET are mandatory without TENANTS_FILE")
+	}
+	if c.TenantHeader == "" {
+		return errors.New("TENANT_HEADER is mandatory")
+	}
+	if _, err := strconv.ParseUint(c.Port, 10, 16); err != nil {
+		return errors.New("PORT must be a valid port number")
+	}
+	if c.GRPCPort != "" {
+		if _, err := strconv.ParseUint(c.GRPCPort, 10, 16); err != nil {
+			return errors.New("GRPC_PORT must be a valid port number")
+		}
+		if c.GRPCPort == c.Port {
+			return errors.New("GRPC_PORT must differ from PORT")
+		}
+	}
+	if (c.TLSCertFile == "") != (c.TLSKeyFile == "") {
+		return errors.New("TLS_CERT_FILE and TLS_KEY_FILE must be set together")
+	}
+	if c.CacheBackend != "none" && c.CacheBackend != "memory" && c.CacheBackend != "redis" {
+		return errors.New("CACHE_BACKEND must be one of none, memory or redis")
+	}
+	if c.GetstreamStore != "memory" && c.GetstreamStore != "file" {
+		return errors.New("GETSTREAM_STORE must be either memory or file")
+	}
+	if c.ScheduleStore != "memory" && c.ScheduleStore != "file" {
+		return errors.New("SCHEDULE_STORE must be either memory or file")
+	}
+	if c.ScheduleMaxAttempts < 1 {
+		return errors.New("SCHEDULE_MAX_ATTEMPTS must be at least 1")
+	}
+	switch c.EventBusBackend {
+	case "none", "memory", "nats", "kafka":
+	default:
+		return errors.New("EVENTBUS_BACKEND must be one of none, memory, nats or kafka")
+	}
+	if c.EventBusOutbox != "memory" && c.EventBusOutbox != "file" {
+		return errors.New("EVENTBUS_OUTBOX must be either memory or file")
+	}
+	if c.EventBusBatchSize < 1 {
+		return errors.New("EVENTBUS_BATCH_SIZE must be at least 1")
+	}
+	if c.WebhookStore != "memory" && c.WebhookStore != "file" {
+		return errors.New("WEBHOOK_STORE must be either memory or file")
+	}
+	if c.WebhookMaxAttempts < 1 {
+		return errors.New("WEBHOOK_MAX_ATTEMPTS must be at least 1")
+	}
+	return nil
+}
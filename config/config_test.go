@@ -0,0 +1,50 @@
+package config
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/kelseyhightower/envconfig"
+)
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name string
+		env  map[string]string
+		// wantErr is the variable named by the error, empty when valid
+		wantErr string
+	}{
+		{name: "defaults with a Stream app", env: map[string]string{}},
+		{name: "tenants file instead of a Stream app", env: map[string]string{"GOSTREAM_API_KEY": "", "GOSTREAM_API_SECRET": "", "TENANTS_FILE": "tenants.json"}},
+		{name: "missing Stream app", env: map[string]string{"GOSTREAM_API_SECRET": ""}, wantErr: "GOSTREAM_API_KEY"},
+		{name: "invalid port", env: map[string]string{"PORT": "http"}, wantErr: "PORT"},
+		{name: "gRPC on the HTTP port", env: map[string]string{"PORT": "8080", "GRPC_PORT": "8080"}, wantErr: "GRPC_PORT"},
+		{name: "certificate without key", env: map[string]string{"TLS_CERT_FILE": "cert.pem"}, wantErr: "TLS_CERT_FILE"},
+		{name: "unknown getstream store", env: map[string]string{"GETSTREAM_STORE": "sql"}, wantErr: "GETSTREAM_STORE"},
+		{name: "no publish attempt", env: map[string]string{"SCHEDULE_MAX_ATTEMPTS": "0"}, wantErr: "SCHEDULE_MAX_ATTEMPTS"},
+		{name: "unknown event bus", env: map[string]string{"EVENTBUS_BACKEND": "amqp"}, wantErr: "EVENTBUS_BACKEND"},
+		{name: "empty batches", env: map[string]string{"EVENTBUS_BATCH_SIZE": "0"}, wantErr: "EVENTBUS_BATCH_SIZE"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("GOSTREAM_API_KEY", "key")
+			t.Setenv("GOSTREAM_API_SECRET", "secret")
+			for key, value := range tt.env {
+				t.Setenv(key, value)
+			}
+			var cfg Config
+			if err := envconfig.Process("", &cfg); err != nil {
+				t.Fatal(err)
+			}
+
+			err := cfg.Validate()
+			switch {
+			case tt.wantErr == "" && err != nil:
+				t.Errorf("err = %v, want valid", err)
+			case tt.wantErr != "" && (err == nil || !strings.HasPrefix(err.Error(), tt.wantErr)):
+				t.Errorf("err = %v, want one about %s", err, tt.wantErr)
+			}
+		})
+	}
+}
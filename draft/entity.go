@@ -0,0 +1,13 @@
+package draft
+
+import "time"
+
+// Draft is an unpublished post kept for a user while they are composing it.
+type Draft struct {
+	ID          string    `json:"id"`
+	UserSerial  string    `json:"userSerial"`
+	PostContent string    `json:"postContent"`
+	PostType    string    `json:"postType"`
+	CreatedAt   time.Time `json:"createdAt"`
+	UpdatedAt   time.Time `json:"updatedAt"`
+}
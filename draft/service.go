@@ -0,0 +1,81 @@
+package draft
+
+import (
+	"time"
+
+	"github.com/wisnuanggoro/go-getstream/uid"
+)
+
+type service struct {
+	store Store
+}
+
+type Service interface {
+	CreateDraft(userSerial, postContent, postType string) (*Draft, error)
+	GetDraftsByUserSerial(userSerial string) ([]Draft, error)
+	GetDraft(userSerial, draftID string) (*Draft, error)
+	UpdateDraft(userSerial, draftID, postContent, postType string) (*Draft, error)
+	DeleteDraft(userSerial, draftID string) error
+}
+
+func NewService(store Store) Service {
+	return &service{
+		store: store,
+	}
+}
+
+func (s *service) CreateDraft(userSerial, postContent, postType string) (*Draft, error) {
+	now := time.Now().UTC()
+	draft := Draft{
+		ID:          uid.New(),
+		UserSerial:  userSerial,
+		PostContent: postContent,
+		PostType:    postType,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if err := s.store.Save(draft); err != nil {
+		return nil, err
+	}
+
+	return &draft, nil
+}
+
+func (s *service) GetDraftsByUserSerial(userSerial string) ([]Draft, error) {
+	return s.store.ListByUserSerial(userSerial)
+}
+
+func (s *service) GetDraft(userSerial, draftID string) (*Draft, error) {
+	draft, err := s.store.Get(userSerial, draftID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &draft, nil
+}
+
+func (s *service) UpdateDraft(userSerial, draftID, postContent, postType string) (*Draft, error) {
+	draft, err := s.store.Get(userSerial, draftID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Empty values keep what the draft already has
+	if postContent != "" {
+		draft.PostContent = postContent
+	}
+	if postType != "" {
+		draft.PostType = postType
+	}
+	draft.UpdatedAt = time.Now().UTC()
+
+	if err := s.store.Save(draft); err != nil {
+		return nil, err
+	}
+
+	return &draft, nil
+}
+
+func (s *service) DeleteDraft(userSerial, draftID string) error {
+	return s.store.Delete(userSerial, draftID)
+}
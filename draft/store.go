@@ -0,0 +1,78 @@
+package draft
+
+import (
+	"errors"
+	"sort"
+	"sync"
+)
+
+var ErrNotFound = errors.New("draft not found")
+
+// Store persists drafts per user
+type Store interface {
+	Save(draft Draft) error
+	Get(userSerial, draftID string) (Draft, error)
+	ListByUserSerial(userSerial string) ([]Draft, error)
+	Delete(userSerial, draftID string) error
+}
+
+type memoryStore struct {
+	mu     sync.RWMutex
+	drafts map[string]map[string]Draft
+}
+
+// NewMemoryStore returns a Store that keeps drafts in memory only
+func NewMemoryStore() Store {
+	return &memoryStore{
+		drafts: map[string]map[string]Draft{},
+	}
+}
+
+func (m *memoryStore) Save(draft Draft) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.drafts[draft.UserSerial] == nil {
+		m.drafts[draft.UserSerial] = map[string]Draft{}
+	}
+	m.drafts[draft.UserSerial][draft.ID] = draft
+	return nil
+}
+
+func (m *memoryStore) Get(userSerial, draftID string) (Draft, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	draft, ok := m.drafts[userSerial][draftID]
+	if !ok {
+		return Draft{}, ErrNotFound
+	}
+	return draft, nil
+}
+
+func (m *memoryStore) ListByUserSerial(userSerial string) ([]Draft, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	drafts := []Draft{}
+	for _, draft := range m.drafts[userSerial] {
+		drafts = append(drafts, draft)
+	}
+
+	// Most recently edited first
+	sort.Slice(drafts, func(i, j int) bool {
+		return drafts[i].UpdatedAt.After(drafts[j].UpdatedAt)
+	})
+	return drafts, nil
+}
+
+func (m *memoryStore) Delete(userSerial, draftID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.drafts[userSerial][draftID]; !ok {
+		return ErrNotFound
+	}
+	delete(m.drafts[userSerial], draftID)
+	return nil
+}
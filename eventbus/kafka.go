@@ -0,0 +1,49 @@
+package eventbus
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+)
+
+type kafkaPublisher struct {
+	writer *kafka.Writer
+}
+
+// NewKafkaPublisher publishes every message to topic, keyed by the user
+// who made the change so their messages land on one partition in order
+func NewKafkaPublisher(brokers []string, topic string) Publisher {
+	return &kafkaPublisher{
+		writer: &kafka.Writer{
+			Addr:         kafka.TCP(brokers...),
+			Topic:        topic,
+			Balancer:     &kafka.Hash{},
+			RequiredAcks: kafka.RequireAll,
+			// Messages are published one at a time, don't wait for a batch
+			BatchTimeout: 10 * time.Millisecond,
+		},
+	}
+}
+
+func (k *kafkaPublisher) Publish(ctx context.Context, message Message) error {
+	data, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+
+	return k.writer.WriteMessages(ctx, kafka.Message{
+		Key:   []byte(message.Key),
+		Value: data,
+		Headers: []kafka.Header{
+			{Key: "id", Value: []byte(message.ID)},
+			{Key: "type", Value: []byte(message.Type)},
+			{Key: "tenant", Value: []byte(message.Tenant)},
+		},
+	})
+}
+
+func (k *kafkaPublisher) Close() error {
+	return k.writer.Close()
+}
@@ -0,0 +1,81 @@
+package eventbus
+
+import (
+	"context"
+	"sync"
+)
+
+// MemoryPublisher hands messages to handlers in the same process
+type MemoryPublisher struct {
+	mu       sync.RWMutex
+	handlers []func(ctx context.Context, message Message)
+}
+
+func NewMemoryPublisher() *MemoryPublisher {
+	return &MemoryPublisher{}
+}
+
+// Subscribe adds a handler called with every message published from now on
+func (m *MemoryPublisher) Subscribe(handler func(ctx context.Context, message Message)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.handlers = append(m.handlers, handler)
+}
+
+func (m *MemoryPublisher) Publish(ctx context.Context, message Message) error {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for _, handler := range m.handlers {
+		handler(ctx, message)
+	}
+	return nil
+}
+
+func (m *MemoryPublisher) Close() error {
+	return nil
+}
+
+// Recorder is a Publisher for tests, it keeps what was published and
+// fails while an error is set
+type Recorder struct {
+	mu       sync.Mutex
+	messages []Message
+	err      error
+}
+
+func NewRecorder() *Recorder {
+	return &Recorder{}
+}
+
+func (r *Recorder) Publish(ctx context.Context, message Message) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.err != nil {
+		return r.err
+	}
+	r.messages = append(r.messages, message)
+	return nil
+}
+
+func (r *Recorder) Close() error {
+	return nil
+}
+
+// SetError makes every publish fail with err until it's set back to nil
+func (r *Recorder) SetError(err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.err = err
+}
+
+// Messages returns the published messages in order
+func (r *Recorder) Messages() []Message {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return append([]Message(nil), r.messages...)
+}
@@ -0,0 +1,64 @@
+package eventbus
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/wisnuanggoro/go-getstream/getstream"
+)
+
+// Types of the domain events published on the bus
+const (
+	PostCreated = "PostCreated"
+	PostDeleted = "PostDeleted"
+	Followed    = "Followed"
+	Unfollowed  = "Unfollowed"
+	LikeAdded   = "LikeAdded"
+	LikeRemoved = "LikeRemoved"
+)
+
+// messageTypes maps the Service events other services care about to the
+// type of their message, follow requests stay between the two users
+var messageTypes = map[string]string{
+	getstream.EventPostCreated: PostCreated,
+	getstream.EventPostDeleted: PostDeleted,
+	getstream.EventFollowed:    Followed,
+	getstream.EventUnfollowed:  Unfollowed,
+	getstream.EventLikeAdded:   LikeAdded,
+	getstream.EventLikeRemoved: LikeRemoved,
+}
+
+// Message is a domain event as published on the bus
+type Message struct {
+	// ID is the ID of the Service event, consumers dedupe on it as a
+	// message may be published more than once
+	ID   string `json:"id"`
+	Type string `json:"type"`
+	// Tenant is the app the event happened in
+	Tenant string `json:"tenant"`
+	// Key is the user who made the change, messages of a key keep their order
+	Key        string          `json:"key"`
+	OccurredAt time.Time       `json:"occurredAt"`
+	Data       json.RawMessage `json:"data"`
+}
+
+// NewMessage returns false for events that aren't published
+func NewMessage(event getstream.Event) (Message, bool, error) {
+	messageType, ok := messageTypes[event.Type]
+	if !ok {
+		return Message{}, false, nil
+	}
+
+	data, err := json.Marshal(event)
+	if err != nil {
+		return Message{}, false, err
+	}
+
+	return Message{
+		ID:         event.ID,
+		Type:       messageType,
+		Key:        event.UserSerial,
+		OccurredAt: event.Time,
+		Data:       data,
+	}, true, nil
+}
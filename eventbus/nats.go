@@ -0,0 +1,51 @@
+package eventbus
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/nats-io/nats.go"
+)
+
+type natsPublisher struct {
+	conn          *nats.Conn
+	subjectPrefix string
+}
+
+// NewNATSPublisher publishes each message on `<subjectPrefix>.<type>`.
+// The message ID is sent as `Nats-Msg-Id` so a JetStream stream on the
+// subjects drops duplicates.
+func NewNATSPublisher(url, subjectPrefix string) (Publisher, error) {
+	conn, err := nats.Connect(url, nats.Name("go-getstream"))
+	if err != nil {
+		return nil, err
+	}
+
+	return &natsPublisher{
+		conn:          conn,
+		subjectPrefix: subjectPrefix,
+	}, nil
+}
+
+func (n *natsPublisher) Publish(ctx context.Context, message Message) error {
+	data, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+
+	msg := nats.NewMsg(subject(n.subjectPrefix, message.Type))
+	msg.Header.Set(nats.MsgIdHdr, message.ID)
+	msg.Header.Set("Tenant-ID", message.Tenant)
+	msg.Data = data
+	if err := n.conn.PublishMsg(msg); err != nil {
+		return err
+	}
+
+	// Publishing only buffers, the message is accepted once the server
+	// answered the flush
+	return n.conn.FlushWithContext(ctx)
+}
+
+func (n *natsPublisher) Close() error {
+	return n.conn.Drain()
+}
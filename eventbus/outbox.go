@@ -0,0 +1,151 @@
+package eventbus
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+)
+
+// Outbox keeps messages until the bus has accepted them. Messages are
+// added right after the change they describe, so a bus that's down only
+// delays them.
+type Outbox interface {
+	Add(message Message) error
+	// Pending returns up to limit messages in the order they were added
+	Pending(limit int) ([]Message, error)
+	Remove(ids ...string) error
+}
+
+// NewOutbox builds the outbox selected by kind, either `memory` or `file`
+func NewOutbox(kind, filePath string) (Outbox, error) {
+	switch kind {
+	case "", "memory":
+		return NewMemoryOutbox(), nil
+	case "file":
+		return NewFileOutbox(filePath)
+	default:
+		return nil, fmt.Errorf("unknown event outbox %q", kind)
+	}
+}
+
+type memoryOutbox struct {
+	mu       sync.Mutex
+	messages []Message
+}
+
+// NewMemoryOutbox returns an Outbox losing its messages on restart
+func NewMemoryOutbox() Outbox {
+	return &memoryOutbox{}
+}
+
+func (m *memoryOutbox) Add(message Message) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.messages = append(m.messages, message)
+	return nil
+}
+
+func (m *memoryOutbox) Pending(limit int) ([]Message, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return slices.Clone(m.messages[:min(limit, len(m.messages))]), nil
+}
+
+func (m *memoryOutbox) Remove(ids ...string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.messages = slices.DeleteFunc(m.messages, func(message Message) bool {
+		return slices.Contains(ids, message.ID)
+	})
+	return nil
+}
+
+// fileOutbox keeps messages in memory and writes a JSON snapshot to disk
+// after every mutation, so pending messages survive restarts
+type fileOutbox struct {
+	mu   sync.Mutex
+	path string
+	mem  *memoryOutbox
+}
+
+// NewFileOutbox returns an Outbox backed by the JSON file at path
+func NewFileOutbox(path string) (Outbox, error) {
+	o := &fileOutbox{
+		path: path,
+		mem:  &memoryOutbox{},
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return o, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(data, &o.mem.messages); err != nil {
+		return nil, err
+	}
+	return o, nil
+}
+
+func (f *fileOutbox) Add(message Message) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.mem.Add(message); err != nil {
+		return err
+	}
+	return f.flush()
+}
+
+func (f *fileOutbox) Pending(limit int) ([]Message, error) {
+	return f.mem.Pending(limit)
+}
+
+func (f *fileOutbox) Remove(ids ...string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.mem.Remove(ids...); err != nil {
+		return err
+	}
+	return f.flush()
+}
+
+// flush writes the whole outbox to a temporary file and renames it over
+// the previous snapshot so a crash never leaves a half-written file behind
+func (f *fileOutbox) flush() error {
+	f.mem.mu.Lock()
+	data, err := json.Marshal(f.mem.messages)
+	f.mem.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(f.path), filepath.Base(f.path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), f.path)
+}
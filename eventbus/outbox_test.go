@@ -0,0 +1,85 @@
+package eventbus
+
+import (
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestOutbox(t *testing.T) {
+	tests := []struct {
+		name      string
+		newOutbox func(t *testing.T) Outbox
+	}{
+		{
+			name:      "memory",
+			newOutbox: func(t *testing.T) Outbox { return NewMemoryOutbox() },
+		},
+		{
+			name: "file",
+			newOutbox: func(t *testing.T) Outbox {
+				outbox, err := NewFileOutbox(filepath.Join(t.TempDir(), "outbox.json"))
+				if err != nil {
+					t.Fatal(err)
+				}
+				return outbox
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			outbox := tt.newOutbox(t)
+			for _, id := range []string{"m1", "m2", "m3"} {
+				if err := outbox.Add(Message{ID: id}); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			batch, err := outbox.Pending(2)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := messageIDs(batch); !slices.Equal(got, []string{"m1", "m2"}) {
+				t.Fatalf("pending %v, want [m1 m2]", got)
+			}
+
+			if err := outbox.Remove("m1", "unknown"); err != nil {
+				t.Fatal(err)
+			}
+			if got := pendingIDs(t, outbox); !slices.Equal(got, []string{"m2", "m3"}) {
+				t.Errorf("pending %v after remove, want [m2 m3]", got)
+			}
+		})
+	}
+}
+
+func TestFileOutboxReload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "outbox.json")
+
+	outbox, err := NewFileOutbox(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range []string{"m1", "m2"} {
+		if err := outbox.Add(Message{ID: id, Tenant: "acme"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := outbox.Remove("m1"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Pending messages survive a restart
+	reloaded, err := NewFileOutbox(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	messages, err := reloaded.Pending(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(messages) != 1 || messages[0].ID != "m2" || messages[0].Tenant != "acme" {
+		t.Errorf("reloaded %+v, want m2 of acme", messages)
+	}
+}
@@ -0,0 +1,34 @@
+package eventbus
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// Publisher sends messages to a message bus, a nil error means the bus
+// has accepted the message
+type Publisher interface {
+	Publish(ctx context.Context, message Message) error
+	Close() error
+}
+
+// NewPublisher builds the publisher selected by backend, one of memory,
+// nats or kafka
+func NewPublisher(backend, natsURL, natsSubjectPrefix string, kafkaBrokers []string, kafkaTopic string) (Publisher, error) {
+	switch backend {
+	case "memory":
+		return NewMemoryPublisher(), nil
+	case "nats":
+		return NewNATSPublisher(natsURL, natsSubjectPrefix)
+	case "kafka":
+		return NewKafkaPublisher(kafkaBrokers, kafkaTopic), nil
+	default:
+		return nil, fmt.Errorf("unknown event bus backend %q", backend)
+	}
+}
+
+// subject returns where a message of messageType goes under prefix
+func subject(prefix, messageType string) string {
+	return strings.TrimSuffix(prefix, ".") + "." + messageType
+}
@@ -0,0 +1,135 @@
+package eventbus
+
+import (
+	"context"
+	"log/slog"
+	"math/rand"
+	"time"
+
+	"github.com/wisnuanggoro/go-getstream/getstream"
+)
+
+// RelayPolicy bounds how the outbox is drained
+type RelayPolicy struct {
+	BatchSize      int
+	PollInterval   time.Duration
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+}
+
+// Relay records Service events in the outbox and publishes them in order,
+// retrying from the first one the bus didn't accept
+type Relay struct {
+	tenantID  string
+	outbox    Outbox
+	publisher Publisher
+	policy    RelayPolicy
+	wake      chan struct{}
+}
+
+// NewRelay tags the messages of its events with tenantID
+func NewRelay(tenantID string, outbox Outbox, publisher Publisher, policy RelayPolicy) *Relay {
+	return &Relay{
+		tenantID:  tenantID,
+		outbox:    outbox,
+		publisher: publisher,
+		policy:    policy,
+		wake:      make(chan struct{}, 1),
+	}
+}
+
+// OnEvent is a getstream.EventListener
+func (r *Relay) OnEvent(ctx context.Context, event getstream.Event) {
+	message, ok, err := NewMessage(event)
+	if err == nil && ok {
+		message.Tenant = r.tenantID
+		err = r.outbox.Add(message)
+	}
+	if err != nil {
+		slog.ErrorContext(ctx, "add event to outbox", slog.String("event_id", event.ID), slog.String("type", event.Type), slog.String("error", err.Error()))
+		return
+	}
+	if !ok {
+		return
+	}
+
+	// Publishing happens on the relay goroutine, the write returns now
+	select {
+	case r.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Run publishes the outbox until ctx is cancelled, messages still pending
+// then are published by the next run
+func (r *Relay) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.policy.PollInterval)
+	defer ticker.Stop()
+
+	failures := 0
+	for {
+		published, err := r.publishPending(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+
+		// A bus that's down isn't retried sooner because of new messages
+		if err != nil {
+			failures++
+			slog.WarnContext(ctx, "publish outbox", slog.Int("failures", failures), slog.String("error", err.Error()))
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(r.backoff(failures)):
+			}
+			continue
+		}
+		failures = 0
+
+		// More may be waiting
+		if published == r.policy.BatchSize {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-r.wake:
+		case <-ticker.C:
+		}
+	}
+}
+
+// publishPending publishes a batch in order and removes the published ones
+func (r *Relay) publishPending(ctx context.Context) (int, error) {
+	messages, err := r.outbox.Pending(r.policy.BatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	published := []string{}
+	var publishErr error
+	for _, message := range messages {
+		if publishErr = r.publisher.Publish(ctx, message); publishErr != nil {
+			break
+		}
+		published = append(published, message.ID)
+	}
+
+	if len(published) > 0 {
+		if err := r.outbox.Remove(published...); err != nil {
+			return 0, err
+		}
+	}
+	return len(published), publishErr
+}
+
+// backoff returns how long to wait after the given number of failures,
+// with full jitter over an exponentially growing window
+func (r *Relay) backoff(failures int) time.Duration {
+	window := r.policy.InitialBackoff << (failures - 1)
+	if window <= 0 || window > r.policy.MaxBackoff {
+		window = r.policy.MaxBackoff
+	}
+	return time.Duration(rand.Int63n(int64(window) + 1))
+}
@@ -0,0 +1,169 @@
+package eventbus
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/wisnuanggoro/go-getstream/getstream"
+)
+
+func testRelayPolicy() RelayPolicy {
+	return RelayPolicy{
+		BatchSize:      10,
+		PollInterval:   time.Hour,
+		InitialBackoff: time.Millisecond,
+		MaxBackoff:     5 * time.Millisecond,
+	}
+}
+
+// testEvents are two published events around a follow request, which isn't
+func testEvents() []getstream.Event {
+	return []getstream.Event{
+		{ID: "e1", Type: getstream.EventPostCreated, UserSerial: "1", PostID: "a"},
+		{ID: "e2", Type: getstream.EventFollowRequested, UserSerial: "1", TargetSerial: "2"},
+		{ID: "e3", Type: getstream.EventLikeAdded, UserSerial: "2", TargetSerial: "1", PostID: "a"},
+	}
+}
+
+func messageIDs(messages []Message) []string {
+	ids := []string{}
+	for _, message := range messages {
+		ids = append(ids, message.ID)
+	}
+	return ids
+}
+
+func pendingIDs(t *testing.T, outbox Outbox) []string {
+	t.Helper()
+	messages, err := outbox.Pending(100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return messageIDs(messages)
+}
+
+func TestRelayPublishPending(t *testing.T) {
+	tests := []struct {
+		name          string
+		batchSize     int
+		publishErr    error
+		wantPublished []string
+		wantPending   []string
+	}{
+		{name: "publishes in order", batchSize: 10, wantPublished: []string{"e1", "e3"}, wantPending: []string{}},
+		{name: "failure keeps the messages", batchSize: 10, publishErr: errors.New("bus down"), wantPublished: []string{}, wantPending: []string{"e1", "e3"}},
+		{name: "one batch at a time", batchSize: 1, wantPublished: []string{"e1"}, wantPending: []string{"e3"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			outbox := NewMemoryOutbox()
+			recorder := NewRecorder()
+			recorder.SetError(tt.publishErr)
+			policy := testRelayPolicy()
+			policy.BatchSize = tt.batchSize
+			relay := NewRelay("acme", outbox, recorder, policy)
+
+			for _, event := range testEvents() {
+				relay.OnEvent(context.Background(), event)
+			}
+			published, err := relay.publishPending(context.Background())
+
+			if !errors.Is(err, tt.publishErr) {
+				t.Fatalf("err = %v, want %v", err, tt.publishErr)
+			}
+			if published != len(tt.wantPublished) {
+				t.Errorf("published = %d, want %d", published, len(tt.wantPublished))
+			}
+			if got := messageIDs(recorder.Messages()); !slices.Equal(got, tt.wantPublished) {
+				t.Errorf("published %v, want %v", got, tt.wantPublished)
+			}
+			for _, message := range recorder.Messages() {
+				if message.Tenant != "acme" {
+					t.Errorf("message %s tenant = %q, want acme", message.ID, message.Tenant)
+				}
+			}
+			if got := pendingIDs(t, outbox); !slices.Equal(got, tt.wantPending) {
+				t.Errorf("pending %v, want %v", got, tt.wantPending)
+			}
+		})
+	}
+}
+
+func TestRelayRunRetries(t *testing.T) {
+	outbox := NewMemoryOutbox()
+	recorder := NewRecorder()
+	recorder.SetError(errors.New("bus down"))
+	relay := NewRelay("acme", outbox, recorder, testRelayPolicy())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		relay.Run(ctx)
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	for _, event := range testEvents() {
+		relay.OnEvent(ctx, event)
+	}
+
+	// A few failed attempts later the messages are still waiting
+	time.Sleep(20 * time.Millisecond)
+	if got := recorder.Messages(); len(got) != 0 {
+		t.Fatalf("published %v while the bus was down", messageIDs(got))
+	}
+	if got := pendingIDs(t, outbox); !slices.Equal(got, []string{"e1", "e3"}) {
+		t.Fatalf("pending %v, want [e1 e3]", got)
+	}
+
+	// Once the bus is back a retry publishes them in order
+	recorder.SetError(nil)
+	deadline := time.Now().Add(time.Second)
+	for len(recorder.Messages()) < 2 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if got := messageIDs(recorder.Messages()); !slices.Equal(got, []string{"e1", "e3"}) {
+		t.Fatalf("published %v, want [e1 e3]", got)
+	}
+	for len(pendingIDs(t, outbox)) > 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if got := pendingIDs(t, outbox); len(got) != 0 {
+		t.Errorf("pending %v after publishing", got)
+	}
+}
+
+func TestRelayBackoff(t *testing.T) {
+	relay := NewRelay("acme", NewMemoryOutbox(), NewRecorder(), RelayPolicy{
+		InitialBackoff: 100 * time.Millisecond,
+		MaxBackoff:     time.Second,
+	})
+
+	tests := []struct {
+		name     string
+		failures int
+		max      time.Duration
+	}{
+		{name: "first failure is jittered within the initial backoff", failures: 1, max: 100 * time.Millisecond},
+		{name: "window doubles per failure", failures: 3, max: 400 * time.Millisecond},
+		{name: "window is capped", failures: 10, max: time.Second},
+		{name: "overflow is capped", failures: 100, max: time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for i := 0; i < 50; i++ {
+				if got := relay.backoff(tt.failures); got < 0 || got > tt.max {
+					t.Fatalf("backoff = %v, want within [0, %v]", got, tt.max)
+				}
+			}
+		})
+	}
+}
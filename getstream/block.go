@@ -0,0 +1,107 @@
+package getstream
+
+import (
+	"context"
+	"strings"
+)
+
+func (s *service) BlockUser(ctx context.Context, ownUserSerial, targetUserSerial string) error {
+	if ownUserSerial == targetUserSerial {
+		return ErrSameUser
+	}
+
+	err := s.store.AddBlock(ownUserSerial, targetUserSerial)
+	if err != nil {
+		return err
+	}
+
+	// Blocking drops pending follow requests between both users
+	err = s.rejectPendingFollowRequests(ownUserSerial, targetUserSerial)
+	if err != nil {
+		return err
+	}
+	err = s.rejectPendingFollowRequests(targetUserSerial, ownUserSerial)
+	if err != nil {
+		return err
+	}
+
+	// Blocking removes every follow edge between both users
+	err = s.Unfollow(ctx, ownUserSerial, targetUserSerial)
+	if err != nil {
+		return err
+	}
+
+	return s.Unfollow(ctx, targetUserSerial, ownUserSerial)
+}
+
+func (s *service) UnblockUser(ctx context.Context, ownUserSerial, targetUserSerial string) error {
+	return s.store.RemoveBlock(ownUserSerial, targetUserSerial)
+}
+
+func (s *service) GetBlockedUsersByUserSerial(ctx context.Context, userSerial string) ([]string, error) {
+	return s.store.GetBlockedUserSerials(userSerial)
+}
+
+func (s *service) MuteUser(ctx context.Context, ownUserSerial, targetUserSerial string) error {
+	if ownUserSerial == targetUserSerial {
+		return ErrSameUser
+	}
+
+	return s.store.AddMute(ownUserSerial, targetUserSerial)
+}
+
+func (s *service) UnmuteUser(ctx context.Context, ownUserSerial, targetUserSerial string) error {
+	return s.store.RemoveMute(ownUserSerial, targetUserSerial)
+}
+
+func (s *service) GetMutedUsersByUserSerial(ctx context.Context, userSerial string) ([]string, error) {
+	return s.store.GetMutedUserSerials(userSerial)
+}
+
+// checkNotBlocked returns ErrBlocked when either user has blocked the other
+func (s *service) checkNotBlocked(userSerial, otherUserSerial string) error {
+	blocked, err := s.store.IsBlocked(userSerial, otherUserSerial)
+	if err != nil {
+		return err
+	}
+	if blocked {
+		return ErrBlocked
+	}
+
+	blocked, err = s.store.IsBlocked(otherUserSerial, userSerial)
+	if err != nil {
+		return err
+	}
+	if blocked {
+		return ErrBlocked
+	}
+
+	return nil
+}
+
+// mutedActors returns the actor IDs of every user muted by userSerial
+func (s *service) mutedActors(userSerial string) (map[string]bool, error) {
+	mutedUserSerials, err := s.store.GetMutedUserSerials(userSerial)
+	if err != nil {
+		return nil, err
+	}
+
+	actors := map[string]bool{}
+	for _, mutedUserSerial := range mutedUserSerials {
+		userFlatFeed, err := s.getstreamClient.FlatFeed(s.topology.PostGroup, mutedUserSerial)
+		if err != nil {
+			return nil, err
+		}
+		actors[userFlatFeed.ID()] = true
+	}
+	return actors, nil
+}
+
+// userSerialFromActor extracts the user serial out of an actor like `user:<userSerial>`
+func userSerialFromActor(actor string) string {
+	_, userSerial, found := strings.Cut(actor, ":")
+	if !found {
+		return actor
+	}
+	return userSerial
+}
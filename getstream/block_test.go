@@ -0,0 +1,139 @@
+package getstream
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestBlockUserUnfollowsBothWays(t *testing.T) {
+	ctx := context.Background()
+	svc, fake := newFakeStreamService(t, NewMemoryStore())
+	fake.follow("timeline:1", "user:2")
+	fake.follow("timeline:2", "user:1")
+	fake.follow("timeline:1", "user:3")
+
+	if err := svc.BlockUser(ctx, "1", "2"); err != nil {
+		t.Fatal(err)
+	}
+	if edges := fake.following(); !slices.Equal(edges, []string{"timeline:1->user:3"}) {
+		t.Errorf("follows = %v, want only timeline:1->user:3", edges)
+	}
+	if err := svc.BlockUser(ctx, "1", "1"); !errors.Is(err, ErrSameUser) {
+		t.Errorf("self block: err = %v, want ErrSameUser", err)
+	}
+}
+
+func TestBlockUserRejectsFollowRequests(t *testing.T) {
+	ctx := context.Background()
+	svc, _ := newFakeStreamService(t, NewMemoryStore())
+	if err := svc.SetPrivateAccount(ctx, "1", true); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.Follow(ctx, "2", "1"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := svc.BlockUser(ctx, "1", "2"); err != nil {
+		t.Fatal(err)
+	}
+	if requests, _ := svc.GetIncomingFollowRequests(ctx, "1"); len(requests) != 0 {
+		t.Errorf("pending requests = %+v, want none after the block", requests)
+	}
+}
+
+func TestBlockedUserCantInteract(t *testing.T) {
+	ctx := context.Background()
+
+	// Blocks apply whoever made them
+	for _, blocker := range []string{"1", "2"} {
+		t.Run("blocked by "+blocker, func(t *testing.T) {
+			svc, fake := newFakeStreamService(t, NewMemoryStore())
+			postID := fake.post("user:1", "user:1")
+			blocked := map[string]string{"1": "2", "2": "1"}[blocker]
+			if err := svc.BlockUser(ctx, blocker, blocked); err != nil {
+				t.Fatal(err)
+			}
+
+			if _, err := svc.Follow(ctx, "2", "1"); !errors.Is(err, ErrBlocked) {
+				t.Errorf("follow: err = %v, want ErrBlocked", err)
+			}
+			if _, err := svc.AddLikeToPostID(ctx, "2", postID); !errors.Is(err, ErrBlocked) {
+				t.Errorf("like: err = %v, want ErrBlocked", err)
+			}
+			if edges := fake.following(); len(edges) != 0 {
+				t.Errorf("follows = %v, want none", edges)
+			}
+			if count := fake.reactionCount(); count != 0 {
+				t.Errorf("reactions = %d, want none", count)
+			}
+
+			// Unblocking allows both again
+			if err := svc.UnblockUser(ctx, blocker, blocked); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := svc.Follow(ctx, "2", "1"); err != nil {
+				t.Errorf("follow after unblock: %v", err)
+			}
+			if _, err := svc.AddLikeToPostID(ctx, "2", postID); err != nil {
+				t.Errorf("like after unblock: %v", err)
+			}
+		})
+	}
+}
+
+func TestMuteUserHidesTimelinePosts(t *testing.T) {
+	ctx := context.Background()
+	svc, fake := newFakeStreamService(t, NewMemoryStore())
+	fake.follow("timeline:1", "user:2")
+	fake.follow("timeline:1", "user:3")
+	fake.post("user:2", "user:2")
+	kept := fake.post("user:3", "user:3")
+
+	if err := svc.MuteUser(ctx, "1", "2"); err != nil {
+		t.Fatal(err)
+	}
+	resp, err := svc.GetTimelineByUserSerial(ctx, "1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Results) != 1 || resp.Results[0].ID != kept {
+		t.Errorf("timeline = %+v, want only %s", resp.Results, kept)
+	}
+
+	// Muting doesn't unfollow
+	if edges := fake.following(); len(edges) != 2 {
+		t.Errorf("follows = %v, want both kept", edges)
+	}
+}
+
+func TestBlocksSurviveRestart(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "getstream.json")
+	store, err := NewStore("file", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	svc, _ := newFakeStreamService(t, store)
+	if err := svc.BlockUser(ctx, "1", "2"); err != nil {
+		t.Fatal(err)
+	}
+	if err := svc.MuteUser(ctx, "1", "3"); err != nil {
+		t.Fatal(err)
+	}
+
+	store, err = NewStore("file", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	svc, fake := newFakeStreamService(t, store)
+	postID := fake.post("user:1", "user:1")
+	if _, err := svc.AddLikeToPostID(ctx, "2", postID); !errors.Is(err, ErrBlocked) {
+		t.Errorf("like after restart: err = %v, want ErrBlocked", err)
+	}
+	if muted, _ := svc.GetMutedUsersByUserSerial(ctx, "1"); !slices.Equal(muted, []string{"3"}) {
+		t.Errorf("muted after restart = %v, want [3]", muted)
+	}
+}
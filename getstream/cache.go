@@ -0,0 +1,336 @@
+package getstream
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	stream "gopkg.in/GetStream/stream-go2.v3"
+
+	"github.com/wisnuanggoro/go-getstream/cache"
+	"github.com/wisnuanggoro/go-getstream/uid"
+)
+
+// scopeTTL is how long a scope token outlives the entries created under it
+const scopeTTL = 24 * time.Hour
+
+// cachingService serves reads of the wrapped Service from a cache.
+//
+// Entries are keyed under a scope token, e.g. the token of `user:<serial>`
+// for the posts of that user. Writes replace the token of every scope they
+// touch, which makes all entries created under the old one unreachable
+// without having to know their keys. Posts show up in the timelines of the
+// author's followers, so writes to them replace those timeline tokens too.
+// Likes are invalidated by the CacheInvalidator, as only the events of the
+// Service tell the author of the liked post.
+type cachingService struct {
+	Service
+	cache      cache.Cache
+	defaultTTL time.Duration
+	ttls       map[string]time.Duration
+}
+
+// NewCachingService caches reads of next for defaultTTL, ttls overrides it
+// per Service method name
+func NewCachingService(next Service, c cache.Cache, defaultTTL time.Duration, ttls map[string]time.Duration) Service {
+	return &cachingService{
+		Service:    next,
+		cache:      c,
+		defaultTTL: defaultTTL,
+		ttls:       ttls,
+	}
+}
+
+// cached returns the entry of method and args under scope, calling load and
+// storing its result on a miss. Cache failures only cost a miss.
+func cached[T any](ctx context.Context, c *cachingService, method, scope string, args []string, load func() (T, error)) (T, error) {
+	ttl, ok := c.ttls[method]
+	if !ok {
+		ttl = c.defaultTTL
+	}
+	if ttl <= 0 {
+		return load()
+	}
+
+	token, err := c.scopeToken(ctx, scope)
+	if err != nil {
+		slog.WarnContext(ctx, "read cache scope", slog.String("scope", scope), slog.String("error", err.Error()))
+		return load()
+	}
+	key := "getstream:" + method + ":" + token + ":" + strings.Join(args, ",")
+
+	var resp T
+	if data, ok, err := c.cache.Get(ctx, key); err == nil && ok && json.Unmarshal(data, &resp) == nil {
+		return resp, nil
+	}
+
+	resp, err = load()
+	if err != nil {
+		return resp, err
+	}
+
+	if data, err := json.Marshal(resp); err == nil {
+		if err := c.cache.Set(ctx, key, data, ttl); err != nil {
+			slog.WarnContext(ctx, "write cache entry", slog.String("method", method), slog.String("error", err.Error()))
+		}
+	}
+	return resp, nil
+}
+
+// scopeToken returns the current token of scope, creating one when missing
+func (c *cachingService) scopeToken(ctx context.Context, scope string) (string, error) {
+	key := "getstream:scope:" + scope
+	token, ok, err := c.cache.Get(ctx, key)
+	if err != nil {
+		return "", err
+	}
+	if ok {
+		return string(token), nil
+	}
+
+	newToken := uid.New()
+	return newToken, c.cache.Set(ctx, key, []byte(newToken), scopeTTL)
+}
+
+// invalidate replaces the token of every scope once the write succeeded
+func (c *cachingService) invalidate(ctx context.Context, err error, scopes ...string) {
+	if err != nil {
+		return
+	}
+
+	if err := invalidateScopes(ctx, c.cache, scopes...); err != nil {
+		slog.WarnContext(ctx, "invalidate cache scopes", slog.Any("scopes", scopes), slog.String("error", err.Error()))
+	}
+}
+
+// invalidatePost replaces the tokens of the posts of userSerial and of the
+// timelines showing them once the write succeeded
+func (c *cachingService) invalidatePost(ctx context.Context, err error, userSerial string, scopes ...string) {
+	if err != nil {
+		return
+	}
+
+	timelineScopes, err := followerTimelineScopes(ctx, c.Service, userSerial)
+	if err != nil {
+		slog.WarnContext(ctx, "list followers to invalidate", slog.String("user_serial", userSerial), slog.String("error", err.Error()))
+	}
+	c.invalidate(ctx, nil, append(append(scopes, postsScope(userSerial)), timelineScopes...)...)
+}
+
+// followerTimelineScopes are the scopes of the timelines showing the posts
+// of userSerial
+func followerTimelineScopes(ctx context.Context, getstreamSvc Service, userSerial string) ([]string, error) {
+	followers, err := getstreamSvc.GetTimelineFollowersByUserSerial(ctx, userSerial)
+	if err != nil {
+		return nil, err
+	}
+
+	scopes := []string{}
+	for _, follower := range followers {
+		scopes = append(scopes, timelineScope(follower))
+	}
+	return scopes, nil
+}
+
+func invalidateScopes(ctx context.Context, c cache.Cache, scopes ...string) error {
+	keys := []string{}
+	for _, scope := range scopes {
+		keys = append(keys, "getstream:scope:"+scope)
+	}
+	return c.Delete(ctx, keys...)
+}
+
+// CacheInvalidator drops reads cached by the caching Service for changes it
+// can't see the scopes of: likes, told by the events of the Service, and
+// changes made outside of it, reported by Stream callbacks.
+type CacheInvalidator struct {
+	cache        cache.Cache
+	topology     Topology
+	getstreamSvc Service
+}
+
+// NewCacheInvalidator tells feeds apart by their group in topology and lists
+// the followers of authors with getstreamSvc, which mustn't be cached
+func NewCacheInvalidator(c cache.Cache, topology Topology, getstreamSvc Service) *CacheInvalidator {
+	return &CacheInvalidator{cache: c, topology: topology, getstreamSvc: getstreamSvc}
+}
+
+// OnEvent drops the reads a like changed: the likes of the post and, as
+// detail reads count reactions, the posts of its author and the timelines
+// showing them
+func (i *CacheInvalidator) OnEvent(ctx context.Context, event Event) {
+	if event.Type != EventLikeAdded && event.Type != EventLikeRemoved {
+		return
+	}
+
+	scopes := []string{likesScope(event.PostID)}
+	if event.TargetSerial != "" {
+		timelineScopes, err := followerTimelineScopes(ctx, i.getstreamSvc, event.TargetSerial)
+		if err != nil {
+			slog.WarnContext(ctx, "list followers to invalidate", slog.String("user_serial", event.TargetSerial), slog.String("error", err.Error()))
+		}
+		scopes = append(append(scopes, postsScope(event.TargetSerial)), timelineScopes...)
+	}
+
+	if err := invalidateScopes(ctx, i.cache, scopes...); err != nil {
+		slog.WarnContext(ctx, "invalidate cache scopes", slog.Any("scopes", scopes), slog.String("error", err.Error()))
+	}
+}
+
+// InvalidateFeed drops the cached reads of feedID, `<group>:<userSerial>`
+func (i *CacheInvalidator) InvalidateFeed(ctx context.Context, feedID string) error {
+	feedGroup, userSerial, _ := strings.Cut(feedID, ":")
+	switch feedGroup {
+	case i.topology.PostGroup:
+		return invalidateScopes(ctx, i.cache, postsScope(userSerial))
+	case i.topology.TimelineGroup:
+		return invalidateScopes(ctx, i.cache, timelineScope(userSerial))
+	default:
+		return nil
+	}
+}
+
+// InvalidateLikes drops the cached likes of postID. Callbacks don't tell the
+// author of the post, reaction counts of detail reads expire through their TTL.
+func (i *CacheInvalidator) InvalidateLikes(ctx context.Context, postID string) error {
+	return invalidateScopes(ctx, i.cache, likesScope(postID))
+}
+
+func postsScope(userSerial string) string    { return "posts:" + userSerial }
+func timelineScope(userSerial string) string { return "timeline:" + userSerial }
+func graphScope(userSerial string) string    { return "graph:" + userSerial }
+func likesScope(postID string) string        { return "likes:" + postID }
+
+// Cached reads
+
+func (c *cachingService) GetPostByUserSerial(ctx context.Context, viewerUserSerial, userSerial string) (*stream.FlatFeedResponse, error) {
+	return cached(ctx, c, "GetPostByUserSerial", postsScope(userSerial), []string{viewerUserSerial}, func() (*stream.FlatFeedResponse, error) {
+		return c.Service.GetPostByUserSerial(ctx, viewerUserSerial, userSerial)
+	})
+}
+
+func (c *cachingService) GetPostDetailByUserSerial(ctx context.Context, viewerUserSerial, userSerial string) (*stream.EnrichedFlatFeedResponse, error) {
+	return cached(ctx, c, "GetPostDetailByUserSerial", postsScope(userSerial), []string{viewerUserSerial}, func() (*stream.EnrichedFlatFeedResponse, error) {
+		return c.Service.GetPostDetailByUserSerial(ctx, viewerUserSerial, userSerial)
+	})
+}
+
+func (c *cachingService) GetTimelineByUserSerial(ctx context.Context, userSerial string) (*stream.FlatFeedResponse, error) {
+	return cached(ctx, c, "GetTimelineByUserSerial", timelineScope(userSerial), nil, func() (*stream.FlatFeedResponse, error) {
+		return c.Service.GetTimelineByUserSerial(ctx, userSerial)
+	})
+}
+
+func (c *cachingService) GetDetailTimelineByUserSerial(ctx context.Context, userSerial string) (*stream.EnrichedFlatFeedResponse, error) {
+	return cached(ctx, c, "GetDetailTimelineByUserSerial", timelineScope(userSerial), nil, func() (*stream.EnrichedFlatFeedResponse, error) {
+		return c.Service.GetDetailTimelineByUserSerial(ctx, userSerial)
+	})
+}
+
+func (c *cachingService) GetFeedFollowersByUserSerial(ctx context.Context, userSerial string) (*stream.FollowersResponse, error) {
+	return cached(ctx, c, "GetFeedFollowersByUserSerial", graphScope(userSerial), nil, func() (*stream.FollowersResponse, error) {
+		return c.Service.GetFeedFollowersByUserSerial(ctx, userSerial)
+	})
+}
+
+func (c *cachingService) GetFollowedFeedsByUserSerial(ctx context.Context, userSerial string) (*stream.FollowingResponse, error) {
+	return cached(ctx, c, "GetFollowedFeedsByUserSerial", graphScope(userSerial), nil, func() (*stream.FollowingResponse, error) {
+		return c.Service.GetFollowedFeedsByUserSerial(ctx, userSerial)
+	})
+}
+
+func (c *cachingService) RetrieveLikeDetailOnPostID(ctx context.Context, postID string, limit int) (*stream.FilterReactionResponse, error) {
+	return cached(ctx, c, "RetrieveLikeDetailOnPostID", likesScope(postID), []string{postID, strconv.Itoa(limit)}, func() (*stream.FilterReactionResponse, error) {
+		return c.Service.RetrieveLikeDetailOnPostID(ctx, postID, limit)
+	})
+}
+
+func (c *cachingService) RetrieveLikeDetailOnPostIDWithPagination(ctx context.Context, postID, nextLikeID string, limit int) (*stream.FilterReactionResponse, error) {
+	return cached(ctx, c, "RetrieveLikeDetailOnPostIDWithPagination", likesScope(postID), []string{postID, nextLikeID, strconv.Itoa(limit)}, func() (*stream.FilterReactionResponse, error) {
+		return c.Service.RetrieveLikeDetailOnPostIDWithPagination(ctx, postID, nextLikeID, limit)
+	})
+}
+
+// Invalidating writes
+
+func (c *cachingService) AddPostByUserSerial(ctx context.Context, userSerial, postContent, postType string) (*stream.AddActivityResponse, error) {
+	resp, err := c.Service.AddPostByUserSerial(ctx, userSerial, postContent, postType)
+	c.invalidatePost(ctx, err, userSerial)
+	return resp, err
+}
+
+func (c *cachingService) AddScheduledPostByUserSerial(ctx context.Context, userSerial, scheduleID, postContent, postType string, publishAt time.Time) (*stream.AddActivityResponse, error) {
+	resp, err := c.Service.AddScheduledPostByUserSerial(ctx, userSerial, scheduleID, postContent, postType, publishAt)
+	c.invalidatePost(ctx, err, userSerial)
+	return resp, err
+}
+
+func (c *cachingService) EditPostByPostID(ctx context.Context, userSerial, postID, postContent, postType string) (*stream.UpdateActivityResponse, error) {
+	resp, err := c.Service.EditPostByPostID(ctx, userSerial, postID, postContent, postType)
+	c.invalidatePost(ctx, err, userSerial)
+	return resp, err
+}
+
+func (c *cachingService) DeletePostByPostID(ctx context.Context, userSerial, postID string) error {
+	err := c.Service.DeletePostByPostID(ctx, userSerial, postID)
+	c.invalidatePost(ctx, err, userSerial, likesScope(postID))
+	return err
+}
+
+func (c *cachingService) Follow(ctx context.Context, ownUserSerial, targetUserSerial string) (*FollowRequest, error) {
+	resp, err := c.Service.Follow(ctx, ownUserSerial, targetUserSerial)
+	c.invalidate(ctx, err, followScopes(ownUserSerial, targetUserSerial)...)
+	return resp, err
+}
+
+func (c *cachingService) Unfollow(ctx context.Context, ownUserSerial, targetUserSerial string) error {
+	err := c.Service.Unfollow(ctx, ownUserSerial, targetUserSerial)
+	c.invalidate(ctx, err, followScopes(ownUserSerial, targetUserSerial)...)
+	return err
+}
+
+func (c *cachingService) ApproveFollowRequest(ctx context.Context, userSerial, requestID string) (*FollowRequest, error) {
+	resp, err := c.Service.ApproveFollowRequest(ctx, userSerial, requestID)
+	if err == nil {
+		c.invalidate(ctx, err, followScopes(resp.OwnUserSerial, resp.TargetUserSerial)...)
+	}
+	return resp, err
+}
+
+func (c *cachingService) BlockUser(ctx context.Context, ownUserSerial, targetUserSerial string) error {
+	err := c.Service.BlockUser(ctx, ownUserSerial, targetUserSerial)
+	c.invalidate(ctx, err, append(followScopes(ownUserSerial, targetUserSerial), followScopes(targetUserSerial, ownUserSerial)...)...)
+	return err
+}
+
+func (c *cachingService) MuteUser(ctx context.Context, ownUserSerial, targetUserSerial string) error {
+	err := c.Service.MuteUser(ctx, ownUserSerial, targetUserSerial)
+	c.invalidate(ctx, err, timelineScope(ownUserSerial))
+	return err
+}
+
+func (c *cachingService) UnmuteUser(ctx context.Context, ownUserSerial, targetUserSerial string) error {
+	err := c.Service.UnmuteUser(ctx, ownUserSerial, targetUserSerial)
+	c.invalidate(ctx, err, timelineScope(ownUserSerial))
+	return err
+}
+
+func (c *cachingService) SetPrivateAccount(ctx context.Context, userSerial string, private bool) error {
+	err := c.Service.SetPrivateAccount(ctx, userSerial, private)
+	c.invalidate(ctx, err, postsScope(userSerial))
+	return err
+}
+
+// followScopes are the scopes a follow edge from ownUserSerial to targetUserSerial touches
+func followScopes(ownUserSerial, targetUserSerial string) []string {
+	return []string{
+		timelineScope(ownUserSerial),
+		graphScope(ownUserSerial),
+		graphScope(targetUserSerial),
+		// Posts of a private target become visible to the new follower
+		postsScope(targetUserSerial),
+	}
+}
@@ -0,0 +1,171 @@
+package getstream
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	stream "gopkg.in/GetStream/stream-go2.v3"
+
+	"github.com/wisnuanggoro/go-getstream/cache"
+)
+
+// countingService counts the reads reaching it and lists followers from a map
+type countingService struct {
+	Service
+
+	mu        sync.Mutex
+	loads     map[string]int
+	followers map[string][]string
+}
+
+func newCountingService(followers map[string][]string) *countingService {
+	return &countingService{loads: map[string]int{}, followers: followers}
+}
+
+func (s *countingService) load(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.loads[key]++
+}
+
+func (s *countingService) loadCount(key string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.loads[key]
+}
+
+func (s *countingService) GetPostDetailByUserSerial(ctx context.Context, viewerUserSerial, userSerial string) (*stream.EnrichedFlatFeedResponse, error) {
+	s.load("posts:" + userSerial)
+	return &stream.EnrichedFlatFeedResponse{}, nil
+}
+
+func (s *countingService) GetDetailTimelineByUserSerial(ctx context.Context, userSerial string) (*stream.EnrichedFlatFeedResponse, error) {
+	s.load("timeline:" + userSerial)
+	return &stream.EnrichedFlatFeedResponse{}, nil
+}
+
+func (s *countingService) RetrieveLikeDetailOnPostID(ctx context.Context, postID string, limit int) (*stream.FilterReactionResponse, error) {
+	s.load("likes:" + postID)
+	return &stream.FilterReactionResponse{}, nil
+}
+
+func (s *countingService) GetTimelineFollowersByUserSerial(ctx context.Context, userSerial string) ([]string, error) {
+	return s.followers[userSerial], nil
+}
+
+func (s *countingService) AddPostByUserSerial(ctx context.Context, userSerial, postContent, postType string) (*stream.AddActivityResponse, error) {
+	return &stream.AddActivityResponse{}, nil
+}
+
+func (s *countingService) DeletePostByPostID(ctx context.Context, userSerial, postID string) error {
+	return nil
+}
+
+// readAll reads every scope the tests look at through svc
+func readAll(t *testing.T, svc Service) {
+	t.Helper()
+	ctx := context.Background()
+
+	for _, userSerial := range []string{"1", "2", "3"} {
+		if _, err := svc.GetPostDetailByUserSerial(ctx, "0", userSerial); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := svc.GetDetailTimelineByUserSerial(ctx, userSerial); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, postID := range []string{"a", "b"} {
+		if _, err := svc.RetrieveLikeDetailOnPostID(ctx, postID, 10); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestCachingServiceInvalidation(t *testing.T) {
+	// 2 follows 1, 3 follows nobody
+	followers := map[string][]string{"1": {"2"}}
+
+	tests := []struct {
+		name string
+		// change is made through the caching Service or its invalidator
+		change     func(svc Service, invalidator *CacheInvalidator) error
+		wantLoaded []string
+	}{
+		{
+			name: "post reloads the author's posts and followers' timelines",
+			change: func(svc Service, invalidator *CacheInvalidator) error {
+				_, err := svc.AddPostByUserSerial(context.Background(), "1", "hello", "text")
+				return err
+			},
+			wantLoaded: []string{"posts:1", "timeline:2"},
+		},
+		{
+			name: "delete also reloads the likes of the post",
+			change: func(svc Service, invalidator *CacheInvalidator) error {
+				return svc.DeletePostByPostID(context.Background(), "1", "a")
+			},
+			wantLoaded: []string{"posts:1", "timeline:2", "likes:a"},
+		},
+		{
+			name: "like reloads the post's likes, its author's posts and followers' timelines",
+			change: func(svc Service, invalidator *CacheInvalidator) error {
+				invalidator.OnEvent(context.Background(), Event{Type: EventLikeAdded, UserSerial: "3", TargetSerial: "1", PostID: "a"})
+				return nil
+			},
+			wantLoaded: []string{"likes:a", "posts:1", "timeline:2"},
+		},
+		{
+			name: "unlike is scoped like a like",
+			change: func(svc Service, invalidator *CacheInvalidator) error {
+				invalidator.OnEvent(context.Background(), Event{Type: EventLikeRemoved, UserSerial: "3", TargetSerial: "1", PostID: "b"})
+				return nil
+			},
+			wantLoaded: []string{"likes:b", "posts:1", "timeline:2"},
+		},
+		{
+			name: "callback reloads the likes of its post only",
+			change: func(svc Service, invalidator *CacheInvalidator) error {
+				return invalidator.InvalidateLikes(context.Background(), "a")
+			},
+			wantLoaded: []string{"likes:a"},
+		},
+		{
+			name: "other events are ignored",
+			change: func(svc Service, invalidator *CacheInvalidator) error {
+				invalidator.OnEvent(context.Background(), Event{Type: EventFollowed, UserSerial: "3", TargetSerial: "1"})
+				return nil
+			},
+		},
+	}
+
+	keys := []string{"posts:1", "posts:2", "posts:3", "timeline:1", "timeline:2", "timeline:3", "likes:a", "likes:b"}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			next := newCountingService(followers)
+			c := cache.NewMemoryCache(100)
+			svc := NewCachingService(next, c, time.Minute, nil)
+			invalidator := NewCacheInvalidator(c, Topology{PostGroup: "user", TimelineGroup: "timeline"}, next)
+
+			// Warm up, then read again after the change
+			readAll(t, svc)
+			if err := tt.change(svc, invalidator); err != nil {
+				t.Fatal(err)
+			}
+			readAll(t, svc)
+
+			for _, key := range keys {
+				want := 1
+				for _, loaded := range tt.wantLoaded {
+					if loaded == key {
+						want = 2
+					}
+				}
+				if got := next.loadCount(key); got != want {
+					t.Errorf("%s loaded %d times, want %d", key, got, want)
+				}
+			}
+		})
+	}
+}
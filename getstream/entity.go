@@ -16,3 +16,48 @@ type Activity struct {
 	Score     float64                `json:"score,omitempty"`
 	Extra     map[string]interface{} `json:"-"`
 }
+
+// PostRevision is the content a post had before it was edited.
+type PostRevision struct {
+	PostID   string    `json:"postID"`
+	Post     string    `json:"post"`
+	PostType string    `json:"postType"`
+	Editor   string    `json:"editor"`
+	EditedAt time.Time `json:"editedAt"`
+}
+
+// FollowRequestStatus is the state of a request to follow a private user.
+type FollowRequestStatus string
+
+const (
+	FollowRequestPending  FollowRequestStatus = "pending"
+	FollowRequestApproved FollowRequestStatus = "approved"
+	FollowRequestRejected FollowRequestStatus = "rejected"
+)
+
+// FollowRequest is a pending follow waiting for the private target to approve it.
+type FollowRequest struct {
+	ID               string              `json:"id"`
+	OwnUserSerial    string              `json:"ownUserSerial"`
+	TargetUserSerial string              `json:"targetUserSerial"`
+	Status           FollowRequestStatus `json:"status"`
+	CreatedAt        time.Time           `json:"createdAt"`
+	UpdatedAt        time.Time           `json:"updatedAt"`
+}
+
+// FollowSuggestion is a user worth following, found through the social graph.
+type FollowSuggestion struct {
+	UserSerial     string     `json:"userSerial"`
+	MutualCount    int        `json:"mutualCount"`
+	FollowsYou     bool       `json:"followsYou"`
+	LastActivityAt *time.Time `json:"lastActivityAt,omitempty"`
+}
+
+// Relationship describes the follow edges between a user and another user.
+type Relationship struct {
+	UserSerial  string `json:"userSerial"`
+	OtherSerial string `json:"otherSerial"`
+	Following   bool   `json:"following"`
+	FollowedBy  bool   `json:"followedBy"`
+	Mutual      bool   `json:"mutual"`
+}
@@ -0,0 +1,77 @@
+package getstream
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/url"
+
+	stream "gopkg.in/GetStream/stream-go2.v3"
+)
+
+// Error classes returned by ErrorClass
+const (
+	ErrorClassDomain      = "domain"
+	ErrorClassClient      = "client_error"
+	ErrorClassRateLimited = "rate_limited"
+	ErrorClassServer      = "server_error"
+	ErrorClassTimeout     = "timeout"
+	ErrorClassNetwork     = "network"
+	ErrorClassCircuitOpen = "circuit_open"
+	ErrorClassUnknown     = "unknown"
+)
+
+var domainErrors = []error{
+	ErrPostNotFound,
+	ErrNotPostOwner,
+	ErrBlocked,
+	ErrSameUser,
+	ErrPrivateAccount,
+	ErrFollowRequestNotFound,
+	ErrFollowRequestNotPending,
+}
+
+// ErrorClass groups an error returned by the Service into a small set of
+// classes, telling apart our own rule violations, Stream API responses and
+// transport failures
+func ErrorClass(err error) string {
+	for _, domainErr := range domainErrors {
+		if errors.Is(err, domainErr) {
+			return ErrorClassDomain
+		}
+	}
+
+	if errors.Is(err, ErrCircuitOpen) {
+		return ErrorClassCircuitOpen
+	}
+
+	var apiErr stream.APIError
+	if errors.As(err, &apiErr) {
+		switch {
+		case apiErr.StatusCode == http.StatusTooManyRequests:
+			return ErrorClassRateLimited
+		case apiErr.StatusCode >= http.StatusInternalServerError:
+			return ErrorClassServer
+		case apiErr.StatusCode >= http.StatusBadRequest:
+			return ErrorClassClient
+		}
+	}
+
+	if errors.Is(err, context.DeadlineExceeded) {
+		return ErrorClassTimeout
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		if netErr.Timeout() {
+			return ErrorClassTimeout
+		}
+		return ErrorClassNetwork
+	}
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		return ErrorClassNetwork
+	}
+
+	return ErrorClassUnknown
+}
@@ -0,0 +1,69 @@
+package getstream
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	stream "gopkg.in/GetStream/stream-go2.v3"
+
+	"github.com/wisnuanggoro/go-getstream/uid"
+)
+
+// Types of the events emitted by the Service
+const (
+	EventPostCreated     = "post.created"
+	EventPostDeleted     = "post.deleted"
+	EventFollowed        = "follow.created"
+	EventFollowRequested = "follow.requested"
+	EventUnfollowed      = "follow.deleted"
+	EventLikeAdded       = "like.created"
+	EventLikeRemoved     = "like.deleted"
+)
+
+// Event is a change the Service made, emitted once Stream accepted it
+type Event struct {
+	ID   string `json:"id"`
+	Type string `json:"type"`
+	// UserSerial made the change
+	UserSerial string `json:"userSerial"`
+	// TargetSerial is the followed user or the author of the liked post
+	TargetSerial string           `json:"targetSerial,omitempty"`
+	PostID       string           `json:"postID,omitempty"`
+	ReactionID   string           `json:"reactionID,omitempty"`
+	Activity     *stream.Activity `json:"activity,omitempty"`
+	Time         time.Time        `json:"time"`
+}
+
+// EventListener is called with each Event on the goroutine of the change,
+// so it must hand slow work off instead of doing it inline
+type EventListener func(ctx context.Context, event Event)
+
+// Events dispatches the Events of a Service to the listeners added to it
+type Events struct {
+	mu        sync.RWMutex
+	listeners []EventListener
+}
+
+func NewEvents() *Events {
+	return &Events{}
+}
+
+func (e *Events) Listen(listener EventListener) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.listeners = append(e.listeners, listener)
+}
+
+func (e *Events) emit(ctx context.Context, event Event) {
+	event.ID = uid.New()
+	event.Time = time.Now().UTC()
+
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	for _, listener := range e.listeners {
+		listener(ctx, event)
+	}
+}
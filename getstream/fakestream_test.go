@@ -0,0 +1,356 @@
+package getstream
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	stream "gopkg.in/GetStream/stream-go2.v3"
+)
+
+// fakeStream is a local Stream API keeping feeds, follows and reactions in
+// memory. Faults make its next responses fail or stall.
+type fakeStream struct {
+	server *httptest.Server
+
+	mu         sync.Mutex
+	activities []map[string]interface{}
+	follows    []stream.Follower
+	reactions  []stream.Reaction
+	nextID     int
+	faults     []fault
+	calls      int
+}
+
+// fault fails a response with status, after waiting for delay
+type fault struct {
+	status int
+	delay  time.Duration
+}
+
+func newFakeStream(t *testing.T) *fakeStream {
+	f := &fakeStream{}
+	f.server = httptest.NewServer(http.HandlerFunc(f.serve))
+	t.Cleanup(f.server.Close)
+	return f
+}
+
+// client returns a Stream client sending its requests to f
+func (f *fakeStream) client(t *testing.T, timeout time.Duration) *stream.Client {
+	target, err := url.Parse(f.server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client, err := stream.NewClient("key", "secret", stream.WithHTTPRequester(&http.Client{
+		Timeout:   timeout,
+		Transport: redirectTransport{target: target},
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return client
+}
+
+// redirectTransport sends requests for the Stream API to target
+type redirectTransport struct {
+	target *url.URL
+}
+
+func (r redirectTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.URL.Scheme = r.target.Scheme
+	req.URL.Host = r.target.Host
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func (f *fakeStream) fail(faults ...fault) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.faults = append(f.faults, faults...)
+}
+
+func (f *fakeStream) callCount() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.calls
+}
+
+// follow adds a follow edge as if it was made before the test
+func (f *fakeStream) follow(feedID, targetID string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.follows = append(f.follows, stream.Follower{FeedID: feedID, TargetID: targetID})
+}
+
+// following returns the follow edges as `<feed>-><target>`
+func (f *fakeStream) following() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	edges := []string{}
+	for _, follow := range f.follows {
+		edges = append(edges, follow.FeedID+"->"+follow.TargetID)
+	}
+	slices.Sort(edges)
+	return edges
+}
+
+// post adds an activity of actor to the feed as if it was made before the test
+func (f *fakeStream) post(feedID, actor string) string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.addActivity(feedID, map[string]interface{}{"actor": actor, "verb": "post", "object": "1"})
+}
+
+func (f *fakeStream) activityIDs() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	ids := []string{}
+	for _, activity := range f.activities {
+		ids = append(ids, activity["id"].(string))
+	}
+	return ids
+}
+
+func (f *fakeStream) reactionCount() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.reactions)
+}
+
+// addActivity stores activity in feedID, replacing the one with the same
+// foreign ID and time like Stream does
+func (f *fakeStream) addActivity(feedID string, activity map[string]interface{}) string {
+	f.nextID++
+	activity["id"] = fmt.Sprintf("activity-%d", f.nextID)
+	activity["origin"] = feedID
+	if at, _ := activity["time"].(string); at == "" {
+		activity["time"] = time.Now().UTC().Add(time.Duration(f.nextID) * time.Millisecond).Format("2006-01-02T15:04:05.999999")
+	}
+
+	if foreignID, _ := activity["foreign_id"].(string); foreignID != "" {
+		for i, existing := range f.activities {
+			if existing["origin"] == feedID && existing["foreign_id"] == foreignID && existing["time"] == activity["time"] {
+				activity["id"] = existing["id"]
+				f.activities[i] = activity
+				return activity["id"].(string)
+			}
+		}
+	}
+	f.activities = append(f.activities, activity)
+	return activity["id"].(string)
+}
+
+func (f *fakeStream) serve(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	f.calls++
+	var next *fault
+	if len(f.faults) > 0 {
+		next = &f.faults[0]
+		f.faults = f.faults[1:]
+	}
+	f.mu.Unlock()
+
+	if next != nil {
+		select {
+		case <-time.After(next.delay):
+		case <-r.Context().Done():
+			return
+		}
+		if next.status != 0 {
+			respond(w, next.status, map[string]interface{}{
+				"code":        next.status,
+				"detail":      "injected fault",
+				"exception":   "InjectedFault",
+				"status_code": next.status,
+			})
+			return
+		}
+	}
+
+	// Paths look like /api/v1.0/feed/user/1/follows/
+	_, path, _ := strings.Cut(r.URL.Path, "/api/")
+	segments := strings.Split(strings.Trim(path, "/"), "/")[1:]
+	query := r.URL.Query()
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	switch {
+	case len(segments) >= 3 && segments[0] == "enrich" && segments[1] == "feed" && r.Method == http.MethodGet:
+		respond(w, http.StatusOK, map[string]interface{}{"results": f.feedActivities(segments[2]+":"+segments[3], query)})
+
+	case len(segments) == 3 && segments[0] == "feed":
+		feedID := segments[1] + ":" + segments[2]
+		switch r.Method {
+		case http.MethodGet:
+			respond(w, http.StatusOK, map[string]interface{}{"results": f.feedActivities(feedID, query)})
+		case http.MethodPost:
+			activity := map[string]interface{}{}
+			if err := json.NewDecoder(r.Body).Decode(&activity); err != nil {
+				respond(w, http.StatusBadRequest, map[string]interface{}{"status_code": http.StatusBadRequest})
+				return
+			}
+			f.addActivity(feedID, activity)
+			respond(w, http.StatusCreated, activity)
+		}
+
+	case len(segments) == 4 && segments[0] == "feed" && segments[3] == "follows" && r.Method == http.MethodPost:
+		var body struct {
+			Target string `json:"target"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			respond(w, http.StatusBadRequest, map[string]interface{}{"status_code": http.StatusBadRequest})
+			return
+		}
+		follow := stream.Follower{FeedID: segments[1] + ":" + segments[2], TargetID: body.Target}
+		if !slices.Contains(f.follows, follow) {
+			f.follows = append(f.follows, follow)
+		}
+		respond(w, http.StatusCreated, map[string]interface{}{})
+
+	case len(segments) == 4 && segments[0] == "feed" && segments[3] == "follows":
+		feedID := segments[1] + ":" + segments[2]
+		filter := []string{}
+		if query.Get("filter") != "" {
+			filter = strings.Split(query.Get("filter"), ",")
+		}
+		results := []stream.Follower{}
+		for _, follow := range f.follows {
+			if follow.FeedID == feedID && (len(filter) == 0 || slices.Contains(filter, follow.TargetID)) {
+				results = append(results, follow)
+			}
+		}
+		respond(w, http.StatusOK, map[string]interface{}{"results": page(results, query)})
+
+	case len(segments) == 5 && segments[0] == "feed" && segments[3] == "follows" && r.Method == http.MethodDelete:
+		feedID := segments[1] + ":" + segments[2]
+		f.follows = slices.DeleteFunc(f.follows, func(follow stream.Follower) bool {
+			return follow.FeedID == feedID && follow.TargetID == segments[4]
+		})
+		respond(w, http.StatusOK, map[string]interface{}{})
+
+	case len(segments) == 4 && segments[0] == "feed" && segments[3] == "followers":
+		feedID := segments[1] + ":" + segments[2]
+		results := []stream.Follower{}
+		for _, follow := range f.follows {
+			if follow.TargetID == feedID {
+				results = append(results, follow)
+			}
+		}
+		respond(w, http.StatusOK, map[string]interface{}{"results": page(results, query)})
+
+	case len(segments) == 4 && segments[0] == "feed" && r.Method == http.MethodDelete:
+		f.activities = slices.DeleteFunc(f.activities, func(activity map[string]interface{}) bool {
+			return activity["id"] == segments[3]
+		})
+		respond(w, http.StatusOK, map[string]interface{}{})
+
+	case len(segments) == 1 && segments[0] == "activities":
+		ids := strings.Split(query.Get("ids"), ",")
+		results := []map[string]interface{}{}
+		for _, activity := range f.activities {
+			if slices.Contains(ids, activity["id"].(string)) {
+				results = append(results, activity)
+			}
+		}
+		respond(w, http.StatusOK, map[string]interface{}{"results": results})
+
+	case len(segments) == 1 && segments[0] == "reaction" && r.Method == http.MethodPost:
+		var reaction stream.Reaction
+		if err := json.NewDecoder(r.Body).Decode(&reaction); err != nil {
+			respond(w, http.StatusBadRequest, map[string]interface{}{"status_code": http.StatusBadRequest})
+			return
+		}
+		f.nextID++
+		reaction.ID = fmt.Sprintf("reaction-%d", f.nextID)
+		f.reactions = append(f.reactions, reaction)
+		respond(w, http.StatusCreated, reaction)
+
+	case len(segments) == 2 && segments[0] == "reaction":
+		i := slices.IndexFunc(f.reactions, func(reaction stream.Reaction) bool { return reaction.ID == segments[1] })
+		if i < 0 {
+			respond(w, http.StatusNotFound, map[string]interface{}{"status_code": http.StatusNotFound, "exception": "DoesNotExistException"})
+			return
+		}
+		if r.Method == http.MethodDelete {
+			f.reactions = slices.Delete(f.reactions, i, i+1)
+			respond(w, http.StatusOK, map[string]interface{}{})
+			return
+		}
+		respond(w, http.StatusOK, f.reactions[i])
+
+	case len(segments) >= 3 && segments[0] == "reaction" && segments[1] == "activity_id":
+		results := []stream.Reaction{}
+		for _, reaction := range f.reactions {
+			if reaction.ActivityID == segments[2] && (len(segments) < 4 || reaction.Kind == segments[3]) {
+				results = append(results, reaction)
+			}
+		}
+		respond(w, http.StatusOK, map[string]interface{}{"results": page(results, query)})
+
+	default:
+		respond(w, http.StatusNotFound, map[string]interface{}{"status_code": http.StatusNotFound, "detail": r.Method + " " + r.URL.Path})
+	}
+}
+
+// feedActivities returns the activities posted to feedID or to the feeds it
+// follows, newest first
+func (f *fakeStream) feedActivities(feedID string, query url.Values) []map[string]interface{} {
+	feeds := []string{feedID}
+	for _, follow := range f.follows {
+		if follow.FeedID == feedID {
+			feeds = append(feeds, follow.TargetID)
+		}
+	}
+
+	results := []map[string]interface{}{}
+	for i := len(f.activities) - 1; i >= 0; i-- {
+		if slices.Contains(feeds, f.activities[i]["origin"].(string)) {
+			results = append(results, f.activities[i])
+		}
+	}
+	return page(results, query)
+}
+
+// page applies the limit and offset parameters of a list request
+func page[T any](results []T, query url.Values) []T {
+	offset, _ := strconv.Atoi(query.Get("offset"))
+	results = results[min(offset, len(results)):]
+	if limit, err := strconv.Atoi(query.Get("limit")); err == nil && limit < len(results) {
+		results = results[:limit]
+	}
+	return results
+}
+
+func respond(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
+
+// testTopology is a new Stream app with timelines following user feeds
+func testTopology() Topology {
+	return Topology{
+		PostGroup:     "user",
+		TimelineGroup: "timeline",
+		PostVerb:      "post",
+		FollowRules:   []FollowRule{{Follower: "timeline", Followed: "user"}},
+	}
+}
+
+// newFakeStreamService returns the core Service over a fake Stream
+func newFakeStreamService(t *testing.T, store Store) (*service, *fakeStream) {
+	fake := newFakeStream(t)
+	svc := NewService(fake.client(t, time.Second), store, testTopology(), time.Minute, NewEvents())
+	return svc.(*service), fake
+}
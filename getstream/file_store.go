@@ -0,0 +1,211 @@
+package getstream
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+)
+
+// fileStore keeps the state in memory and writes a JSON snapshot to disk
+// after every mutation, so private accounts, follow requests, blocks and
+// mutes survive restarts
+type fileStore struct {
+	mu   sync.RWMutex
+	path string
+	mem  *memoryStore
+}
+
+// storeSnapshot is the state of a fileStore as written to disk
+type storeSnapshot struct {
+	Revisions      map[string][]PostRevision `json:"revisions"`
+	Blocks         map[string][]string       `json:"blocks"`
+	Mutes          map[string][]string       `json:"mutes"`
+	Private        []string                  `json:"private"`
+	FollowRequests []FollowRequest           `json:"followRequests"`
+}
+
+// NewFileStore returns a Store backed by the JSON file at path
+func NewFileStore(path string) (Store, error) {
+	f := &fileStore{
+		path: path,
+		mem:  newMemoryStore(),
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return f, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var snapshot storeSnapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return nil, err
+	}
+	f.mem = snapshot.restore()
+	return f, nil
+}
+
+// current returns the stored state, it's replaced rather than changed
+func (f *fileStore) current() *memoryStore {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return f.mem
+}
+
+// update changes a copy of the state, memory only takes it once it's on disk
+func (f *fileStore) update(fn func(next *memoryStore) error) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	next := f.mem.snapshot().restore()
+	if err := fn(next); err != nil {
+		return err
+	}
+	if err := f.flush(next.snapshot()); err != nil {
+		return err
+	}
+	f.mem = next
+	return nil
+}
+
+func (f *fileStore) AddPostRevision(revision PostRevision) error {
+	return f.update(func(next *memoryStore) error { return next.AddPostRevision(revision) })
+}
+
+func (f *fileStore) GetPostRevisions(postID string) ([]PostRevision, error) {
+	return f.current().GetPostRevisions(postID)
+}
+
+func (f *fileStore) AddBlock(userSerial, targetUserSerial string) error {
+	return f.update(func(next *memoryStore) error { return next.AddBlock(userSerial, targetUserSerial) })
+}
+
+func (f *fileStore) RemoveBlock(userSerial, targetUserSerial string) error {
+	return f.update(func(next *memoryStore) error { return next.RemoveBlock(userSerial, targetUserSerial) })
+}
+
+func (f *fileStore) IsBlocked(userSerial, targetUserSerial string) (bool, error) {
+	return f.current().IsBlocked(userSerial, targetUserSerial)
+}
+
+func (f *fileStore) GetBlockedUserSerials(userSerial string) ([]string, error) {
+	return f.current().GetBlockedUserSerials(userSerial)
+}
+
+func (f *fileStore) AddMute(userSerial, targetUserSerial string) error {
+	return f.update(func(next *memoryStore) error { return next.AddMute(userSerial, targetUserSerial) })
+}
+
+func (f *fileStore) RemoveMute(userSerial, targetUserSerial string) error {
+	return f.update(func(next *memoryStore) error { return next.RemoveMute(userSerial, targetUserSerial) })
+}
+
+func (f *fileStore) GetMutedUserSerials(userSerial string) ([]string, error) {
+	return f.current().GetMutedUserSerials(userSerial)
+}
+
+func (f *fileStore) SetPrivate(userSerial string, private bool) error {
+	return f.update(func(next *memoryStore) error { return next.SetPrivate(userSerial, private) })
+}
+
+func (f *fileStore) IsPrivate(userSerial string) (bool, error) {
+	return f.current().IsPrivate(userSerial)
+}
+
+func (f *fileStore) SaveFollowRequest(request FollowRequest) error {
+	return f.update(func(next *memoryStore) error { return next.SaveFollowRequest(request) })
+}
+
+func (f *fileStore) GetFollowRequest(requestID string) (FollowRequest, error) {
+	return f.current().GetFollowRequest(requestID)
+}
+
+func (f *fileStore) GetFollowRequests(ownUserSerial, targetUserSerial string, status FollowRequestStatus) ([]FollowRequest, error) {
+	return f.current().GetFollowRequests(ownUserSerial, targetUserSerial, status)
+}
+
+// flush writes the snapshot to a temporary file and renames it over the
+// previous one so a crash never leaves a half-written file behind
+func (f *fileStore) flush(snapshot storeSnapshot) error {
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(f.path), filepath.Base(f.path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), f.path)
+}
+
+// snapshot copies the state of m
+func (m *memoryStore) snapshot() storeSnapshot {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	snapshot := storeSnapshot{
+		Revisions:      map[string][]PostRevision{},
+		Blocks:         m.blocks.lists(),
+		Mutes:          m.mutes.lists(),
+		Private:        []string{},
+		FollowRequests: []FollowRequest{},
+	}
+	for postID, revisions := range m.revisions {
+		snapshot.Revisions[postID] = append([]PostRevision(nil), revisions...)
+	}
+	for userSerial := range m.private {
+		snapshot.Private = append(snapshot.Private, userSerial)
+	}
+	sort.Strings(snapshot.Private)
+	for _, request := range m.requests {
+		snapshot.FollowRequests = append(snapshot.FollowRequests, request)
+	}
+	sort.Slice(snapshot.FollowRequests, func(i, j int) bool {
+		return snapshot.FollowRequests[i].ID < snapshot.FollowRequests[j].ID
+	})
+	return snapshot
+}
+
+// restore returns a memoryStore holding the snapshot
+func (s storeSnapshot) restore() *memoryStore {
+	m := newMemoryStore()
+	for postID, revisions := range s.Revisions {
+		m.revisions[postID] = append([]PostRevision(nil), revisions...)
+	}
+	for userSerial, targetUserSerials := range s.Blocks {
+		for _, targetUserSerial := range targetUserSerials {
+			m.blocks.add(userSerial, targetUserSerial)
+		}
+	}
+	for userSerial, targetUserSerials := range s.Mutes {
+		for _, targetUserSerial := range targetUserSerials {
+			m.mutes.add(userSerial, targetUserSerial)
+		}
+	}
+	for _, userSerial := range s.Private {
+		m.private[userSerial] = true
+	}
+	for _, request := range s.FollowRequests {
+		m.requests[request.ID] = request
+	}
+	return m
+}
@@ -0,0 +1,97 @@
+package getstream
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestFileStoreReload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "getstream.json")
+	store, err := NewFileStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	createdAt := time.Now().UTC().Truncate(time.Second)
+	steps := []func() error{
+		func() error { return store.SetPrivate("1", true) },
+		func() error { return store.SetPrivate("2", true) },
+		func() error { return store.SetPrivate("2", false) },
+		func() error {
+			return store.SaveFollowRequest(FollowRequest{ID: "r1", OwnUserSerial: "3", TargetUserSerial: "1", Status: FollowRequestPending, CreatedAt: createdAt})
+		},
+		func() error { return store.AddBlock("1", "4") },
+		func() error { return store.AddBlock("1", "5") },
+		func() error { return store.RemoveBlock("1", "5") },
+		func() error { return store.AddMute("1", "6") },
+		func() error {
+			return store.AddPostRevision(PostRevision{PostID: "p1", Post: "first", EditedAt: createdAt})
+		},
+	}
+	for i, step := range steps {
+		if err := step(); err != nil {
+			t.Fatalf("step %d: %v", i, err)
+		}
+	}
+
+	// Everything survives a restart
+	reloaded, err := NewFileStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for userSerial, want := range map[string]bool{"1": true, "2": false} {
+		if private, _ := reloaded.IsPrivate(userSerial); private != want {
+			t.Errorf("%s private = %v, want %v", userSerial, private, want)
+		}
+	}
+	requests, _ := reloaded.GetFollowRequests("", "1", FollowRequestPending)
+	if len(requests) != 1 || requests[0].ID != "r1" || !requests[0].CreatedAt.Equal(createdAt) {
+		t.Errorf("pending requests = %+v, want r1", requests)
+	}
+	if blocked, _ := reloaded.GetBlockedUserSerials("1"); !slices.Equal(blocked, []string{"4"}) {
+		t.Errorf("blocked = %v, want [4]", blocked)
+	}
+	if muted, _ := reloaded.GetMutedUserSerials("1"); !slices.Equal(muted, []string{"6"}) {
+		t.Errorf("muted = %v, want [6]", muted)
+	}
+	if revisions, _ := reloaded.GetPostRevisions("p1"); len(revisions) != 1 || revisions[0].Post != "first" {
+		t.Errorf("revisions = %+v, want the first one", revisions)
+	}
+}
+
+func TestFileStoreFailedFlush(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "getstream")
+	if err := os.Mkdir(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	store, err := NewFileStore(filepath.Join(dir, "getstream.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.SetPrivate("1", true); err != nil {
+		t.Fatal(err)
+	}
+
+	// Snapshots can't be written anymore
+	if err := os.RemoveAll(dir); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := store.SetPrivate("1", false); err == nil {
+		t.Fatal("privacy change succeeded without its snapshot")
+	}
+	if err := store.AddBlock("1", "2"); err == nil {
+		t.Fatal("block succeeded without its snapshot")
+	}
+
+	// Memory only holds what's on disk
+	if private, _ := store.IsPrivate("1"); !private {
+		t.Error("account became public without its snapshot")
+	}
+	if blocked, _ := store.IsBlocked("1", "2"); blocked {
+		t.Error("block was kept without its snapshot")
+	}
+}
@@ -0,0 +1,146 @@
+package getstream
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/wisnuanggoro/go-getstream/uid"
+)
+
+var (
+	ErrFollowRequestNotPending = errors.New("follow request is no longer pending")
+	ErrPrivateAccount          = errors.New("this account is private")
+)
+
+func (s *service) SetPrivateAccount(ctx context.Context, userSerial string, private bool) error {
+	return s.store.SetPrivate(userSerial, private)
+}
+
+func (s *service) GetIncomingFollowRequests(ctx context.Context, userSerial string) ([]FollowRequest, error) {
+	return s.store.GetFollowRequests("", userSerial, FollowRequestPending)
+}
+
+func (s *service) GetOutgoingFollowRequests(ctx context.Context, userSerial string) ([]FollowRequest, error) {
+	return s.store.GetFollowRequests(userSerial, "", FollowRequestPending)
+}
+
+func (s *service) ApproveFollowRequest(ctx context.Context, userSerial, requestID string) (*FollowRequest, error) {
+	request, err := s.getPendingFollowRequest(userSerial, requestID)
+	if err != nil {
+		return nil, err
+	}
+
+	// A block placed after the request was sent still wins
+	err = s.checkNotBlocked(request.OwnUserSerial, request.TargetUserSerial)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.follow(ctx, request.OwnUserSerial, request.TargetUserSerial)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.closeFollowRequest(request, FollowRequestApproved)
+}
+
+func (s *service) RejectFollowRequest(ctx context.Context, userSerial, requestID string) (*FollowRequest, error) {
+	request, err := s.getPendingFollowRequest(userSerial, requestID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.closeFollowRequest(request, FollowRequestRejected)
+}
+
+// requestFollow creates a pending request, or returns the one already waiting
+func (s *service) requestFollow(ctx context.Context, ownUserSerial, targetUserSerial string) (*FollowRequest, error) {
+	requests, err := s.store.GetFollowRequests(ownUserSerial, targetUserSerial, FollowRequestPending)
+	if err != nil {
+		return nil, err
+	}
+	if len(requests) > 0 {
+		return &requests[0], nil
+	}
+
+	now := time.Now().UTC()
+	request := FollowRequest{
+		ID:               uid.New(),
+		OwnUserSerial:    ownUserSerial,
+		TargetUserSerial: targetUserSerial,
+		Status:           FollowRequestPending,
+		CreatedAt:        now,
+		UpdatedAt:        now,
+	}
+	if err := s.store.SaveFollowRequest(request); err != nil {
+		return nil, err
+	}
+
+	s.events.emit(ctx, Event{Type: EventFollowRequested, UserSerial: ownUserSerial, TargetSerial: targetUserSerial})
+	return &request, nil
+}
+
+// getPendingFollowRequest returns the request only when it was sent to userSerial
+func (s *service) getPendingFollowRequest(userSerial, requestID string) (FollowRequest, error) {
+	request, err := s.store.GetFollowRequest(requestID)
+	if err != nil {
+		return FollowRequest{}, err
+	}
+	if request.TargetUserSerial != userSerial {
+		return FollowRequest{}, ErrFollowRequestNotFound
+	}
+	if request.Status != FollowRequestPending {
+		return FollowRequest{}, ErrFollowRequestNotPending
+	}
+	return request, nil
+}
+
+func (s *service) closeFollowRequest(request FollowRequest, status FollowRequestStatus) (*FollowRequest, error) {
+	request.Status = status
+	request.UpdatedAt = time.Now().UTC()
+	if err := s.store.SaveFollowRequest(request); err != nil {
+		return nil, err
+	}
+
+	return &request, nil
+}
+
+// rejectPendingFollowRequests drops every pending request from ownUserSerial to targetUserSerial
+func (s *service) rejectPendingFollowRequests(ownUserSerial, targetUserSerial string) error {
+	requests, err := s.store.GetFollowRequests(ownUserSerial, targetUserSerial, FollowRequestPending)
+	if err != nil {
+		return err
+	}
+
+	for _, request := range requests {
+		if _, err := s.closeFollowRequest(request, FollowRequestRejected); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkCanViewPosts returns ErrPrivateAccount when userSerial is private and
+// viewerUserSerial is neither the owner nor one of their followers
+func (s *service) checkCanViewPosts(viewerUserSerial, userSerial string) error {
+	private, err := s.store.IsPrivate(userSerial)
+	if err != nil {
+		return err
+	}
+	if !private || viewerUserSerial == userSerial {
+		return nil
+	}
+	if viewerUserSerial == "" {
+		return ErrPrivateAccount
+	}
+
+	following, err := s.followingAmong(viewerUserSerial, userSerial)
+	if err != nil {
+		return err
+	}
+	if !following[userSerial] {
+		return ErrPrivateAccount
+	}
+	return nil
+}
@@ -0,0 +1,121 @@
+package getstream
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestCheckCanViewPosts(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name string
+		// setup runs with user 1 private and returns who views their posts
+		setup   func(t *testing.T, svc *service) string
+		wantErr error
+	}{
+		{name: "owner", setup: func(t *testing.T, svc *service) string { return "1" }},
+		{name: "anonymous viewer", setup: func(t *testing.T, svc *service) string { return "" }, wantErr: ErrPrivateAccount},
+		{name: "non-follower", setup: func(t *testing.T, svc *service) string { return "2" }, wantErr: ErrPrivateAccount},
+		{
+			name: "pending request",
+			setup: func(t *testing.T, svc *service) string {
+				if _, err := svc.Follow(ctx, "2", "1"); err != nil {
+					t.Fatal(err)
+				}
+				return "2"
+			},
+			wantErr: ErrPrivateAccount,
+		},
+		{
+			name: "approved follower",
+			setup: func(t *testing.T, svc *service) string {
+				request, err := svc.Follow(ctx, "2", "1")
+				if err != nil {
+					t.Fatal(err)
+				}
+				if _, err := svc.ApproveFollowRequest(ctx, "1", request.ID); err != nil {
+					t.Fatal(err)
+				}
+				return "2"
+			},
+		},
+		{
+			name: "rejected request",
+			setup: func(t *testing.T, svc *service) string {
+				request, err := svc.Follow(ctx, "2", "1")
+				if err != nil {
+					t.Fatal(err)
+				}
+				if _, err := svc.RejectFollowRequest(ctx, "1", request.ID); err != nil {
+					t.Fatal(err)
+				}
+				return "2"
+			},
+			wantErr: ErrPrivateAccount,
+		},
+		{
+			name: "public again",
+			setup: func(t *testing.T, svc *service) string {
+				if err := svc.SetPrivateAccount(ctx, "1", false); err != nil {
+					t.Fatal(err)
+				}
+				return "2"
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, _ := newFakeStreamService(t, NewMemoryStore())
+			if err := svc.SetPrivateAccount(ctx, "1", true); err != nil {
+				t.Fatal(err)
+			}
+			viewer := tt.setup(t, svc)
+
+			if err := svc.checkCanViewPosts(viewer, "1"); !errors.Is(err, tt.wantErr) {
+				t.Errorf("err = %v, want %v", err, tt.wantErr)
+			}
+			// Reads of the posts are guarded the same way
+			if _, err := svc.GetPostByUserSerial(ctx, viewer, "1"); !errors.Is(err, tt.wantErr) {
+				t.Errorf("posts: err = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestFollowPrivateAccount(t *testing.T) {
+	ctx := context.Background()
+	svc, fake := newFakeStreamService(t, NewMemoryStore())
+	if err := svc.SetPrivateAccount(ctx, "1", true); err != nil {
+		t.Fatal(err)
+	}
+
+	// Following waits for approval, asking twice returns the same request
+	request, err := svc.Follow(ctx, "2", "1")
+	if err != nil || request == nil {
+		t.Fatalf("request = %v, err = %v, want a pending request", request, err)
+	}
+	again, err := svc.Follow(ctx, "2", "1")
+	if err != nil || again.ID != request.ID {
+		t.Fatalf("second request = %v, err = %v, want %s", again, err, request.ID)
+	}
+	if edges := fake.following(); len(edges) != 0 {
+		t.Fatalf("followed %v before the approval", edges)
+	}
+
+	// Only the requested user decides
+	if _, err := svc.ApproveFollowRequest(ctx, "2", request.ID); !errors.Is(err, ErrFollowRequestNotFound) {
+		t.Fatalf("approved by the requester: err = %v, want ErrFollowRequestNotFound", err)
+	}
+	if _, err := svc.ApproveFollowRequest(ctx, "1", request.ID); err != nil {
+		t.Fatal(err)
+	}
+	if edges := fake.following(); len(edges) != 1 || edges[0] != "timeline:2->user:1" {
+		t.Fatalf("follows = %v, want timeline:2->user:1", edges)
+	}
+	if _, err := svc.RejectFollowRequest(ctx, "1", request.ID); !errors.Is(err, ErrFollowRequestNotPending) {
+		t.Errorf("closed twice: err = %v, want ErrFollowRequestNotPending", err)
+	}
+}
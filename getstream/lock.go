@@ -0,0 +1,45 @@
+package getstream
+
+import "sync"
+
+// keyedMutex serialises callers per key, e.g. edits per post ID. It only
+// covers this process, instances of the server don't share it.
+type keyedMutex struct {
+	mu    sync.Mutex
+	locks map[string]*keyedLock
+}
+
+type keyedLock struct {
+	mu sync.Mutex
+	// holders counts the callers holding or waiting for mu
+	holders int
+}
+
+func newKeyedMutex() *keyedMutex {
+	return &keyedMutex{locks: map[string]*keyedLock{}}
+}
+
+// lock locks key and returns the func unlocking it. Locks are dropped once
+// nobody holds or waits for them.
+func (k *keyedMutex) lock(key string) (unlock func()) {
+	k.mu.Lock()
+	l, ok := k.locks[key]
+	if !ok {
+		l = &keyedLock{}
+		k.locks[key] = l
+	}
+	l.holders++
+	k.mu.Unlock()
+
+	l.mu.Lock()
+	return func() {
+		l.mu.Unlock()
+
+		k.mu.Lock()
+		defer k.mu.Unlock()
+		l.holders--
+		if l.holders == 0 {
+			delete(k.locks, key)
+		}
+	}
+}
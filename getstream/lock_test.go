@@ -0,0 +1,63 @@
+package getstream
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestKeyedMutex(t *testing.T) {
+	k := newKeyedMutex()
+
+	// Callers of a key take turns
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		running int
+		overlap bool
+	)
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			unlock := k.lock("post")
+			defer unlock()
+
+			mu.Lock()
+			running++
+			overlap = overlap || running > 1
+			mu.Unlock()
+
+			time.Sleep(time.Millisecond)
+
+			mu.Lock()
+			running--
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+	if overlap {
+		t.Error("callers of the same key overlapped")
+	}
+
+	// Other keys don't wait
+	unlock := k.lock("a")
+	done := make(chan struct{})
+	go func() {
+		k.lock("b")()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("another key waited for a held one")
+	}
+	unlock()
+
+	// Released locks are dropped
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if len(k.locks) != 0 {
+		t.Errorf("%d locks left, want none", len(k.locks))
+	}
+}
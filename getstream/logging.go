@@ -0,0 +1,290 @@
+package getstream
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	stream "gopkg.in/GetStream/stream-go2.v3"
+
+	"github.com/wisnuanggoro/go-getstream/logging"
+)
+
+// loggingService writes a structured log line for every call of the wrapped
+// Service, tagged with the request ID carried by ctx
+type loggingService struct {
+	next     Service
+	logger   *slog.Logger
+	topology Topology
+}
+
+// NewLoggingService names the feeds of calls after their group in topology
+func NewLoggingService(next Service, logger *slog.Logger, topology Topology) Service {
+	return &loggingService{
+		next:     next,
+		logger:   logger,
+		topology: topology,
+	}
+}
+
+func (l *loggingService) log(ctx context.Context, method string, start time.Time, err error, args ...slog.Attr) {
+	attrs := []slog.Attr{
+		slog.String("request_id", logging.RequestID(ctx)),
+		slog.String("method", method),
+		slog.Duration("duration", time.Since(start)),
+	}
+	attrs = append(attrs, args...)
+
+	// Rule violations like a missing post are the caller's fault, not ours
+	level := slog.LevelInfo
+	if err != nil {
+		level = slog.LevelError
+		if ErrorClass(err) == ErrorClassDomain {
+			level = slog.LevelWarn
+		}
+		attrs = append(attrs, slog.String("error", err.Error()), slog.String("error_class", ErrorClass(err)))
+	}
+
+	l.logger.LogAttrs(ctx, level, "getstream call", attrs...)
+}
+
+// feedGroupAttr and feedIDAttr name the feed a call reads or writes, the
+// followed feed for follow graph changes
+func feedGroupAttr(group string) slog.Attr {
+	return slog.String("feed_group", group)
+}
+
+func feedIDAttr(group, userSerial string) slog.Attr {
+	return slog.String("feed_id", group+":"+userSerial)
+}
+
+func (l *loggingService) AddPostByUserSerial(ctx context.Context, userSerial, postContent, postType string) (*stream.AddActivityResponse, error) {
+	start := time.Now()
+	resp, err := l.next.AddPostByUserSerial(ctx, userSerial, postContent, postType)
+	l.log(ctx, "AddPostByUserSerial", start, err, slog.String("userSerial", userSerial), slog.String("postType", postType), feedGroupAttr(l.topology.PostGroup), feedIDAttr(l.topology.PostGroup, userSerial))
+	return resp, err
+}
+
+func (l *loggingService) AddScheduledPostByUserSerial(ctx context.Context, userSerial, scheduleID, postContent, postType string, publishAt time.Time) (*stream.AddActivityResponse, error) {
+	start := time.Now()
+	resp, err := l.next.AddScheduledPostByUserSerial(ctx, userSerial, scheduleID, postContent, postType, publishAt)
+	l.log(ctx, "AddScheduledPostByUserSerial", start, err, slog.String("userSerial", userSerial), slog.String("scheduleID", scheduleID), slog.String("postType", postType), feedGroupAttr(l.topology.PostGroup), feedIDAttr(l.topology.PostGroup, userSerial))
+	return resp, err
+}
+
+func (l *loggingService) GetPostByUserSerial(ctx context.Context, viewerUserSerial, userSerial string) (*stream.FlatFeedResponse, error) {
+	start := time.Now()
+	resp, err := l.next.GetPostByUserSerial(ctx, viewerUserSerial, userSerial)
+	l.log(ctx, "GetPostByUserSerial", start, err, slog.String("viewerUserSerial", viewerUserSerial), slog.String("userSerial", userSerial), feedGroupAttr(l.topology.PostGroup), feedIDAttr(l.topology.PostGroup, userSerial))
+	return resp, err
+}
+
+func (l *loggingService) GetPostDetailByUserSerial(ctx context.Context, viewerUserSerial, userSerial string) (*stream.EnrichedFlatFeedResponse, error) {
+	start := time.Now()
+	resp, err := l.next.GetPostDetailByUserSerial(ctx, viewerUserSerial, userSerial)
+	l.log(ctx, "GetPostDetailByUserSerial", start, err, slog.String("viewerUserSerial", viewerUserSerial), slog.String("userSerial", userSerial), feedGroupAttr(l.topology.PostGroup), feedIDAttr(l.topology.PostGroup, userSerial))
+	return resp, err
+}
+
+func (l *loggingService) EditPostByPostID(ctx context.Context, userSerial, postID, postContent, postType string) (*stream.UpdateActivityResponse, error) {
+	start := time.Now()
+	resp, err := l.next.EditPostByPostID(ctx, userSerial, postID, postContent, postType)
+	l.log(ctx, "EditPostByPostID", start, err, slog.String("userSerial", userSerial), slog.String("postID", postID), slog.String("postType", postType), feedGroupAttr(l.topology.PostGroup), feedIDAttr(l.topology.PostGroup, userSerial))
+	return resp, err
+}
+
+func (l *loggingService) GetPostHistoryByPostID(ctx context.Context, viewerUserSerial, postID string) ([]PostRevision, error) {
+	start := time.Now()
+	resp, err := l.next.GetPostHistoryByPostID(ctx, viewerUserSerial, postID)
+	l.log(ctx, "GetPostHistoryByPostID", start, err, slog.String("viewerUserSerial", viewerUserSerial), slog.String("postID", postID), feedGroupAttr(l.topology.PostGroup))
+	return resp, err
+}
+
+func (l *loggingService) DeletePostByPostID(ctx context.Context, userSerial, postID string) error {
+	start := time.Now()
+	err := l.next.DeletePostByPostID(ctx, userSerial, postID)
+	l.log(ctx, "DeletePostByPostID", start, err, slog.String("userSerial", userSerial), slog.String("postID", postID), feedGroupAttr(l.topology.PostGroup), feedIDAttr(l.topology.PostGroup, userSerial))
+	return err
+}
+
+func (l *loggingService) GetTimelineByUserSerial(ctx context.Context, userSerial string) (*stream.FlatFeedResponse, error) {
+	start := time.Now()
+	resp, err := l.next.GetTimelineByUserSerial(ctx, userSerial)
+	l.log(ctx, "GetTimelineByUserSerial", start, err, slog.String("userSerial", userSerial), feedGroupAttr(l.topology.TimelineGroup), feedIDAttr(l.topology.TimelineGroup, userSerial))
+	return resp, err
+}
+
+func (l *loggingService) GetDetailTimelineByUserSerial(ctx context.Context, userSerial string) (*stream.EnrichedFlatFeedResponse, error) {
+	start := time.Now()
+	resp, err := l.next.GetDetailTimelineByUserSerial(ctx, userSerial)
+	l.log(ctx, "GetDetailTimelineByUserSerial", start, err, slog.String("userSerial", userSerial), feedGroupAttr(l.topology.TimelineGroup), feedIDAttr(l.topology.TimelineGroup, userSerial))
+	return resp, err
+}
+
+func (l *loggingService) GetFeedFollowersByUserSerial(ctx context.Context, userSerial string) (*stream.FollowersResponse, error) {
+	start := time.Now()
+	resp, err := l.next.GetFeedFollowersByUserSerial(ctx, userSerial)
+	l.log(ctx, "GetFeedFollowersByUserSerial", start, err, slog.String("userSerial", userSerial), feedGroupAttr(l.topology.PostGroup), feedIDAttr(l.topology.PostGroup, userSerial))
+	return resp, err
+}
+
+func (l *loggingService) GetFollowedFeedsByUserSerial(ctx context.Context, userSerial string) (*stream.FollowingResponse, error) {
+	start := time.Now()
+	resp, err := l.next.GetFollowedFeedsByUserSerial(ctx, userSerial)
+	l.log(ctx, "GetFollowedFeedsByUserSerial", start, err, slog.String("userSerial", userSerial), feedGroupAttr(l.topology.TimelineGroup), feedIDAttr(l.topology.TimelineGroup, userSerial))
+	return resp, err
+}
+
+func (l *loggingService) GetTimelineFollowersByUserSerial(ctx context.Context, userSerial string) ([]string, error) {
+	start := time.Now()
+	resp, err := l.next.GetTimelineFollowersByUserSerial(ctx, userSerial)
+	l.log(ctx, "GetTimelineFollowersByUserSerial", start, err, slog.String("userSerial", userSerial), feedGroupAttr(l.topology.PostGroup), feedIDAttr(l.topology.PostGroup, userSerial))
+	return resp, err
+}
+
+func (l *loggingService) Follow(ctx context.Context, ownUserSerial, targetUserSerial string) (*FollowRequest, error) {
+	start := time.Now()
+	resp, err := l.next.Follow(ctx, ownUserSerial, targetUserSerial)
+	l.log(ctx, "Follow", start, err, slog.String("ownUserSerial", ownUserSerial), slog.String("targetUserSerial", targetUserSerial), feedGroupAttr(l.topology.followerGroups()), feedIDAttr(l.topology.PostGroup, targetUserSerial))
+	return resp, err
+}
+
+func (l *loggingService) Unfollow(ctx context.Context, ownUserSerial, targetUserSerial string) error {
+	start := time.Now()
+	err := l.next.Unfollow(ctx, ownUserSerial, targetUserSerial)
+	l.log(ctx, "Unfollow", start, err, slog.String("ownUserSerial", ownUserSerial), slog.String("targetUserSerial", targetUserSerial), feedGroupAttr(l.topology.followerGroups()), feedIDAttr(l.topology.PostGroup, targetUserSerial))
+	return err
+}
+
+func (l *loggingService) AddLikeToPostID(ctx context.Context, likerUserSerial, postID string) (*stream.Reaction, error) {
+	start := time.Now()
+	resp, err := l.next.AddLikeToPostID(ctx, likerUserSerial, postID)
+	l.log(ctx, "AddLikeToPostID", start, err, slog.String("likerUserSerial", likerUserSerial), slog.String("postID", postID))
+	return resp, err
+}
+
+func (l *loggingService) RetrieveLikeDetailOnPostID(ctx context.Context, postID string, limit int) (*stream.FilterReactionResponse, error) {
+	start := time.Now()
+	resp, err := l.next.RetrieveLikeDetailOnPostID(ctx, postID, limit)
+	l.log(ctx, "RetrieveLikeDetailOnPostID", start, err, slog.String("postID", postID), slog.Int("limit", limit))
+	return resp, err
+}
+
+func (l *loggingService) RetrieveLikeDetailOnPostIDWithPagination(ctx context.Context, postID, nextLikeID string, limit int) (*stream.FilterReactionResponse, error) {
+	start := time.Now()
+	resp, err := l.next.RetrieveLikeDetailOnPostIDWithPagination(ctx, postID, nextLikeID, limit)
+	l.log(ctx, "RetrieveLikeDetailOnPostIDWithPagination", start, err, slog.String("postID", postID), slog.String("nextLikeID", nextLikeID), slog.Int("limit", limit))
+	return resp, err
+}
+
+func (l *loggingService) RemoveLikeByReactionID(ctx context.Context, reactionID string) error {
+	start := time.Now()
+	err := l.next.RemoveLikeByReactionID(ctx, reactionID)
+	l.log(ctx, "RemoveLikeByReactionID", start, err, slog.String("reactionID", reactionID))
+	return err
+}
+
+func (l *loggingService) BlockUser(ctx context.Context, ownUserSerial, targetUserSerial string) error {
+	start := time.Now()
+	err := l.next.BlockUser(ctx, ownUserSerial, targetUserSerial)
+	l.log(ctx, "BlockUser", start, err, slog.String("ownUserSerial", ownUserSerial), slog.String("targetUserSerial", targetUserSerial), feedGroupAttr(l.topology.followerGroups()), feedIDAttr(l.topology.PostGroup, targetUserSerial))
+	return err
+}
+
+func (l *loggingService) UnblockUser(ctx context.Context, ownUserSerial, targetUserSerial string) error {
+	start := time.Now()
+	err := l.next.UnblockUser(ctx, ownUserSerial, targetUserSerial)
+	l.log(ctx, "UnblockUser", start, err, slog.String("ownUserSerial", ownUserSerial), slog.String("targetUserSerial", targetUserSerial))
+	return err
+}
+
+func (l *loggingService) GetBlockedUsersByUserSerial(ctx context.Context, userSerial string) ([]string, error) {
+	start := time.Now()
+	resp, err := l.next.GetBlockedUsersByUserSerial(ctx, userSerial)
+	l.log(ctx, "GetBlockedUsersByUserSerial", start, err, slog.String("userSerial", userSerial))
+	return resp, err
+}
+
+func (l *loggingService) MuteUser(ctx context.Context, ownUserSerial, targetUserSerial string) error {
+	start := time.Now()
+	err := l.next.MuteUser(ctx, ownUserSerial, targetUserSerial)
+	l.log(ctx, "MuteUser", start, err, slog.String("ownUserSerial", ownUserSerial), slog.String("targetUserSerial", targetUserSerial))
+	return err
+}
+
+func (l *loggingService) UnmuteUser(ctx context.Context, ownUserSerial, targetUserSerial string) error {
+	start := time.Now()
+	err := l.next.UnmuteUser(ctx, ownUserSerial, targetUserSerial)
+	l.log(ctx, "UnmuteUser", start, err, slog.String("ownUserSerial", ownUserSerial), slog.String("targetUserSerial", targetUserSerial))
+	return err
+}
+
+func (l *loggingService) GetMutedUsersByUserSerial(ctx context.Context, userSerial string) ([]string, error) {
+	start := time.Now()
+	resp, err := l.next.GetMutedUsersByUserSerial(ctx, userSerial)
+	l.log(ctx, "GetMutedUsersByUserSerial", start, err, slog.String("userSerial", userSerial))
+	return resp, err
+}
+
+func (l *loggingService) SetPrivateAccount(ctx context.Context, userSerial string, private bool) error {
+	start := time.Now()
+	err := l.next.SetPrivateAccount(ctx, userSerial, private)
+	l.log(ctx, "SetPrivateAccount", start, err, slog.String("userSerial", userSerial), slog.Bool("private", private))
+	return err
+}
+
+func (l *loggingService) GetIncomingFollowRequests(ctx context.Context, userSerial string) ([]FollowRequest, error) {
+	start := time.Now()
+	resp, err := l.next.GetIncomingFollowRequests(ctx, userSerial)
+	l.log(ctx, "GetIncomingFollowRequests", start, err, slog.String("userSerial", userSerial))
+	return resp, err
+}
+
+func (l *loggingService) GetOutgoingFollowRequests(ctx context.Context, userSerial string) ([]FollowRequest, error) {
+	start := time.Now()
+	resp, err := l.next.GetOutgoingFollowRequests(ctx, userSerial)
+	l.log(ctx, "GetOutgoingFollowRequests", start, err, slog.String("userSerial", userSerial))
+	return resp, err
+}
+
+func (l *loggingService) ApproveFollowRequest(ctx context.Context, userSerial, requestID string) (*FollowRequest, error) {
+	start := time.Now()
+	resp, err := l.next.ApproveFollowRequest(ctx, userSerial, requestID)
+	l.log(ctx, "ApproveFollowRequest", start, err, slog.String("userSerial", userSerial), slog.String("requestID", requestID), feedGroupAttr(l.topology.followerGroups()))
+	return resp, err
+}
+
+func (l *loggingService) RejectFollowRequest(ctx context.Context, userSerial, requestID string) (*FollowRequest, error) {
+	start := time.Now()
+	resp, err := l.next.RejectFollowRequest(ctx, userSerial, requestID)
+	l.log(ctx, "RejectFollowRequest", start, err, slog.String("userSerial", userSerial), slog.String("requestID", requestID))
+	return resp, err
+}
+
+func (l *loggingService) GetFollowSuggestionsByUserSerial(ctx context.Context, userSerial string, limit int) ([]FollowSuggestion, error) {
+	start := time.Now()
+	resp, err := l.next.GetFollowSuggestionsByUserSerial(ctx, userSerial, limit)
+	l.log(ctx, "GetFollowSuggestionsByUserSerial", start, err, slog.String("userSerial", userSerial), slog.Int("limit", limit), feedGroupAttr(l.topology.PostGroup))
+	return resp, err
+}
+
+func (l *loggingService) GetRelationship(ctx context.Context, userSerial, otherSerial string) (*Relationship, error) {
+	start := time.Now()
+	resp, err := l.next.GetRelationship(ctx, userSerial, otherSerial)
+	l.log(ctx, "GetRelationship", start, err, slog.String("userSerial", userSerial), slog.String("otherSerial", otherSerial), feedGroupAttr(l.topology.TimelineGroup), feedIDAttr(l.topology.TimelineGroup, userSerial))
+	return resp, err
+}
+
+func (l *loggingService) GetRelationships(ctx context.Context, userSerial string, otherSerials []string) ([]Relationship, error) {
+	start := time.Now()
+	resp, err := l.next.GetRelationships(ctx, userSerial, otherSerials)
+	l.log(ctx, "GetRelationships", start, err, slog.String("userSerial", userSerial), slog.Any("otherSerials", otherSerials), feedGroupAttr(l.topology.TimelineGroup), feedIDAttr(l.topology.TimelineGroup, userSerial))
+	return resp, err
+}
+
+func (l *loggingService) Ping(ctx context.Context) error {
+	start := time.Now()
+	err := l.next.Ping(ctx)
+	l.log(ctx, "Ping", start, err, feedGroupAttr(l.topology.PostGroup), feedIDAttr(l.topology.PostGroup, "healthcheck"))
+	return err
+}
@@ -0,0 +1,97 @@
+package getstream
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+
+	stream "gopkg.in/GetStream/stream-go2.v3"
+)
+
+// faultyService fails its calls with err
+type faultyService struct {
+	Service
+	err error
+}
+
+func (f *faultyService) GetTimelineByUserSerial(ctx context.Context, userSerial string) (*stream.FlatFeedResponse, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &stream.FlatFeedResponse{}, nil
+}
+
+func (f *faultyService) AddPostByUserSerial(ctx context.Context, userSerial, postContent, postType string) (*stream.AddActivityResponse, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &stream.AddActivityResponse{}, nil
+}
+
+func TestLoggingServiceAttrs(t *testing.T) {
+	topology := Topology{PostGroup: "user", TimelineGroup: "timeline", FollowRules: []FollowRule{{Follower: "timeline", Followed: "user"}}}
+
+	tests := []struct {
+		name  string
+		fault error
+		call  func(svc Service) error
+		want  map[string]string
+	}{
+		{
+			name: "timeline read",
+			call: func(svc Service) error {
+				_, err := svc.GetTimelineByUserSerial(context.Background(), "1")
+				return err
+			},
+			want: map[string]string{"level": "INFO", "method": "GetTimelineByUserSerial", "feed_group": "timeline", "feed_id": "timeline:1"},
+		},
+		{
+			name: "post write",
+			call: func(svc Service) error {
+				_, err := svc.AddPostByUserSerial(context.Background(), "2", "hello", "text")
+				return err
+			},
+			want: map[string]string{"level": "INFO", "method": "AddPostByUserSerial", "feed_group": "user", "feed_id": "user:2"},
+		},
+		{
+			name:  "failed call",
+			fault: apiError(500),
+			call: func(svc Service) error {
+				_, err := svc.GetTimelineByUserSerial(context.Background(), "1")
+				return err
+			},
+			want: map[string]string{"level": "ERROR", "feed_group": "timeline", "feed_id": "timeline:1", "error_class": ErrorClassServer},
+		},
+		{
+			name:  "rule violation",
+			fault: ErrPostNotFound,
+			call: func(svc Service) error {
+				_, err := svc.AddPostByUserSerial(context.Background(), "2", "hello", "text")
+				return err
+			},
+			want: map[string]string{"level": "WARN", "feed_id": "user:2", "error_class": ErrorClassDomain},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			next := &faultyService{err: tt.fault}
+			svc := NewLoggingService(next, slog.New(slog.NewJSONHandler(&buf, nil)), topology)
+
+			_ = tt.call(svc)
+
+			var line map[string]any
+			if err := json.Unmarshal(buf.Bytes(), &line); err != nil {
+				t.Fatalf("log line %q: %v", buf.String(), err)
+			}
+			for key, want := range tt.want {
+				if got := line[key]; got != want {
+					t.Errorf("%s = %v, want %q", key, got, want)
+				}
+			}
+		})
+	}
+}
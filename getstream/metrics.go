@@ -0,0 +1,296 @@
+package getstream
+
+import (
+	"context"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	stream "gopkg.in/GetStream/stream-go2.v3"
+)
+
+// Metrics holds the collectors recording calls made through the Service
+type Metrics struct {
+	duration *prometheus.HistogramVec
+	errors   *prometheus.CounterVec
+	inFlight *prometheus.GaugeVec
+}
+
+// NewMetrics creates the Service collectors and registers them on registerer
+func NewMetrics(registerer prometheus.Registerer) *Metrics {
+	m := &Metrics{
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "getstream_call_duration_seconds",
+			Help:    "Latency of Service calls to Stream, by method.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"method"}),
+		errors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "getstream_call_errors_total",
+			Help: "Failed Service calls to Stream, by method and error class.",
+		}, []string{"method", "class"}),
+		inFlight: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "getstream_calls_in_flight",
+			Help: "Service calls to Stream currently running, by method.",
+		}, []string{"method"}),
+	}
+	registerer.MustRegister(m.duration, m.errors, m.inFlight)
+	return m
+}
+
+// observe records the start of a call and returns the func recording its end
+func (m *Metrics) observe(method string) func(err error) {
+	start := time.Now()
+	inFlight := m.inFlight.WithLabelValues(method)
+	inFlight.Inc()
+
+	return func(err error) {
+		inFlight.Dec()
+		m.duration.WithLabelValues(method).Observe(time.Since(start).Seconds())
+		if err != nil {
+			m.errors.WithLabelValues(method, ErrorClass(err)).Inc()
+		}
+	}
+}
+
+// metricsService records latency, errors and in-flight calls of the wrapped Service
+type metricsService struct {
+	next    Service
+	metrics *Metrics
+}
+
+func NewMetricsService(next Service, metrics *Metrics) Service {
+	return &metricsService{
+		next:    next,
+		metrics: metrics,
+	}
+}
+
+func (m *metricsService) AddPostByUserSerial(ctx context.Context, userSerial, postContent, postType string) (*stream.AddActivityResponse, error) {
+	done := m.metrics.observe("AddPostByUserSerial")
+	resp, err := m.next.AddPostByUserSerial(ctx, userSerial, postContent, postType)
+	done(err)
+	return resp, err
+}
+
+func (m *metricsService) AddScheduledPostByUserSerial(ctx context.Context, userSerial, scheduleID, postContent, postType string, publishAt time.Time) (*stream.AddActivityResponse, error) {
+	done := m.metrics.observe("AddScheduledPostByUserSerial")
+	resp, err := m.next.AddScheduledPostByUserSerial(ctx, userSerial, scheduleID, postContent, postType, publishAt)
+	done(err)
+	return resp, err
+}
+
+func (m *metricsService) GetPostByUserSerial(ctx context.Context, viewerUserSerial, userSerial string) (*stream.FlatFeedResponse, error) {
+	done := m.metrics.observe("GetPostByUserSerial")
+	resp, err := m.next.GetPostByUserSerial(ctx, viewerUserSerial, userSerial)
+	done(err)
+	return resp, err
+}
+
+func (m *metricsService) GetPostDetailByUserSerial(ctx context.Context, viewerUserSerial, userSerial string) (*stream.EnrichedFlatFeedResponse, error) {
+	done := m.metrics.observe("GetPostDetailByUserSerial")
+	resp, err := m.next.GetPostDetailByUserSerial(ctx, viewerUserSerial, userSerial)
+	done(err)
+	return resp, err
+}
+
+func (m *metricsService) EditPostByPostID(ctx context.Context, userSerial, postID, postContent, postType string) (*stream.UpdateActivityResponse, error) {
+	done := m.metrics.observe("EditPostByPostID")
+	resp, err := m.next.EditPostByPostID(ctx, userSerial, postID, postContent, postType)
+	done(err)
+	return resp, err
+}
+
+func (m *metricsService) GetPostHistoryByPostID(ctx context.Context, viewerUserSerial, postID string) ([]PostRevision, error) {
+	done := m.metrics.observe("GetPostHistoryByPostID")
+	resp, err := m.next.GetPostHistoryByPostID(ctx, viewerUserSerial, postID)
+	done(err)
+	return resp, err
+}
+
+func (m *metricsService) DeletePostByPostID(ctx context.Context, userSerial, postID string) error {
+	done := m.metrics.observe("DeletePostByPostID")
+	err := m.next.DeletePostByPostID(ctx, userSerial, postID)
+	done(err)
+	return err
+}
+
+func (m *metricsService) GetTimelineByUserSerial(ctx context.Context, userSerial string) (*stream.FlatFeedResponse, error) {
+	done := m.metrics.observe("GetTimelineByUserSerial")
+	resp, err := m.next.GetTimelineByUserSerial(ctx, userSerial)
+	done(err)
+	return resp, err
+}
+
+func (m *metricsService) GetDetailTimelineByUserSerial(ctx context.Context, userSerial string) (*stream.EnrichedFlatFeedResponse, error) {
+	done := m.metrics.observe("GetDetailTimelineByUserSerial")
+	resp, err := m.next.GetDetailTimelineByUserSerial(ctx, userSerial)
+	done(err)
+	return resp, err
+}
+
+func (m *metricsService) GetFeedFollowersByUserSerial(ctx context.Context, userSerial string) (*stream.FollowersResponse, error) {
+	done := m.metrics.observe("GetFeedFollowersByUserSerial")
+	resp, err := m.next.GetFeedFollowersByUserSerial(ctx, userSerial)
+	done(err)
+	return resp, err
+}
+
+func (m *metricsService) GetFollowedFeedsByUserSerial(ctx context.Context, userSerial string) (*stream.FollowingResponse, error) {
+	done := m.metrics.observe("GetFollowedFeedsByUserSerial")
+	resp, err := m.next.GetFollowedFeedsByUserSerial(ctx, userSerial)
+	done(err)
+	return resp, err
+}
+
+func (m *metricsService) GetTimelineFollowersByUserSerial(ctx context.Context, userSerial string) ([]string, error) {
+	done := m.metrics.observe("GetTimelineFollowersByUserSerial")
+	resp, err := m.next.GetTimelineFollowersByUserSerial(ctx, userSerial)
+	done(err)
+	return resp, err
+}
+
+func (m *metricsService) Follow(ctx context.Context, ownUserSerial, targetUserSerial string) (*FollowRequest, error) {
+	done := m.metrics.observe("Follow")
+	resp, err := m.next.Follow(ctx, ownUserSerial, targetUserSerial)
+	done(err)
+	return resp, err
+}
+
+func (m *metricsService) Unfollow(ctx context.Context, ownUserSerial, targetUserSerial string) error {
+	done := m.metrics.observe("Unfollow")
+	err := m.next.Unfollow(ctx, ownUserSerial, targetUserSerial)
+	done(err)
+	return err
+}
+
+func (m *metricsService) AddLikeToPostID(ctx context.Context, likerUserSerial, postID string) (*stream.Reaction, error) {
+	done := m.metrics.observe("AddLikeToPostID")
+	resp, err := m.next.AddLikeToPostID(ctx, likerUserSerial, postID)
+	done(err)
+	return resp, err
+}
+
+func (m *metricsService) RetrieveLikeDetailOnPostID(ctx context.Context, postID string, limit int) (*stream.FilterReactionResponse, error) {
+	done := m.metrics.observe("RetrieveLikeDetailOnPostID")
+	resp, err := m.next.RetrieveLikeDetailOnPostID(ctx, postID, limit)
+	done(err)
+	return resp, err
+}
+
+func (m *metricsService) RetrieveLikeDetailOnPostIDWithPagination(ctx context.Context, postID, nextLikeID string, limit int) (*stream.FilterReactionResponse, error) {
+	done := m.metrics.observe("RetrieveLikeDetailOnPostIDWithPagination")
+	resp, err := m.next.RetrieveLikeDetailOnPostIDWithPagination(ctx, postID, nextLikeID, limit)
+	done(err)
+	return resp, err
+}
+
+func (m *metricsService) RemoveLikeByReactionID(ctx context.Context, reactionID string) error {
+	done := m.metrics.observe("RemoveLikeByReactionID")
+	err := m.next.RemoveLikeByReactionID(ctx, reactionID)
+	done(err)
+	return err
+}
+
+func (m *metricsService) BlockUser(ctx context.Context, ownUserSerial, targetUserSerial string) error {
+	done := m.metrics.observe("BlockUser")
+	err := m.next.BlockUser(ctx, ownUserSerial, targetUserSerial)
+	done(err)
+	return err
+}
+
+func (m *metricsService) UnblockUser(ctx context.Context, ownUserSerial, targetUserSerial string) error {
+	done := m.metrics.observe("UnblockUser")
+	err := m.next.UnblockUser(ctx, ownUserSerial, targetUserSerial)
+	done(err)
+	return err
+}
+
+func (m *metricsService) GetBlockedUsersByUserSerial(ctx context.Context, userSerial string) ([]string, error) {
+	done := m.metrics.observe("GetBlockedUsersByUserSerial")
+	resp, err := m.next.GetBlockedUsersByUserSerial(ctx, userSerial)
+	done(err)
+	return resp, err
+}
+
+func (m *metricsService) MuteUser(ctx context.Context, ownUserSerial, targetUserSerial string) error {
+	done := m.metrics.observe("MuteUser")
+	err := m.next.MuteUser(ctx, ownUserSerial, targetUserSerial)
+	done(err)
+	return err
+}
+
+func (m *metricsService) UnmuteUser(ctx context.Context, ownUserSerial, targetUserSerial string) error {
+	done := m.metrics.observe("UnmuteUser")
+	err := m.next.UnmuteUser(ctx, ownUserSerial, targetUserSerial)
+	done(err)
+	return err
+}
+
+func (m *metricsService) GetMutedUsersByUserSerial(ctx context.Context, userSerial string) ([]string, error) {
+	done := m.metrics.observe("GetMutedUsersByUserSerial")
+	resp, err := m.next.GetMutedUsersByUserSerial(ctx, userSerial)
+	done(err)
+	return resp, err
+}
+
+func (m *metricsService) SetPrivateAccount(ctx context.Context, userSerial string, private bool) error {
+	done := m.metrics.observe("SetPrivateAccount")
+	err := m.next.SetPrivateAccount(ctx, userSerial, private)
+	done(err)
+	return err
+}
+
+func (m *metricsService) GetIncomingFollowRequests(ctx context.Context, userSerial string) ([]FollowRequest, error) {
+	done := m.metrics.observe("GetIncomingFollowRequests")
+	resp, err := m.next.GetIncomingFollowRequests(ctx, userSerial)
+	done(err)
+	return resp, err
+}
+
+func (m *metricsService) GetOutgoingFollowRequests(ctx context.Context, userSerial string) ([]FollowRequest, error) {
+	done := m.metrics.observe("GetOutgoingFollowRequests")
+	resp, err := m.next.GetOutgoingFollowRequests(ctx, userSerial)
+	done(err)
+	return resp, err
+}
+
+func (m *metricsService) ApproveFollowRequest(ctx context.Context, userSerial, requestID string) (*FollowRequest, error) {
+	done := m.metrics.observe("ApproveFollowRequest")
+	resp, err := m.next.ApproveFollowRequest(ctx, userSerial, requestID)
+	done(err)
+	return resp, err
+}
+
+func (m *metricsService) RejectFollowRequest(ctx context.Context, userSerial, requestID string) (*FollowRequest, error) {
+	done := m.metrics.observe("RejectFollowRequest")
+	resp, err := m.next.RejectFollowRequest(ctx, userSerial, requestID)
+	done(err)
+	return resp, err
+}
+
+func (m *metricsService) GetFollowSuggestionsByUserSerial(ctx context.Context, userSerial string, limit int) ([]FollowSuggestion, error) {
+	done := m.metrics.observe("GetFollowSuggestionsByUserSerial")
+	resp, err := m.next.GetFollowSuggestionsByUserSerial(ctx, userSerial, limit)
+	done(err)
+	return resp, err
+}
+
+func (m *metricsService) GetRelationship(ctx context.Context, userSerial, otherSerial string) (*Relationship, error) {
+	done := m.metrics.observe("GetRelationship")
+	resp, err := m.next.GetRelationship(ctx, userSerial, otherSerial)
+	done(err)
+	return resp, err
+}
+
+func (m *metricsService) GetRelationships(ctx context.Context, userSerial string, otherSerials []string) ([]Relationship, error) {
+	done := m.metrics.observe("GetRelationships")
+	resp, err := m.next.GetRelationships(ctx, userSerial, otherSerials)
+	done(err)
+	return resp, err
+}
+
+func (m *metricsService) Ping(ctx context.Context) error {
+	done := m.metrics.observe("Ping")
+	err := m.next.Ping(ctx)
+	done(err)
+	return err
+}
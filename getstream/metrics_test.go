@@ -0,0 +1,82 @@
+package getstream
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	stream "gopkg.in/GetStream/stream-go2.v3"
+)
+
+func TestMetricsService(t *testing.T) {
+	ctx := context.Background()
+	registry := prometheus.NewRegistry()
+	metrics := NewMetrics(registry)
+	next := &faultyService{}
+	svc := NewMetricsService(next, metrics)
+
+	for i := 0; i < 2; i++ {
+		if _, err := svc.GetTimelineByUserSerial(ctx, "1"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	next.err = ErrCircuitOpen
+	svc.GetTimelineByUserSerial(ctx, "1")
+	next.err = stream.APIError{StatusCode: 429}
+	svc.AddPostByUserSerial(ctx, "1", "hello", "text")
+	next.err = errors.New("boom")
+	svc.AddPostByUserSerial(ctx, "1", "hello", "text")
+
+	// Every call is timed, failed ones are counted by error class
+	if got := testutil.CollectAndCount(metrics.duration); got != 2 {
+		t.Errorf("duration series = %d, want one per method", got)
+	}
+	for method, want := range map[string]uint64{"GetTimelineByUserSerial": 3, "AddPostByUserSerial": 2} {
+		if got := callCount(t, registry, method); got != want {
+			t.Errorf("%s calls = %d, want %d", method, got, want)
+		}
+	}
+	errorCounts := map[[2]string]float64{
+		{"GetTimelineByUserSerial", ErrorClassCircuitOpen}: 1,
+		{"AddPostByUserSerial", ErrorClassRateLimited}:     1,
+		{"AddPostByUserSerial", ErrorClassUnknown}:         1,
+	}
+	if got := testutil.CollectAndCount(metrics.errors); got != len(errorCounts) {
+		t.Errorf("error series = %d, want %d", got, len(errorCounts))
+	}
+	for labels, want := range errorCounts {
+		if got := testutil.ToFloat64(metrics.errors.WithLabelValues(labels[0], labels[1])); got != want {
+			t.Errorf("%s %s errors = %v, want %v", labels[0], labels[1], got, want)
+		}
+	}
+
+	// Nothing is left in flight
+	for _, method := range []string{"GetTimelineByUserSerial", "AddPostByUserSerial"} {
+		if got := testutil.ToFloat64(metrics.inFlight.WithLabelValues(method)); got != 0 {
+			t.Errorf("%s in flight = %v, want 0", method, got)
+		}
+	}
+}
+
+// callCount returns how many calls of method were timed
+func callCount(t *testing.T, registry *prometheus.Registry, method string) uint64 {
+	families, err := registry.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, family := range families {
+		if family.GetName() != "getstream_call_duration_seconds" {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			for _, label := range metric.GetLabel() {
+				if label.GetName() == "method" && label.GetValue() == method {
+					return metric.GetHistogram().GetSampleCount()
+				}
+			}
+		}
+	}
+	return 0
+}
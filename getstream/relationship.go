@@ -0,0 +1,80 @@
+package getstream
+
+import (
+	"context"
+
+	stream "gopkg.in/GetStream/stream-go2.v3"
+)
+
+func (s *service) GetRelationship(ctx context.Context, userSerial, otherSerial string) (*Relationship, error) {
+	relationships, err := s.GetRelationships(ctx, userSerial, []string{otherSerial})
+	if err != nil {
+		return nil, err
+	}
+
+	return &relationships[0], nil
+}
+
+func (s *service) GetRelationships(ctx context.Context, userSerial string, otherSerials []string) ([]Relationship, error) {
+	// One filtered lookup covers every user followed by userSerial
+	following, err := s.followingAmong(userSerial, otherSerials...)
+	if err != nil {
+		return nil, err
+	}
+
+	relationships := []Relationship{}
+	for _, otherSerial := range otherSerials {
+		// The other direction needs a lookup per user
+		followedBy, err := s.followingAmong(otherSerial, userSerial)
+		if err != nil {
+			return nil, err
+		}
+
+		relationships = append(relationships, Relationship{
+			UserSerial:  userSerial,
+			OtherSerial: otherSerial,
+			Following:   following[otherSerial],
+			FollowedBy:  followedBy[userSerial],
+			Mutual:      following[otherSerial] && followedBy[userSerial],
+		})
+	}
+
+	return relationships, nil
+}
+
+// followingAmong tells which of targetUserSerials are followed by the timeline of ownUserSerial
+func (s *service) followingAmong(ownUserSerial string, targetUserSerials ...string) (map[string]bool, error) {
+	following := map[string]bool{}
+	if len(targetUserSerials) == 0 {
+		return following, nil
+	}
+
+	// Get timeline feed object
+	ownUserFlatFeed, err := s.getstreamClient.FlatFeed(s.topology.TimelineGroup, ownUserSerial)
+	if err != nil {
+		return nil, err
+	}
+
+	// Only look for the target feeds among the followed ones
+	targetFeedIDs := []string{}
+	for _, targetUserSerial := range targetUserSerials {
+		targetUserFlatFeed, err := s.getstreamClient.FlatFeed(s.topology.PostGroup, targetUserSerial)
+		if err != nil {
+			return nil, err
+		}
+		targetFeedIDs = append(targetFeedIDs, targetUserFlatFeed.ID())
+	}
+
+	resp, err := ownUserFlatFeed.GetFollowing(
+		stream.WithFollowingFilter(targetFeedIDs...),
+		stream.WithFollowingLimit(len(targetFeedIDs)),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, followed := range resp.Results {
+		following[userSerialFromActor(followed.TargetID)] = true
+	}
+	return following, nil
+}
@@ -0,0 +1,50 @@
+package getstream
+
+import (
+	"context"
+	"net/http"
+	"testing"
+)
+
+func TestGetRelationships(t *testing.T) {
+	ctx := context.Background()
+	svc, fake := newFakeStreamService(t, NewMemoryStore())
+	fake.follow("timeline:1", "user:2")
+	fake.follow("timeline:2", "user:1")
+	fake.follow("timeline:1", "user:3")
+	fake.follow("timeline:4", "user:1")
+	// Other feeds of user 5 don't make them a follower
+	fake.follow("notification:5", "user:1")
+
+	relationships, err := svc.GetRelationships(ctx, "1", []string{"2", "3", "4", "5"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Relationship{
+		{UserSerial: "1", OtherSerial: "2", Following: true, FollowedBy: true, Mutual: true},
+		{UserSerial: "1", OtherSerial: "3", Following: true},
+		{UserSerial: "1", OtherSerial: "4", FollowedBy: true},
+		{UserSerial: "1", OtherSerial: "5"},
+	}
+	if len(relationships) != len(want) {
+		t.Fatalf("relationships = %+v, want %+v", relationships, want)
+	}
+	for i := range want {
+		if relationships[i] != want[i] {
+			t.Errorf("relationship %d = %+v, want %+v", i, relationships[i], want[i])
+		}
+	}
+
+	relationship, err := svc.GetRelationship(ctx, "4", "1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *relationship != (Relationship{UserSerial: "4", OtherSerial: "1", Following: true}) {
+		t.Errorf("relationship = %+v, want 4 following 1", relationship)
+	}
+
+	fake.fail(fault{status: http.StatusInternalServerError})
+	if _, err := svc.GetRelationship(ctx, "1", "2"); err == nil {
+		t.Error("relationship returned while Stream fails")
+	}
+}
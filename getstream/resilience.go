@@ -0,0 +1,394 @@
+package getstream
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"sync"
+	"time"
+
+	stream "gopkg.in/GetStream/stream-go2.v3"
+)
+
+var ErrCircuitOpen = errors.New("getstream is unavailable, circuit breaker is open")
+
+// ResiliencePolicy configures retries and circuit breaking of Service calls
+type ResiliencePolicy struct {
+	// MaxAttempts is the number of tries for idempotent reads, 1 disables retries
+	MaxAttempts    int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	// FailureThreshold consecutive failures of an operation open its breaker
+	FailureThreshold int
+	// OpenTimeout is how long an open breaker rejects calls before letting a probe through
+	OpenTimeout time.Duration
+}
+
+// resilientService retries idempotent reads of the wrapped Service and stops
+// calling an operation for a while once it keeps failing
+type resilientService struct {
+	next     Service
+	policy   ResiliencePolicy
+	mu       sync.Mutex
+	breakers map[string]*breaker
+}
+
+func NewResilientService(next Service, policy ResiliencePolicy) Service {
+	if policy.MaxAttempts < 1 {
+		policy.MaxAttempts = 1
+	}
+
+	return &resilientService{
+		next:     next,
+		policy:   policy,
+		breakers: map[string]*breaker{},
+	}
+}
+
+func (r *resilientService) breaker(method string) *breaker {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.breakers[method] == nil {
+		r.breakers[method] = &breaker{
+			failureThreshold: r.policy.FailureThreshold,
+			openTimeout:      r.policy.OpenTimeout,
+		}
+	}
+	return r.breakers[method]
+}
+
+// callWithResilience runs fn through the breaker of method, retrying it with
+// exponential backoff and jitter when idempotent is set
+func callWithResilience[T any](ctx context.Context, r *resilientService, method string, idempotent bool, fn func() (T, error)) (T, error) {
+	b := r.breaker(method)
+
+	attempts := 1
+	if idempotent {
+		attempts = r.policy.MaxAttempts
+	}
+
+	var resp T
+	var err error
+	for attempt := 1; ; attempt++ {
+		if !b.allow() {
+			return resp, ErrCircuitOpen
+		}
+
+		resp, err = fn()
+		// Rule violations and rejected requests say nothing about the
+		// health of Stream, they only end a probe
+		switch {
+		case err == nil:
+			b.record(false)
+		case isTransient(err):
+			b.record(true)
+		default:
+			b.release()
+		}
+		if err == nil || !isTransient(err) || attempt >= attempts {
+			return resp, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return resp, err
+		case <-time.After(r.backoff(attempt, err)):
+		}
+	}
+}
+
+// backoff returns how long to wait before the next attempt, preferring the
+// reset time Stream sends along with a rate limited response
+func (r *resilientService) backoff(attempt int, err error) time.Duration {
+	var apiErr stream.APIError
+	if ErrorClass(err) == ErrorClassRateLimited && errors.As(err, &apiErr) && apiErr.Rate != nil {
+		if wait := time.Until(time.Unix(apiErr.Rate.Reset.Unix(), 0)); wait > 0 {
+			return min(wait, r.policy.MaxBackoff)
+		}
+	}
+
+	// Full jitter over an exponentially growing window
+	window := r.policy.InitialBackoff << (attempt - 1)
+	if window <= 0 || window > r.policy.MaxBackoff {
+		window = r.policy.MaxBackoff
+	}
+	return time.Duration(rand.Int63n(int64(window) + 1))
+}
+
+// isTransient tells whether err is worth retrying and counts against the breaker
+func isTransient(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	switch ErrorClass(err) {
+	case ErrorClassServer, ErrorClassRateLimited, ErrorClassTimeout, ErrorClassNetwork:
+		return true
+	default:
+		return false
+	}
+}
+
+// breaker is a consecutive failures circuit breaker. Once open it rejects
+// calls until openTimeout passed, then lets a single probe through whose
+// outcome closes it again or keeps it open
+type breaker struct {
+	mu               sync.Mutex
+	failureThreshold int
+	openTimeout      time.Duration
+	failures         int
+	openUntil        time.Time
+	probing          bool
+}
+
+func (b *breaker) allow() bool {
+	if b.failureThreshold <= 0 {
+		return true
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.failures < b.failureThreshold {
+		return true
+	}
+	if time.Now().Before(b.openUntil) || b.probing {
+		return false
+	}
+
+	b.probing = true
+	return true
+}
+
+// release ends a call without counting it as a success or a failure
+func (b *breaker) release() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.probing = false
+}
+
+func (b *breaker) record(failed bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.probing = false
+	if !failed {
+		b.failures = 0
+		return
+	}
+
+	b.failures++
+	if b.failures >= b.failureThreshold {
+		b.openUntil = time.Now().Add(b.openTimeout)
+	}
+}
+
+func (r *resilientService) AddPostByUserSerial(ctx context.Context, userSerial, postContent, postType string) (*stream.AddActivityResponse, error) {
+	return callWithResilience(ctx, r, "AddPostByUserSerial", false, func() (*stream.AddActivityResponse, error) {
+		return r.next.AddPostByUserSerial(ctx, userSerial, postContent, postType)
+	})
+}
+
+// Publishing a scheduled post again doesn't duplicate it, so it's retried
+func (r *resilientService) AddScheduledPostByUserSerial(ctx context.Context, userSerial, scheduleID, postContent, postType string, publishAt time.Time) (*stream.AddActivityResponse, error) {
+	return callWithResilience(ctx, r, "AddScheduledPostByUserSerial", true, func() (*stream.AddActivityResponse, error) {
+		return r.next.AddScheduledPostByUserSerial(ctx, userSerial, scheduleID, postContent, postType, publishAt)
+	})
+}
+
+func (r *resilientService) GetPostByUserSerial(ctx context.Context, viewerUserSerial, userSerial string) (*stream.FlatFeedResponse, error) {
+	return callWithResilience(ctx, r, "GetPostByUserSerial", true, func() (*stream.FlatFeedResponse, error) {
+		return r.next.GetPostByUserSerial(ctx, viewerUserSerial, userSerial)
+	})
+}
+
+func (r *resilientService) GetPostDetailByUserSerial(ctx context.Context, viewerUserSerial, userSerial string) (*stream.EnrichedFlatFeedResponse, error) {
+	return callWithResilience(ctx, r, "GetPostDetailByUserSerial", true, func() (*stream.EnrichedFlatFeedResponse, error) {
+		return r.next.GetPostDetailByUserSerial(ctx, viewerUserSerial, userSerial)
+	})
+}
+
+func (r *resilientService) EditPostByPostID(ctx context.Context, userSerial, postID, postContent, postType string) (*stream.UpdateActivityResponse, error) {
+	return callWithResilience(ctx, r, "EditPostByPostID", false, func() (*stream.UpdateActivityResponse, error) {
+		return r.next.EditPostByPostID(ctx, userSerial, postID, postContent, postType)
+	})
+}
+
+func (r *resilientService) GetPostHistoryByPostID(ctx context.Context, viewerUserSerial, postID string) ([]PostRevision, error) {
+	return callWithResilience(ctx, r, "GetPostHistoryByPostID", true, func() ([]PostRevision, error) {
+		return r.next.GetPostHistoryByPostID(ctx, viewerUserSerial, postID)
+	})
+}
+
+func (r *resilientService) DeletePostByPostID(ctx context.Context, userSerial, postID string) error {
+	_, err := callWithResilience(ctx, r, "DeletePostByPostID", false, func() (struct{}, error) {
+		return struct{}{}, r.next.DeletePostByPostID(ctx, userSerial, postID)
+	})
+	return err
+}
+
+func (r *resilientService) GetTimelineByUserSerial(ctx context.Context, userSerial string) (*stream.FlatFeedResponse, error) {
+	return callWithResilience(ctx, r, "GetTimelineByUserSerial", true, func() (*stream.FlatFeedResponse, error) {
+		return r.next.GetTimelineByUserSerial(ctx, userSerial)
+	})
+}
+
+func (r *resilientService) GetDetailTimelineByUserSerial(ctx context.Context, userSerial string) (*stream.EnrichedFlatFeedResponse, error) {
+	return callWithResilience(ctx, r, "GetDetailTimelineByUserSerial", true, func() (*stream.EnrichedFlatFeedResponse, error) {
+		return r.next.GetDetailTimelineByUserSerial(ctx, userSerial)
+	})
+}
+
+func (r *resilientService) GetFeedFollowersByUserSerial(ctx context.Context, userSerial string) (*stream.FollowersResponse, error) {
+	return callWithResilience(ctx, r, "GetFeedFollowersByUserSerial", true, func() (*stream.FollowersResponse, error) {
+		return r.next.GetFeedFollowersByUserSerial(ctx, userSerial)
+	})
+}
+
+func (r *resilientService) GetFollowedFeedsByUserSerial(ctx context.Context, userSerial string) (*stream.FollowingResponse, error) {
+	return callWithResilience(ctx, r, "GetFollowedFeedsByUserSerial", true, func() (*stream.FollowingResponse, error) {
+		return r.next.GetFollowedFeedsByUserSerial(ctx, userSerial)
+	})
+}
+
+func (r *resilientService) GetTimelineFollowersByUserSerial(ctx context.Context, userSerial string) ([]string, error) {
+	return callWithResilience(ctx, r, "GetTimelineFollowersByUserSerial", true, func() ([]string, error) {
+		return r.next.GetTimelineFollowersByUserSerial(ctx, userSerial)
+	})
+}
+
+func (r *resilientService) Follow(ctx context.Context, ownUserSerial, targetUserSerial string) (*FollowRequest, error) {
+	return callWithResilience(ctx, r, "Follow", false, func() (*FollowRequest, error) {
+		return r.next.Follow(ctx, ownUserSerial, targetUserSerial)
+	})
+}
+
+func (r *resilientService) Unfollow(ctx context.Context, ownUserSerial, targetUserSerial string) error {
+	_, err := callWithResilience(ctx, r, "Unfollow", false, func() (struct{}, error) {
+		return struct{}{}, r.next.Unfollow(ctx, ownUserSerial, targetUserSerial)
+	})
+	return err
+}
+
+func (r *resilientService) AddLikeToPostID(ctx context.Context, likerUserSerial, postID string) (*stream.Reaction, error) {
+	return callWithResilience(ctx, r, "AddLikeToPostID", false, func() (*stream.Reaction, error) {
+		return r.next.AddLikeToPostID(ctx, likerUserSerial, postID)
+	})
+}
+
+func (r *resilientService) RetrieveLikeDetailOnPostID(ctx context.Context, postID string, limit int) (*stream.FilterReactionResponse, error) {
+	return callWithResilience(ctx, r, "RetrieveLikeDetailOnPostID", true, func() (*stream.FilterReactionResponse, error) {
+		return r.next.RetrieveLikeDetailOnPostID(ctx, postID, limit)
+	})
+}
+
+func (r *resilientService) RetrieveLikeDetailOnPostIDWithPagination(ctx context.Context, postID, nextLikeID string, limit int) (*stream.FilterReactionResponse, error) {
+	return callWithResilience(ctx, r, "RetrieveLikeDetailOnPostIDWithPagination", true, func() (*stream.FilterReactionResponse, error) {
+		return r.next.RetrieveLikeDetailOnPostIDWithPagination(ctx, postID, nextLikeID, limit)
+	})
+}
+
+func (r *resilientService) RemoveLikeByReactionID(ctx context.Context, reactionID string) error {
+	_, err := callWithResilience(ctx, r, "RemoveLikeByReactionID", false, func() (struct{}, error) {
+		return struct{}{}, r.next.RemoveLikeByReactionID(ctx, reactionID)
+	})
+	return err
+}
+
+func (r *resilientService) BlockUser(ctx context.Context, ownUserSerial, targetUserSerial string) error {
+	_, err := callWithResilience(ctx, r, "BlockUser", false, func() (struct{}, error) {
+		return struct{}{}, r.next.BlockUser(ctx, ownUserSerial, targetUserSerial)
+	})
+	return err
+}
+
+func (r *resilientService) UnblockUser(ctx context.Context, ownUserSerial, targetUserSerial string) error {
+	_, err := callWithResilience(ctx, r, "UnblockUser", false, func() (struct{}, error) {
+		return struct{}{}, r.next.UnblockUser(ctx, ownUserSerial, targetUserSerial)
+	})
+	return err
+}
+
+func (r *resilientService) GetBlockedUsersByUserSerial(ctx context.Context, userSerial string) ([]string, error) {
+	return callWithResilience(ctx, r, "GetBlockedUsersByUserSerial", true, func() ([]string, error) {
+		return r.next.GetBlockedUsersByUserSerial(ctx, userSerial)
+	})
+}
+
+func (r *resilientService) MuteUser(ctx context.Context, ownUserSerial, targetUserSerial string) error {
+	_, err := callWithResilience(ctx, r, "MuteUser", false, func() (struct{}, error) {
+		return struct{}{}, r.next.MuteUser(ctx, ownUserSerial, targetUserSerial)
+	})
+	return err
+}
+
+func (r *resilientService) UnmuteUser(ctx context.Context, ownUserSerial, targetUserSerial string) error {
+	_, err := callWithResilience(ctx, r, "UnmuteUser", false, func() (struct{}, error) {
+		return struct{}{}, r.next.UnmuteUser(ctx, ownUserSerial, targetUserSerial)
+	})
+	return err
+}
+
+func (r *resilientService) GetMutedUsersByUserSerial(ctx context.Context, userSerial string) ([]string, error) {
+	return callWithResilience(ctx, r, "GetMutedUsersByUserSerial", true, func() ([]string, error) {
+		return r.next.GetMutedUsersByUserSerial(ctx, userSerial)
+	})
+}
+
+func (r *resilientService) SetPrivateAccount(ctx context.Context, userSerial string, private bool) error {
+	_, err := callWithResilience(ctx, r, "SetPrivateAccount", false, func() (struct{}, error) {
+		return struct{}{}, r.next.SetPrivateAccount(ctx, userSerial, private)
+	})
+	return err
+}
+
+func (r *resilientService) GetIncomingFollowRequests(ctx context.Context, userSerial string) ([]FollowRequest, error) {
+	return callWithResilience(ctx, r, "GetIncomingFollowRequests", true, func() ([]FollowRequest, error) {
+		return r.next.GetIncomingFollowRequests(ctx, userSerial)
+	})
+}
+
+func (r *resilientService) GetOutgoingFollowRequests(ctx context.Context, userSerial string) ([]FollowRequest, error) {
+	return callWithResilience(ctx, r, "GetOutgoingFollowRequests", true, func() ([]FollowRequest, error) {
+		return r.next.GetOutgoingFollowRequests(ctx, userSerial)
+	})
+}
+
+func (r *resilientService) ApproveFollowRequest(ctx context.Context, userSerial, requestID string) (*FollowRequest, error) {
+	return callWithResilience(ctx, r, "ApproveFollowRequest", false, func() (*FollowRequest, error) {
+		return r.next.ApproveFollowRequest(ctx, userSerial, requestID)
+	})
+}
+
+func (r *resilientService) RejectFollowRequest(ctx context.Context, userSerial, requestID string) (*FollowRequest, error) {
+	return callWithResilience(ctx, r, "RejectFollowRequest", false, func() (*FollowRequest, error) {
+		return r.next.RejectFollowRequest(ctx, userSerial, requestID)
+	})
+}
+
+func (r *resilientService) GetFollowSuggestionsByUserSerial(ctx context.Context, userSerial string, limit int) ([]FollowSuggestion, error) {
+	return callWithResilience(ctx, r, "GetFollowSuggestionsByUserSerial", true, func() ([]FollowSuggestion, error) {
+		return r.next.GetFollowSuggestionsByUserSerial(ctx, userSerial, limit)
+	})
+}
+
+func (r *resilientService) GetRelationship(ctx context.Context, userSerial, otherSerial string) (*Relationship, error) {
+	return callWithResilience(ctx, r, "GetRelationship", true, func() (*Relationship, error) {
+		return r.next.GetRelationship(ctx, userSerial, otherSerial)
+	})
+}
+
+func (r *resilientService) GetRelationships(ctx context.Context, userSerial string, otherSerials []string) ([]Relationship, error) {
+	return callWithResilience(ctx, r, "GetRelationships", true, func() ([]Relationship, error) {
+		return r.next.GetRelationships(ctx, userSerial, otherSerials)
+	})
+}
+
+func (r *resilientService) Ping(ctx context.Context) error {
+	_, err := callWithResilience(ctx, r, "Ping", true, func() (struct{}, error) {
+		return struct{}{}, r.next.Ping(ctx)
+	})
+	return err
+}
@@ -0,0 +1,228 @@
+package getstream
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	stream "gopkg.in/GetStream/stream-go2.v3"
+)
+
+func apiError(statusCode int) error {
+	return stream.APIError{StatusCode: statusCode}
+}
+
+func testPolicy() ResiliencePolicy {
+	return ResiliencePolicy{
+		MaxAttempts:      3,
+		InitialBackoff:   time.Millisecond,
+		MaxBackoff:       5 * time.Millisecond,
+		FailureThreshold: 100,
+		OpenTimeout:      time.Minute,
+	}
+}
+
+// newResilientFakeStream wraps the core Service over a fake Stream whose
+// requests time out after 50ms
+func newResilientFakeStream(t *testing.T, policy ResiliencePolicy) (Service, *fakeStream) {
+	fake := newFakeStream(t)
+	core := NewService(fake.client(t, 50*time.Millisecond), NewMemoryStore(), testTopology(), time.Minute, NewEvents())
+	return NewResilientService(core, policy), fake
+}
+
+func TestResilientServiceRetries(t *testing.T) {
+	stall := fault{delay: 200 * time.Millisecond}
+
+	tests := []struct {
+		name      string
+		call      func(svc Service) error
+		faults    []fault
+		wantCalls int
+		wantErr   bool
+	}{
+		{name: "read recovers from server errors", call: readTimeline, faults: []fault{{status: 500}, {status: 503}}, wantCalls: 3},
+		{name: "read gives up after max attempts", call: readTimeline, faults: []fault{{status: 500}, {status: 500}, {status: 500}, {status: 500}}, wantCalls: 3, wantErr: true},
+		{name: "read retries rate limits", call: readTimeline, faults: []fault{{status: http.StatusTooManyRequests}}, wantCalls: 2},
+		{name: "read retries stalled requests", call: readTimeline, faults: []fault{stall}, wantCalls: 2},
+		{name: "read doesn't retry client errors", call: readTimeline, faults: []fault{{status: 404}}, wantCalls: 1, wantErr: true},
+		{
+			name: "read doesn't retry domain errors",
+			call: func(svc Service) error {
+				_, err := svc.GetPostHistoryByPostID(context.Background(), "1", "missing")
+				return err
+			},
+			wantCalls: 1,
+			wantErr:   true,
+		},
+		{name: "write isn't retried", call: writePost, faults: []fault{{status: 500}}, wantCalls: 1, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, fake := newResilientFakeStream(t, testPolicy())
+			fake.fail(tt.faults...)
+
+			err := tt.call(svc)
+
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, want error %v", err, tt.wantErr)
+			}
+			if got := fake.callCount(); got != tt.wantCalls {
+				t.Errorf("calls = %d, want %d", got, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func readTimeline(svc Service) error {
+	_, err := svc.GetTimelineByUserSerial(context.Background(), "1")
+	return err
+}
+
+func writePost(svc Service) error {
+	_, err := svc.AddPostByUserSerial(context.Background(), "1", "hello", "text")
+	return err
+}
+
+func TestResilientServiceRetryStopsWithContext(t *testing.T) {
+	policy := testPolicy()
+	policy.InitialBackoff = time.Hour
+	policy.MaxBackoff = time.Hour
+	svc, fake := newResilientFakeStream(t, policy)
+	fake.fail(fault{status: 500}, fault{status: 500})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	_, err := svc.GetTimelineByUserSerial(ctx, "1")
+	if err == nil {
+		t.Fatal("expected the first error once the context is done")
+	}
+	if got := fake.callCount(); got != 1 {
+		t.Errorf("calls = %d, want 1", got)
+	}
+}
+
+func TestResilientServiceBackoff(t *testing.T) {
+	r := &resilientService{policy: ResiliencePolicy{
+		InitialBackoff: 100 * time.Millisecond,
+		MaxBackoff:     time.Second,
+	}}
+
+	tests := []struct {
+		name    string
+		attempt int
+		err     error
+		min     time.Duration
+		max     time.Duration
+	}{
+		{name: "first attempt is jittered within the initial backoff", attempt: 1, err: apiError(500), max: 100 * time.Millisecond},
+		{name: "window doubles per attempt", attempt: 3, err: apiError(500), max: 400 * time.Millisecond},
+		{name: "window is capped", attempt: 10, err: apiError(500), max: time.Second},
+		{
+			name:    "rate limit waits until the reset",
+			attempt: 1,
+			err:     stream.APIError{StatusCode: http.StatusTooManyRequests, Rate: &stream.Rate{Reset: time.Now().Add(3 * time.Second)}},
+			min:     time.Second,
+			max:     time.Second,
+		},
+		{
+			name:    "rate limit reset in the past falls back to jitter",
+			attempt: 1,
+			err:     stream.APIError{StatusCode: http.StatusTooManyRequests, Rate: &stream.Rate{Reset: time.Now().Add(-time.Minute)}},
+			max:     100 * time.Millisecond,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for i := 0; i < 50; i++ {
+				got := r.backoff(tt.attempt, tt.err)
+				if got < tt.min || got > tt.max {
+					t.Fatalf("backoff = %v, want within [%v, %v]", got, tt.min, tt.max)
+				}
+			}
+		})
+	}
+}
+
+func TestResilientServiceBreaker(t *testing.T) {
+	policy := testPolicy()
+	policy.FailureThreshold = 2
+	policy.OpenTimeout = 30 * time.Millisecond
+	svc, fake := newResilientFakeStream(t, policy)
+	fake.fail(fault{status: 500}, fault{delay: 200 * time.Millisecond})
+	ctx := context.Background()
+
+	// Writes aren't retried, each failure counts once
+	for i := 0; i < 2; i++ {
+		if err := writePost(svc); err == nil {
+			t.Fatalf("call %d: expected the injected fault", i)
+		}
+	}
+
+	// Open, calls are rejected without reaching Stream
+	if err := writePost(svc); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("err = %v, want ErrCircuitOpen", err)
+	}
+	if got := fake.callCount(); got != 2 {
+		t.Fatalf("calls = %d, want 2", got)
+	}
+
+	// Breakers are per operation
+	if _, err := svc.GetTimelineByUserSerial(ctx, "1"); err != nil {
+		t.Fatalf("other operation: %v", err)
+	}
+
+	// Half-open after the timeout, the successful probe closes it
+	time.Sleep(policy.OpenTimeout + 10*time.Millisecond)
+	if err := writePost(svc); err != nil {
+		t.Fatalf("probe: %v", err)
+	}
+	if err := writePost(svc); err != nil {
+		t.Fatalf("closed: %v", err)
+	}
+}
+
+func TestBreakerHalfOpen(t *testing.T) {
+	b := &breaker{failureThreshold: 1, openTimeout: 20 * time.Millisecond}
+
+	b.record(true)
+	if b.allow() {
+		t.Fatal("open breaker allowed a call")
+	}
+
+	time.Sleep(30 * time.Millisecond)
+	if !b.allow() {
+		t.Fatal("half-open breaker rejected the probe")
+	}
+	if b.allow() {
+		t.Fatal("half-open breaker allowed a second call next to the probe")
+	}
+
+	// A failed probe opens it again
+	b.record(true)
+	if b.allow() {
+		t.Fatal("breaker stayed closed after a failed probe")
+	}
+}
+
+func TestResilientServiceNonTransientErrorsLeaveBreaker(t *testing.T) {
+	policy := testPolicy()
+	policy.FailureThreshold = 2
+	svc, fake := newResilientFakeStream(t, policy)
+	fake.fail(fault{status: 500}, fault{status: 400}, fault{status: 404})
+
+	for i := 0; i < 3; i++ {
+		_ = writePost(svc)
+	}
+
+	// The errors in between neither reset nor counted towards the threshold
+	fake.fail(fault{status: 500})
+	_ = writePost(svc)
+	if err := writePost(svc); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("err = %v, want ErrCircuitOpen", err)
+	}
+}
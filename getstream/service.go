@@ -1,60 +1,126 @@
 package getstream
 
 import (
+	"context"
+	"errors"
+	"time"
+
 	stream "gopkg.in/GetStream/stream-go2.v3"
 )
 
+var (
+	ErrPostNotFound = errors.New("post not found")
+	ErrNotPostOwner = errors.New("post does not belong to this user")
+	ErrBlocked      = errors.New("one of the users has blocked the other")
+	ErrSameUser     = errors.New("users can't target themselves")
+)
+
 type service struct {
 	getstreamClient *stream.Client
+	store           Store
+	topology        Topology
+	suggestions     *suggestionCache
+	events          *Events
+	postLocks       *keyedMutex
 }
 
 type Service interface {
-	AddPostByUserSerial(userSerial, postContent, postType string) (*stream.AddActivityResponse, error)
-	GetPostByUserSerial(userSerial string) (*stream.FlatFeedResponse, error)
-	GetPostDetailByUserSerial(userSerial string) (*stream.EnrichedFlatFeedResponse, error)
-	DeletePostByPostID(userSerial, postID string) error
-	GetTimelineByUserSerial(userSerial string) (*stream.FlatFeedResponse, error)
-	GetDetailTimelineByUserSerial(userSerial string) (*stream.EnrichedFlatFeedResponse, error)
-	GetFeedFollowersByUserSerial(userSerial string) (*stream.FollowersResponse, error)
-	GetFollowedFeedsByUserSerial(userSerial string) (*stream.FollowingResponse, error)
-	Follow(ownUserSerial, targetUserSerial string) error
-	Unfollow(ownUserSerial, targetUserSerial string) error
-	AddLikeToPostID(likerUserSerial, postID string) (*stream.Reaction, error)
-	RetrieveLikeDetailOnPostID(postID string, limit int) (*stream.FilterReactionResponse, error)
-	RetrieveLikeDetailOnPostIDWithPagination(postID, nextLikeID string, limit int) (*stream.FilterReactionResponse, error)
-	RemoveLikeByReactionID(reactionID string) error
+	AddPostByUserSerial(ctx context.Context, userSerial, postContent, postType string) (*stream.AddActivityResponse, error)
+	AddScheduledPostByUserSerial(ctx context.Context, userSerial, scheduleID, postContent, postType string, publishAt time.Time) (*stream.AddActivityResponse, error)
+	GetPostByUserSerial(ctx context.Context, viewerUserSerial, userSerial string) (*stream.FlatFeedResponse, error)
+	GetPostDetailByUserSerial(ctx context.Context, viewerUserSerial, userSerial string) (*stream.EnrichedFlatFeedResponse, error)
+	EditPostByPostID(ctx context.Context, userSerial, postID, postContent, postType string) (*stream.UpdateActivityResponse, error)
+	GetPostHistoryByPostID(ctx context.Context, viewerUserSerial, postID string) ([]PostRevision, error)
+	DeletePostByPostID(ctx context.Context, userSerial, postID string) error
+	GetTimelineByUserSerial(ctx context.Context, userSerial string) (*stream.FlatFeedResponse, error)
+	GetDetailTimelineByUserSerial(ctx context.Context, userSerial string) (*stream.EnrichedFlatFeedResponse, error)
+	GetFeedFollowersByUserSerial(ctx context.Context, userSerial string) (*stream.FollowersResponse, error)
+	GetFollowedFeedsByUserSerial(ctx context.Context, userSerial string) (*stream.FollowingResponse, error)
+	GetTimelineFollowersByUserSerial(ctx context.Context, userSerial string) ([]string, error)
+	Follow(ctx context.Context, ownUserSerial, targetUserSerial string) (*FollowRequest, error)
+	Unfollow(ctx context.Context, ownUserSerial, targetUserSerial string) error
+	AddLikeToPostID(ctx context.Context, likerUserSerial, postID string) (*stream.Reaction, error)
+	RetrieveLikeDetailOnPostID(ctx context.Context, postID string, limit int) (*stream.FilterReactionResponse, error)
+	RetrieveLikeDetailOnPostIDWithPagination(ctx context.Context, postID, nextLikeID string, limit int) (*stream.FilterReactionResponse, error)
+	RemoveLikeByReactionID(ctx context.Context, reactionID string) error
+	BlockUser(ctx context.Context, ownUserSerial, targetUserSerial string) error
+	UnblockUser(ctx context.Context, ownUserSerial, targetUserSerial string) error
+	GetBlockedUsersByUserSerial(ctx context.Context, userSerial string) ([]string, error)
+	MuteUser(ctx context.Context, ownUserSerial, targetUserSerial string) error
+	UnmuteUser(ctx context.Context, ownUserSerial, targetUserSerial string) error
+	GetMutedUsersByUserSerial(ctx context.Context, userSerial string) ([]string, error)
+	SetPrivateAccount(ctx context.Context, userSerial string, private bool) error
+	GetIncomingFollowRequests(ctx context.Context, userSerial string) ([]FollowRequest, error)
+	GetOutgoingFollowRequests(ctx context.Context, userSerial string) ([]FollowRequest, error)
+	ApproveFollowRequest(ctx context.Context, userSerial, requestID string) (*FollowRequest, error)
+	RejectFollowRequest(ctx context.Context, userSerial, requestID string) (*FollowRequest, error)
+	GetFollowSuggestionsByUserSerial(ctx context.Context, userSerial string, limit int) ([]FollowSuggestion, error)
+	GetRelationship(ctx context.Context, userSerial, otherSerial string) (*Relationship, error)
+	GetRelationships(ctx context.Context, userSerial string, otherSerials []string) ([]Relationship, error)
+	Ping(ctx context.Context) error
 }
 
-func NewService(getstreamClient *stream.Client) Service {
+// NewService lays out feeds as described by topology, which must be valid,
+// and emits an Event to events after each change it makes
+func NewService(getstreamClient *stream.Client, store Store, topology Topology, suggestionCacheTTL time.Duration, events *Events) Service {
 	return &service{
 		getstreamClient: getstreamClient,
+		store:           store,
+		topology:        topology,
+		suggestions:     newSuggestionCache(suggestionCacheTTL),
+		events:          events,
+		postLocks:       newKeyedMutex(),
 	}
 }
 
-func (s *service) AddPostByUserSerial(userSerial, postContent, postType string) (*stream.AddActivityResponse, error) {
+func (s *service) AddPostByUserSerial(ctx context.Context, userSerial, postContent, postType string) (*stream.AddActivityResponse, error) {
+	return s.addPost(ctx, userSerial, postContent, postType, "", time.Time{})
+}
+
+func (s *service) AddScheduledPostByUserSerial(ctx context.Context, userSerial, scheduleID, postContent, postType string, publishAt time.Time) (*stream.AddActivityResponse, error) {
+	// Stream keeps a single activity per foreign ID and time, publishing the
+	// same schedule again doesn't duplicate the post
+	return s.addPost(ctx, userSerial, postContent, postType, "schedule:"+scheduleID, publishAt.UTC())
+}
+
+// addPost adds a post activity to the user feed, at the current time unless
+// at is set
+func (s *service) addPost(ctx context.Context, userSerial, postContent, postType, foreignID string, at time.Time) (*stream.AddActivityResponse, error) {
 	// Get user feed object
-	userFlatFeed, err := s.getstreamClient.FlatFeed("user", userSerial)
+	userFlatFeed, err := s.getstreamClient.FlatFeed(s.topology.PostGroup, userSerial)
 	if err != nil {
 		return nil, err
 	}
 
 	// Add post activity to the feed
 	resp, err := userFlatFeed.AddActivity(stream.Activity{
-		Actor:  userFlatFeed.ID(),
-		Verb:   "post",
-		Object: "1",
+		Actor:     userFlatFeed.ID(),
+		Verb:      s.topology.PostVerb,
+		Object:    "1",
+		ForeignID: foreignID,
+		Time:      stream.Time{Time: at},
 		Extra: map[string]interface{}{
 			"post":     postContent,
 			"postType": postType,
 		},
 	})
+	if err != nil {
+		return nil, err
+	}
 
-	return resp, err
+	s.events.emit(ctx, Event{Type: EventPostCreated, UserSerial: userSerial, PostID: resp.ID, Activity: &resp.Activity})
+	return resp, nil
 }
 
-func (s *service) GetPostByUserSerial(userSerial string) (*stream.FlatFeedResponse, error) {
+func (s *service) GetPostByUserSerial(ctx context.Context, viewerUserSerial, userSerial string) (*stream.FlatFeedResponse, error) {
+	// Posts of private users are only visible to their followers
+	err := s.checkCanViewPosts(viewerUserSerial, userSerial)
+	if err != nil {
+		return nil, err
+	}
+
 	// Get user feed object
-	userFlatFeed, err := s.getstreamClient.FlatFeed("user", userSerial)
+	userFlatFeed, err := s.getstreamClient.FlatFeed(s.topology.PostGroup, userSerial)
 	if err != nil {
 		return nil, err
 	}
@@ -63,9 +129,15 @@ func (s *service) GetPostByUserSerial(userSerial string) (*stream.FlatFeedRespon
 	return userFlatFeed.GetActivities()
 }
 
-func (s *service) GetPostDetailByUserSerial(userSerial string) (*stream.EnrichedFlatFeedResponse, error) {
+func (s *service) GetPostDetailByUserSerial(ctx context.Context, viewerUserSerial, userSerial string) (*stream.EnrichedFlatFeedResponse, error) {
+	// Posts of private users are only visible to their followers
+	err := s.checkCanViewPosts(viewerUserSerial, userSerial)
+	if err != nil {
+		return nil, err
+	}
+
 	// Get user feed object
-	userFlatFeed, err := s.getstreamClient.FlatFeed("user", userSerial)
+	userFlatFeed, err := s.getstreamClient.FlatFeed(s.topology.PostGroup, userSerial)
 	if err != nil {
 		return nil, err
 	}
@@ -80,144 +152,285 @@ func (s *service) GetPostDetailByUserSerial(userSerial string) (*stream.Enriched
 	return userFlatFeed.GetEnrichedActivities(opts...)
 }
 
-func (s *service) DeletePostByPostID(userSerial, postID string) error {
-	// Get user feed object
-	userFlatFeed, err := s.getstreamClient.FlatFeed("user", userSerial)
+func (s *service) EditPostByPostID(ctx context.Context, userSerial, postID, postContent, postType string) (*stream.UpdateActivityResponse, error) {
+	// One edit of a post at a time, so each revision keeps what the previous
+	// edit wrote
+	unlock := s.postLocks.lock(postID)
+	defer unlock()
+
+	// Get the post being edited
+	post, err := s.getPostOwnedBy(userSerial, postID)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	// Remove `post` activity specified by `activityID`
-	return userFlatFeed.RemoveActivityByID(postID)
+	// Replace `post` and `postType` on the activity
+	resp, err := s.getstreamClient.UpdateActivityByID(postID, map[string]interface{}{
+		"post":     postContent,
+		"postType": postType,
+	}, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// Keep what the post looked like before this edit
+	previousPost, _ := post.Extra["post"].(string)
+	previousPostType, _ := post.Extra["postType"].(string)
+	err = s.store.AddPostRevision(PostRevision{
+		PostID:   postID,
+		Post:     previousPost,
+		PostType: previousPostType,
+		Editor:   userSerial,
+		EditedAt: time.Now().UTC(),
+	})
+
+	return resp, err
 }
 
-func (s *service) GetTimelineByUserSerial(userSerial string) (*stream.FlatFeedResponse, error) {
-	// Get timeline feed object
-	userFlatFeed, err := s.getstreamClient.FlatFeed("timeline", userSerial)
+func (s *service) GetPostHistoryByPostID(ctx context.Context, viewerUserSerial, postID string) ([]PostRevision, error) {
+	// Get the post to find out who posted it
+	resp, err := s.getstreamClient.GetActivitiesByID(postID)
 	if err != nil {
 		return nil, err
 	}
+	if len(resp.Results) == 0 {
+		return nil, ErrPostNotFound
+	}
 
-	// Get activities on `timeline` feed
-	return userFlatFeed.GetActivities()
+	// History of posts by private users is only visible to their followers
+	err = s.checkCanViewPosts(viewerUserSerial, userSerialFromActor(resp.Results[0].Actor))
+	if err != nil {
+		return nil, err
+	}
+
+	// Revisions are stored oldest first
+	return s.store.GetPostRevisions(postID)
 }
 
-func (s *service) GetDetailTimelineByUserSerial(userSerial string) (*stream.EnrichedFlatFeedResponse, error) {
-	// Get timeline feed object
-	userFlatFeed, err := s.getstreamClient.FlatFeed("timeline", userSerial)
+// getPostOwnedBy fetches the `post` activity and makes sure it was posted by userSerial
+func (s *service) getPostOwnedBy(userSerial, postID string) (*stream.Activity, error) {
+	resp, err := s.getstreamClient.GetActivitiesByID(postID)
 	if err != nil {
 		return nil, err
 	}
+	if len(resp.Results) == 0 {
+		return nil, ErrPostNotFound
+	}
 
-	// Add enriched option
-	opts := []stream.GetActivitiesOption{
-		stream.WithEnrichRecentReactions(),
-		stream.WithEnrichReactionCounts(),
+	// Get user feed object to compare its ID with the activity actor
+	userFlatFeed, err := s.getstreamClient.FlatFeed(s.topology.PostGroup, userSerial)
+	if err != nil {
+		return nil, err
 	}
 
-	// Get `enriched` activities on `timeline` feed
-	return userFlatFeed.GetEnrichedActivities(opts...)
+	post := resp.Results[0]
+	if post.Actor != userFlatFeed.ID() {
+		return nil, ErrNotPostOwner
+	}
+
+	return &post, nil
 }
 
-func (s *service) Follow(ownUserSerial, targetUserSerial string) error {
-	err := s.followTimelineFeed(ownUserSerial, targetUserSerial)
+func (s *service) DeletePostByPostID(ctx context.Context, userSerial, postID string) error {
+	// Make sure the post exists and belongs to the user
+	if _, err := s.getPostOwnedBy(userSerial, postID); err != nil {
+		return err
+	}
+
+	// Get user feed object
+	userFlatFeed, err := s.getstreamClient.FlatFeed(s.topology.PostGroup, userSerial)
 	if err != nil {
 		return err
 	}
 
-	err = s.followUserFeed(ownUserSerial, targetUserSerial)
+	// Remove `post` activity specified by `activityID`
+	err = userFlatFeed.RemoveActivityByID(postID)
 	if err != nil {
 		return err
 	}
+	s.events.emit(ctx, Event{Type: EventPostDeleted, UserSerial: userSerial, PostID: postID})
 
-	return nil
+	// Remove reactions left on the deleted post
+	return s.removeReactionsByPostID(postID)
 }
 
-func (s *service) followTimelineFeed(ownUserSerial, targetUserSerial string) error {
+func (s *service) removeReactionsByPostID(postID string) error {
+	const pageSize = 25
+
+	filterAttribute := stream.ByActivityID(postID)
+	opts := []stream.FilterReactionsOption{stream.WithLimit(pageSize)}
+	for {
+		resp, err := s.getstreamClient.Reactions().Filter(filterAttribute, opts...)
+		if err != nil {
+			return err
+		}
+
+		for _, reaction := range resp.Results {
+			err := s.getstreamClient.Reactions().Delete(reaction.ID)
+			if err != nil {
+				return err
+			}
+		}
+
+		if len(resp.Results) < pageSize {
+			return nil
+		}
+
+		// Continue after the last reaction of this page
+		lastID := resp.Results[len(resp.Results)-1].ID
+		opts = []stream.FilterReactionsOption{stream.WithLimit(pageSize), stream.WithIDLT(lastID)}
+	}
+}
+
+func (s *service) GetTimelineByUserSerial(ctx context.Context, userSerial string) (*stream.FlatFeedResponse, error) {
 	// Get timeline feed object
-	ownUserFlatFeed, err := s.getstreamClient.FlatFeed("timeline", ownUserSerial)
+	userFlatFeed, err := s.getstreamClient.FlatFeed(s.topology.TimelineGroup, userSerial)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	// Get user feed object
-	targetUserFlatFeed, err := s.getstreamClient.FlatFeed("user", targetUserSerial)
+	// Get activities on `timeline` feed
+	resp, err := userFlatFeed.GetActivities()
 	if err != nil {
-		return err
+		return nil, err
+	}
+
+	// Hide activities of muted users
+	mutedActors, err := s.mutedActors(userSerial)
+	if err != nil {
+		return nil, err
+	}
+	activities := resp.Results[:0]
+	for _, activity := range resp.Results {
+		if !mutedActors[activity.Actor] {
+			activities = append(activities, activity)
+		}
 	}
+	resp.Results = activities
 
-	// `timeline` of `ownUser` will be filled by all activities of `targetUser`
-	return ownUserFlatFeed.Follow(targetUserFlatFeed)
+	return resp, nil
 }
 
-func (s *service) followUserFeed(ownUserSerial, targetUserSerial string) error {
+func (s *service) GetDetailTimelineByUserSerial(ctx context.Context, userSerial string) (*stream.EnrichedFlatFeedResponse, error) {
 	// Get timeline feed object
-	ownUserFlatFeed, err := s.getstreamClient.FlatFeed("user", ownUserSerial)
+	userFlatFeed, err := s.getstreamClient.FlatFeed(s.topology.TimelineGroup, userSerial)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	// Get user feed object
-	targetUserFlatFeed, err := s.getstreamClient.FlatFeed("user", targetUserSerial)
+	// Add enriched option
+	opts := []stream.GetActivitiesOption{
+		stream.WithEnrichRecentReactions(),
+		stream.WithEnrichReactionCounts(),
+	}
+
+	// Get `enriched` activities on `timeline` feed
+	resp, err := userFlatFeed.GetEnrichedActivities(opts...)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	// `timeline` of `ownUser` will be filled by all activities of `targetUser`
-	return ownUserFlatFeed.Follow(targetUserFlatFeed)
+	// Hide activities of muted users
+	mutedActors, err := s.mutedActors(userSerial)
+	if err != nil {
+		return nil, err
+	}
+	activities := resp.Results[:0]
+	for _, activity := range resp.Results {
+		if !mutedActors[activity.Actor.ID] {
+			activities = append(activities, activity)
+		}
+	}
+	resp.Results = activities
+
+	return resp, nil
 }
 
-func (s *service) Unfollow(ownUserSerial, targetUserSerial string) error {
-	err := s.unfollowTimelineFeed(ownUserSerial, targetUserSerial)
+func (s *service) Follow(ctx context.Context, ownUserSerial, targetUserSerial string) (*FollowRequest, error) {
+	err := s.checkNotBlocked(ownUserSerial, targetUserSerial)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	err = s.unfollowUserFeed(ownUserSerial, targetUserSerial)
+	// Following a private user needs their approval first
+	private, err := s.store.IsPrivate(targetUserSerial)
 	if err != nil {
-		return err
+		return nil, err
+	}
+	if private {
+		return s.requestFollow(ctx, ownUserSerial, targetUserSerial)
+	}
+
+	return nil, s.follow(ctx, ownUserSerial, targetUserSerial)
+}
+
+func (s *service) follow(ctx context.Context, ownUserSerial, targetUserSerial string) error {
+	// The follow graph is changing, suggestions of both users are stale
+	s.suggestions.invalidate(ownUserSerial, targetUserSerial)
+
+	for _, rule := range s.topology.FollowRules {
+		err := s.followFeed(rule, ownUserSerial, targetUserSerial)
+		if err != nil {
+			return err
+		}
 	}
 
+	s.events.emit(ctx, Event{Type: EventFollowed, UserSerial: ownUserSerial, TargetSerial: targetUserSerial})
 	return nil
 }
 
-func (s *service) unfollowTimelineFeed(ownUserSerial, targetUserSerial string) error {
-	// Get timeline feed object
-	ownUserFlatFeed, err := s.getstreamClient.FlatFeed("timeline", ownUserSerial)
+func (s *service) followFeed(rule FollowRule, ownUserSerial, targetUserSerial string) error {
+	// Get the feed of `ownUser` doing the following
+	ownUserFeed, err := s.feed(rule.Follower, ownUserSerial)
 	if err != nil {
 		return err
 	}
 
-	// Get user feed object
-	targetUserFlatFeed, err := s.getstreamClient.FlatFeed("user", targetUserSerial)
+	// Get the followed feed of `targetUser`
+	targetUserFlatFeed, err := s.getstreamClient.FlatFeed(rule.Followed, targetUserSerial)
 	if err != nil {
 		return err
 	}
 
-	// `timeline` of `ownUser` will no longer be filled by all activities of `targetUser`
-	return ownUserFlatFeed.Unfollow(targetUserFlatFeed)
+	// The feed of `ownUser` will be filled by all activities of `targetUser`
+	return ownUserFeed.Follow(targetUserFlatFeed)
 }
 
-func (s *service) unfollowUserFeed(ownUserSerial, targetUserSerial string) error {
-	// Get timeline feed object
-	ownUserFlatFeed, err := s.getstreamClient.FlatFeed("user", ownUserSerial)
+func (s *service) Unfollow(ctx context.Context, ownUserSerial, targetUserSerial string) error {
+	// The follow graph is changing, suggestions of both users are stale
+	s.suggestions.invalidate(ownUserSerial, targetUserSerial)
+
+	for _, rule := range s.topology.FollowRules {
+		err := s.unfollowFeed(rule, ownUserSerial, targetUserSerial)
+		if err != nil {
+			return err
+		}
+	}
+
+	s.events.emit(ctx, Event{Type: EventUnfollowed, UserSerial: ownUserSerial, TargetSerial: targetUserSerial})
+	return nil
+}
+
+func (s *service) unfollowFeed(rule FollowRule, ownUserSerial, targetUserSerial string) error {
+	// Get the feed of `ownUser` doing the following
+	ownUserFeed, err := s.feed(rule.Follower, ownUserSerial)
 	if err != nil {
 		return err
 	}
 
-	// Get user feed object
-	targetUserFlatFeed, err := s.getstreamClient.FlatFeed("user", targetUserSerial)
+	// Get the followed feed of `targetUser`
+	targetUserFlatFeed, err := s.getstreamClient.FlatFeed(rule.Followed, targetUserSerial)
 	if err != nil {
 		return err
 	}
 
-	// `timeline` of `ownUser` will no longer be filled by all activities of `targetUser`
-	return ownUserFlatFeed.Unfollow(targetUserFlatFeed)
+	// The feed of `ownUser` will no longer be filled by all activities of `targetUser`
+	return ownUserFeed.Unfollow(targetUserFlatFeed)
 }
 
-func (s *service) GetFeedFollowersByUserSerial(userSerial string) (*stream.FollowersResponse, error) {
+func (s *service) GetFeedFollowersByUserSerial(ctx context.Context, userSerial string) (*stream.FollowersResponse, error) {
 	// Get user feed object
-	userFlatFeed, err := s.getstreamClient.FlatFeed("user", userSerial)
+	userFlatFeed, err := s.getstreamClient.FlatFeed(s.topology.PostGroup, userSerial)
 	if err != nil {
 		return nil, err
 	}
@@ -225,17 +438,33 @@ func (s *service) GetFeedFollowersByUserSerial(userSerial string) (*stream.Follo
 	return userFlatFeed.GetFollowers(stream.WithFollowersOffset(0), stream.WithFollowersLimit(10))
 }
 
-func (s *service) GetFollowedFeedsByUserSerial(userSerial string) (*stream.FollowingResponse, error) {
-	// Get user feed object
-	userFlatFeed, err := s.getstreamClient.FlatFeed("user", userSerial)
+func (s *service) GetFollowedFeedsByUserSerial(ctx context.Context, userSerial string) (*stream.FollowingResponse, error) {
+	// Get timeline feed object, the only follower feed every topology has
+	timelineFlatFeed, err := s.getstreamClient.FlatFeed(s.topology.TimelineGroup, userSerial)
 	if err != nil {
 		return nil, err
 	}
-	// Retrieve last 10 feeds followed by user_feed_1
-	return userFlatFeed.GetFollowing(stream.WithFollowingOffset(0), stream.WithFollowingLimit(10))
+	// Retrieve last 10 feeds followed by the timeline
+	return timelineFlatFeed.GetFollowing(stream.WithFollowingOffset(0), stream.WithFollowingLimit(10))
 }
 
-func (s *service) AddLikeToPostID(likerUserSerial, postID string) (*stream.Reaction, error) {
+func (s *service) AddLikeToPostID(ctx context.Context, likerUserSerial, postID string) (*stream.Reaction, error) {
+	// Get the liked post to find out who posted it
+	resp, err := s.getstreamClient.GetActivitiesByID(postID)
+	if err != nil {
+		return nil, err
+	}
+	if len(resp.Results) == 0 {
+		return nil, ErrPostNotFound
+	}
+
+	// Users can't like posts of someone they blocked or who blocked them
+	authorUserSerial := userSerialFromActor(resp.Results[0].Actor)
+	err = s.checkNotBlocked(likerUserSerial, authorUserSerial)
+	if err != nil {
+		return nil, err
+	}
+
 	// Create a new `like` reaction
 	r := stream.AddReactionRequestObject{
 		Kind:       "like",
@@ -244,16 +473,22 @@ func (s *service) AddLikeToPostID(likerUserSerial, postID string) (*stream.React
 	}
 
 	// Add the reaction to stream
-	return s.getstreamClient.Reactions().Add(r)
+	reaction, err := s.getstreamClient.Reactions().Add(r)
+	if err != nil {
+		return nil, err
+	}
+
+	s.events.emit(ctx, Event{Type: EventLikeAdded, UserSerial: likerUserSerial, TargetSerial: authorUserSerial, PostID: postID, ReactionID: reaction.ID})
+	return reaction, nil
 }
 
-func (s *service) RetrieveLikeDetailOnPostID(postID string, limit int) (*stream.FilterReactionResponse, error) {
+func (s *service) RetrieveLikeDetailOnPostID(ctx context.Context, postID string, limit int) (*stream.FilterReactionResponse, error) {
 	// Retrieve detail likes activity on selected postID
 	return s.getstreamClient.
 		Reactions().Filter(stream.ByActivityID(postID).ByKind("like"), stream.WithLimit(limit))
 }
 
-func (s *service) RetrieveLikeDetailOnPostIDWithPagination(postID, nextLikeID string, limit int) (*stream.FilterReactionResponse, error) {
+func (s *service) RetrieveLikeDetailOnPostIDWithPagination(ctx context.Context, postID, nextLikeID string, limit int) (*stream.FilterReactionResponse, error) {
 	// Retrieve the next {limit} likes using the id_lt param
 	filterAttribute := stream.ByActivityID(postID).ByKind("like")
 	limitation := stream.WithLimit(limit)
@@ -262,7 +497,39 @@ func (s *service) RetrieveLikeDetailOnPostIDWithPagination(postID, nextLikeID st
 	return s.getstreamClient.Reactions().Filter(filterAttribute, limitation, pagination)
 }
 
-func (s *service) RemoveLikeByReactionID(reactionID string) error {
+func (s *service) RemoveLikeByReactionID(ctx context.Context, reactionID string) error {
+	// Get the reaction and its post first, listeners need to know which post
+	// it was on and who posted it
+	reaction, err := s.getstreamClient.Reactions().Get(reactionID)
+	if err != nil {
+		return err
+	}
+	resp, err := s.getstreamClient.GetActivitiesByID(reaction.ActivityID)
+	if err != nil {
+		return err
+	}
+	authorUserSerial := ""
+	if len(resp.Results) > 0 {
+		authorUserSerial = userSerialFromActor(resp.Results[0].Actor)
+	}
+
 	// Delete reaction by `reactionID`
-	return s.getstreamClient.Reactions().Delete(reactionID)
+	err = s.getstreamClient.Reactions().Delete(reactionID)
+	if err != nil {
+		return err
+	}
+
+	s.events.emit(ctx, Event{Type: EventLikeRemoved, UserSerial: reaction.UserID, TargetSerial: authorUserSerial, PostID: reaction.ActivityID, ReactionID: reactionID})
+	return nil
+}
+
+func (s *service) Ping(ctx context.Context) error {
+	// Get a feed nobody posts to, reading it is the cheapest authenticated call
+	healthFlatFeed, err := s.getstreamClient.FlatFeed(s.topology.PostGroup, "healthcheck")
+	if err != nil {
+		return err
+	}
+
+	_, err = healthFlatFeed.GetActivities(stream.WithActivitiesLimit(1))
+	return err
 }
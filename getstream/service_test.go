@@ -0,0 +1,94 @@
+package getstream
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestAddScheduledPostIsIdempotent(t *testing.T) {
+	ctx := context.Background()
+	svc, fake := newFakeStreamService(t, NewMemoryStore())
+	publishAt := time.Date(2026, 10, 19, 9, 30, 0, 0, time.UTC)
+
+	first, err := svc.AddScheduledPostByUserSerial(ctx, "1", "schedule-1", "hello", "text", publishAt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.ForeignID != "schedule:schedule-1" || !first.Time.Equal(publishAt) {
+		t.Errorf("foreign ID %q at %v, want schedule:schedule-1 at %v", first.ForeignID, first.Time, publishAt)
+	}
+
+	// Publishing again after a lost update keeps a single post
+	again, err := svc.AddScheduledPostByUserSerial(ctx, "1", "schedule-1", "hello", "text", publishAt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again.ID != first.ID {
+		t.Errorf("published again as %s, want %s", again.ID, first.ID)
+	}
+
+	// Other schedules and plain posts are new activities
+	if _, err := svc.AddScheduledPostByUserSerial(ctx, "1", "schedule-2", "hello", "text", publishAt); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.AddPostByUserSerial(ctx, "1", "hello", "text"); err != nil {
+		t.Fatal(err)
+	}
+	if ids := fake.activityIDs(); len(ids) != 3 {
+		t.Errorf("activities = %v, want 3", ids)
+	}
+}
+
+func TestDeletePostByPostID(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name       string
+		userSerial string
+		// postID is the post of user 1 when empty
+		postID      string
+		wantErr     error
+		wantDeleted bool
+	}{
+		{name: "author", userSerial: "1", wantDeleted: true},
+		{name: "another user", userSerial: "2", wantErr: ErrNotPostOwner},
+		{name: "unknown post", userSerial: "1", postID: "activity-404", wantErr: ErrPostNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, fake := newFakeStreamService(t, NewMemoryStore())
+			postID := fake.post("user:1", "user:1")
+			// More likes than a page of reactions
+			for i := 0; i < 30; i++ {
+				if _, err := svc.AddLikeToPostID(ctx, fmt.Sprint(100+i), postID); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if tt.postID == "" {
+				tt.postID = postID
+			}
+
+			err := svc.DeletePostByPostID(ctx, tt.userSerial, tt.postID)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+
+			deleted := !slices.Contains(fake.activityIDs(), postID)
+			if deleted != tt.wantDeleted {
+				t.Errorf("deleted = %v, want %v", deleted, tt.wantDeleted)
+			}
+			wantReactions := 30
+			if tt.wantDeleted {
+				wantReactions = 0
+			}
+			if count := fake.reactionCount(); count != wantReactions {
+				t.Errorf("reactions = %d, want %d", count, wantReactions)
+			}
+		})
+	}
+}
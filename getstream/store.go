@@ -0,0 +1,243 @@
+package getstream
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+var ErrFollowRequestNotFound = errors.New("follow request not found")
+
+// Store keeps the state this service tracks alongside Stream
+type Store interface {
+	AddPostRevision(revision PostRevision) error
+	GetPostRevisions(postID string) ([]PostRevision, error)
+
+	AddBlock(userSerial, targetUserSerial string) error
+	RemoveBlock(userSerial, targetUserSerial string) error
+	IsBlocked(userSerial, targetUserSerial string) (bool, error)
+	GetBlockedUserSerials(userSerial string) ([]string, error)
+
+	AddMute(userSerial, targetUserSerial string) error
+	RemoveMute(userSerial, targetUserSerial string) error
+	GetMutedUserSerials(userSerial string) ([]string, error)
+
+	SetPrivate(userSerial string, private bool) error
+	IsPrivate(userSerial string) (bool, error)
+
+	SaveFollowRequest(request FollowRequest) error
+	GetFollowRequest(requestID string) (FollowRequest, error)
+	// GetFollowRequests returns the requests matching both serials, an empty
+	// serial matches any user
+	GetFollowRequests(ownUserSerial, targetUserSerial string, status FollowRequestStatus) ([]FollowRequest, error)
+}
+
+// NewStore builds the store selected by kind, either `memory` or `file`
+func NewStore(kind, filePath string) (Store, error) {
+	switch kind {
+	case "", "memory":
+		return NewMemoryStore(), nil
+	case "file":
+		return NewFileStore(filePath)
+	default:
+		return nil, fmt.Errorf("unknown getstream store %q", kind)
+	}
+}
+
+type memoryStore struct {
+	mu        sync.RWMutex
+	revisions map[string][]PostRevision
+	blocks    userSets
+	mutes     userSets
+	private   map[string]bool
+	requests  map[string]FollowRequest
+}
+
+// NewMemoryStore returns a Store that keeps everything in memory only
+func NewMemoryStore() Store {
+	return newMemoryStore()
+}
+
+func newMemoryStore() *memoryStore {
+	return &memoryStore{
+		revisions: map[string][]PostRevision{},
+		blocks:    userSets{},
+		mutes:     userSets{},
+		private:   map[string]bool{},
+		requests:  map[string]FollowRequest{},
+	}
+}
+
+func (m *memoryStore) AddPostRevision(revision PostRevision) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.revisions[revision.PostID] = append(m.revisions[revision.PostID], revision)
+	return nil
+}
+
+func (m *memoryStore) GetPostRevisions(postID string) ([]PostRevision, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	// Copy so callers can't modify the stored history
+	revisions := make([]PostRevision, len(m.revisions[postID]))
+	copy(revisions, m.revisions[postID])
+	return revisions, nil
+}
+
+func (m *memoryStore) AddBlock(userSerial, targetUserSerial string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.blocks.add(userSerial, targetUserSerial)
+	return nil
+}
+
+func (m *memoryStore) RemoveBlock(userSerial, targetUserSerial string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.blocks.remove(userSerial, targetUserSerial)
+	return nil
+}
+
+func (m *memoryStore) IsBlocked(userSerial, targetUserSerial string) (bool, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return m.blocks.has(userSerial, targetUserSerial), nil
+}
+
+func (m *memoryStore) GetBlockedUserSerials(userSerial string) ([]string, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return m.blocks.list(userSerial), nil
+}
+
+func (m *memoryStore) AddMute(userSerial, targetUserSerial string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.mutes.add(userSerial, targetUserSerial)
+	return nil
+}
+
+func (m *memoryStore) RemoveMute(userSerial, targetUserSerial string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.mutes.remove(userSerial, targetUserSerial)
+	return nil
+}
+
+func (m *memoryStore) GetMutedUserSerials(userSerial string) ([]string, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return m.mutes.list(userSerial), nil
+}
+
+func (m *memoryStore) SetPrivate(userSerial string, private bool) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if private {
+		m.private[userSerial] = true
+	} else {
+		delete(m.private, userSerial)
+	}
+	return nil
+}
+
+func (m *memoryStore) IsPrivate(userSerial string) (bool, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return m.private[userSerial], nil
+}
+
+func (m *memoryStore) SaveFollowRequest(request FollowRequest) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.requests[request.ID] = request
+	return nil
+}
+
+func (m *memoryStore) GetFollowRequest(requestID string) (FollowRequest, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	request, ok := m.requests[requestID]
+	if !ok {
+		return FollowRequest{}, ErrFollowRequestNotFound
+	}
+	return request, nil
+}
+
+func (m *memoryStore) GetFollowRequests(ownUserSerial, targetUserSerial string, status FollowRequestStatus) ([]FollowRequest, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	requests := []FollowRequest{}
+	for _, request := range m.requests {
+		if ownUserSerial != "" && request.OwnUserSerial != ownUserSerial {
+			continue
+		}
+		if targetUserSerial != "" && request.TargetUserSerial != targetUserSerial {
+			continue
+		}
+		if request.Status != status {
+			continue
+		}
+		requests = append(requests, request)
+	}
+
+	// Oldest request first
+	sort.Slice(requests, func(i, j int) bool {
+		return requests[i].CreatedAt.Before(requests[j].CreatedAt)
+	})
+	return requests, nil
+}
+
+// userSets holds, per user, a set of other users they have a relation to
+type userSets map[string]map[string]struct{}
+
+func (u userSets) add(userSerial, targetUserSerial string) {
+	if u[userSerial] == nil {
+		u[userSerial] = map[string]struct{}{}
+	}
+	u[userSerial][targetUserSerial] = struct{}{}
+}
+
+func (u userSets) remove(userSerial, targetUserSerial string) {
+	delete(u[userSerial], targetUserSerial)
+}
+
+func (u userSets) has(userSerial, targetUserSerial string) bool {
+	_, ok := u[userSerial][targetUserSerial]
+	return ok
+}
+
+func (u userSets) list(userSerial string) []string {
+	userSerials := []string{}
+	for targetUserSerial := range u[userSerial] {
+		userSerials = append(userSerials, targetUserSerial)
+	}
+	sort.Strings(userSerials)
+	return userSerials
+}
+
+// lists returns the set of every user as a sorted list
+func (u userSets) lists() map[string][]string {
+	lists := map[string][]string{}
+	for userSerial := range u {
+		if list := u.list(userSerial); len(list) > 0 {
+			lists[userSerial] = list
+		}
+	}
+	return lists
+}
@@ -0,0 +1,243 @@
+package getstream
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	stream "gopkg.in/GetStream/stream-go2.v3"
+)
+
+const (
+	// How many follow edges are read per user while walking the graph
+	suggestionGraphLimit = 100
+	// How many top candidates get their latest activity looked up
+	suggestionCandidateLimit = 50
+)
+
+func (s *service) GetFollowSuggestionsByUserSerial(ctx context.Context, userSerial string, limit int) ([]FollowSuggestion, error) {
+	suggestions, ok := s.suggestions.get(userSerial)
+	if !ok {
+		var err error
+		suggestions, err = s.computeFollowSuggestions(userSerial)
+		if err != nil {
+			return nil, err
+		}
+		s.suggestions.set(userSerial, suggestions)
+	}
+
+	if len(suggestions) > limit {
+		suggestions = suggestions[:limit]
+	}
+	return suggestions, nil
+}
+
+func (s *service) computeFollowSuggestions(userSerial string) ([]FollowSuggestion, error) {
+	followed, err := s.followedUserSerials(userSerial)
+	if err != nil {
+		return nil, err
+	}
+	followers, err := s.followerUserSerials(userSerial)
+	if err != nil {
+		return nil, err
+	}
+
+	alreadyFollowed := map[string]bool{userSerial: true}
+	for _, followedUserSerial := range followed {
+		alreadyFollowed[followedUserSerial] = true
+	}
+
+	// Friends of friends, counting how many followed users follow each of them
+	candidates := map[string]*FollowSuggestion{}
+	candidate := func(candidateUserSerial string) *FollowSuggestion {
+		if candidates[candidateUserSerial] == nil {
+			candidates[candidateUserSerial] = &FollowSuggestion{UserSerial: candidateUserSerial}
+		}
+		return candidates[candidateUserSerial]
+	}
+	for _, followedUserSerial := range followed {
+		friendsOfFriend, err := s.followedUserSerials(followedUserSerial)
+		if err != nil {
+			return nil, err
+		}
+		for _, friendOfFriend := range friendsOfFriend {
+			if !alreadyFollowed[friendOfFriend] {
+				candidate(friendOfFriend).MutualCount++
+			}
+		}
+	}
+
+	// Followers not followed back are candidates as well
+	for _, followerUserSerial := range followers {
+		if !alreadyFollowed[followerUserSerial] {
+			candidate(followerUserSerial).FollowsYou = true
+		}
+	}
+
+	suggestions := []FollowSuggestion{}
+	for _, suggestion := range candidates {
+		err := s.checkNotBlocked(userSerial, suggestion.UserSerial)
+		if errors.Is(err, ErrBlocked) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		suggestions = append(suggestions, *suggestion)
+	}
+
+	// Rank by graph signals first, then look up recent activity of the best ones only
+	sortFollowSuggestions(suggestions)
+	if len(suggestions) > suggestionCandidateLimit {
+		suggestions = suggestions[:suggestionCandidateLimit]
+	}
+	for i := range suggestions {
+		lastActivityAt, err := s.lastActivityAt(suggestions[i].UserSerial)
+		if err != nil {
+			return nil, err
+		}
+		suggestions[i].LastActivityAt = lastActivityAt
+	}
+	sortFollowSuggestions(suggestions)
+
+	return suggestions, nil
+}
+
+// sortFollowSuggestions orders by mutual count, then followers, then most recently active
+func sortFollowSuggestions(suggestions []FollowSuggestion) {
+	sort.SliceStable(suggestions, func(i, j int) bool {
+		a, b := suggestions[i], suggestions[j]
+		if a.MutualCount != b.MutualCount {
+			return a.MutualCount > b.MutualCount
+		}
+		if a.FollowsYou != b.FollowsYou {
+			return a.FollowsYou
+		}
+		if (a.LastActivityAt == nil) != (b.LastActivityAt == nil) {
+			return a.LastActivityAt != nil
+		}
+		if a.LastActivityAt != nil && !a.LastActivityAt.Equal(*b.LastActivityAt) {
+			return a.LastActivityAt.After(*b.LastActivityAt)
+		}
+		return a.UserSerial < b.UserSerial
+	})
+}
+
+// followedUserSerials lists the users whose post feed is followed by the timeline of userSerial
+func (s *service) followedUserSerials(userSerial string) ([]string, error) {
+	// Get timeline feed object
+	timelineFlatFeed, err := s.getstreamClient.FlatFeed(s.topology.TimelineGroup, userSerial)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := timelineFlatFeed.GetFollowing(stream.WithFollowingOffset(0), stream.WithFollowingLimit(suggestionGraphLimit))
+	if err != nil {
+		return nil, err
+	}
+
+	userSerials := []string{}
+	for _, following := range resp.Results {
+		userSerials = append(userSerials, userSerialFromActor(following.TargetID))
+	}
+	return userSerials, nil
+}
+
+// followerUserSerials lists the users following the user feed of userSerial
+func (s *service) followerUserSerials(userSerial string) ([]string, error) {
+	// Get user feed object
+	userFlatFeed, err := s.getstreamClient.FlatFeed(s.topology.PostGroup, userSerial)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := userFlatFeed.GetFollowers(stream.WithFollowersOffset(0), stream.WithFollowersLimit(suggestionGraphLimit))
+	if err != nil {
+		return nil, err
+	}
+
+	// Several feeds of a follower may follow us, count them once
+	seen := map[string]bool{}
+	userSerials := []string{}
+	for _, follower := range resp.Results {
+		followerUserSerial := userSerialFromActor(follower.FeedID)
+		if !seen[followerUserSerial] {
+			seen[followerUserSerial] = true
+			userSerials = append(userSerials, followerUserSerial)
+		}
+	}
+	return userSerials, nil
+}
+
+// lastActivityAt returns the time of the latest post of userSerial, nil when they never posted
+func (s *service) lastActivityAt(userSerial string) (*time.Time, error) {
+	// Get user feed object
+	userFlatFeed, err := s.getstreamClient.FlatFeed(s.topology.PostGroup, userSerial)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := userFlatFeed.GetActivities(stream.WithActivitiesLimit(1))
+	if err != nil {
+		return nil, err
+	}
+	if len(resp.Results) == 0 {
+		return nil, nil
+	}
+
+	lastActivityAt := resp.Results[0].Time.Time
+	return &lastActivityAt, nil
+}
+
+// suggestionCache keeps computed suggestions per user for a while
+type suggestionCache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	entries map[string]suggestionCacheEntry
+}
+
+type suggestionCacheEntry struct {
+	suggestions []FollowSuggestion
+	expiresAt   time.Time
+}
+
+func newSuggestionCache(ttl time.Duration) *suggestionCache {
+	return &suggestionCache{
+		ttl:     ttl,
+		entries: map[string]suggestionCacheEntry{},
+	}
+}
+
+func (c *suggestionCache) get(userSerial string) ([]FollowSuggestion, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[userSerial]
+	if !ok || time.Now().After(entry.expiresAt) {
+		delete(c.entries, userSerial)
+		return nil, false
+	}
+	return entry.suggestions, true
+}
+
+func (c *suggestionCache) set(userSerial string, suggestions []FollowSuggestion) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.entries[userSerial] = suggestionCacheEntry{
+		suggestions: suggestions,
+		expiresAt:   time.Now().Add(c.ttl),
+	}
+}
+
+// invalidate drops cached suggestions of every given user
+func (c *suggestionCache) invalidate(userSerials ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, userSerial := range userSerials {
+		delete(c.entries, userSerial)
+	}
+}
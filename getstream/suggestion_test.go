@@ -0,0 +1,93 @@
+package getstream
+
+import (
+	"context"
+	"net/http"
+	"testing"
+)
+
+// suggestionGraph has user 1 following 2 and 3, who follow 4 and 5, and
+// followed by 6 and by 7 they blocked
+func suggestionGraph(t *testing.T) (*service, *fakeStream) {
+	svc, fake := newFakeStreamService(t, NewMemoryStore())
+	for _, edge := range [][2]string{
+		{"timeline:1", "user:2"},
+		{"timeline:1", "user:3"},
+		{"timeline:2", "user:4"},
+		{"timeline:2", "user:5"},
+		{"timeline:3", "user:4"},
+		{"timeline:3", "user:1"},
+		{"timeline:6", "user:1"},
+		{"timeline:7", "user:1"},
+		{"timeline:2", "user:7"},
+	} {
+		fake.follow(edge[0], edge[1])
+	}
+	fake.post("user:5", "user:5")
+	if err := svc.store.AddBlock("1", "7"); err != nil {
+		t.Fatal(err)
+	}
+	return svc, fake
+}
+
+func TestGetFollowSuggestions(t *testing.T) {
+	ctx := context.Background()
+	svc, _ := suggestionGraph(t)
+
+	suggestions, err := svc.GetFollowSuggestionsByUserSerial(ctx, "1", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Most mutual follows first, then followers, never blocked or followed users
+	want := []struct {
+		userSerial  string
+		mutualCount int
+		followsYou  bool
+		posted      bool
+	}{
+		{userSerial: "4", mutualCount: 2},
+		{userSerial: "5", mutualCount: 1, posted: true},
+		{userSerial: "6", followsYou: true},
+	}
+	if len(suggestions) != len(want) {
+		t.Fatalf("suggestions = %+v, want %d", suggestions, len(want))
+	}
+	for i, w := range want {
+		got := suggestions[i]
+		if got.UserSerial != w.userSerial || got.MutualCount != w.mutualCount || got.FollowsYou != w.followsYou || (got.LastActivityAt != nil) != w.posted {
+			t.Errorf("suggestion %d = %+v, want %+v", i, got, w)
+		}
+	}
+
+	limited, err := svc.GetFollowSuggestionsByUserSerial(ctx, "1", 1)
+	if err != nil || len(limited) != 1 || limited[0].UserSerial != "4" {
+		t.Errorf("limited = %+v, %v, want only 4", limited, err)
+	}
+}
+
+func TestGetFollowSuggestionsCache(t *testing.T) {
+	ctx := context.Background()
+	svc, fake := suggestionGraph(t)
+
+	// A failed computation isn't cached
+	fake.fail(fault{status: http.StatusInternalServerError})
+	if _, err := svc.GetFollowSuggestionsByUserSerial(ctx, "1", 10); err == nil {
+		t.Fatal("suggestions computed while Stream fails")
+	}
+	first, err := svc.GetFollowSuggestionsByUserSerial(ctx, "1", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Computed suggestions are reused until they expire
+	calls := fake.callCount()
+	fake.follow("timeline:1", "user:4")
+	cached, err := svc.GetFollowSuggestionsByUserSerial(ctx, "1", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fake.callCount() != calls || len(cached) != len(first) {
+		t.Errorf("cached suggestions = %+v after %d Stream calls, want %+v without any", cached, fake.callCount()-calls, first)
+	}
+}
@@ -0,0 +1,41 @@
+package getstream
+
+import (
+	"context"
+	"strings"
+
+	stream "gopkg.in/GetStream/stream-go2.v3"
+)
+
+// Stream returns at most this many followers per call
+const followersPageSize = 500
+
+// GetTimelineFollowersByUserSerial lists every user whose timeline follows
+// the user feed of userSerial, i.e. who sees their posts
+func (s *service) GetTimelineFollowersByUserSerial(ctx context.Context, userSerial string) ([]string, error) {
+	// Get user feed object
+	userFlatFeed, err := s.getstreamClient.FlatFeed(s.topology.PostGroup, userSerial)
+	if err != nil {
+		return nil, err
+	}
+
+	userSerials := []string{}
+	for offset := 0; ; offset += followersPageSize {
+		resp, err := userFlatFeed.GetFollowers(stream.WithFollowersOffset(offset), stream.WithFollowersLimit(followersPageSize))
+		if err != nil {
+			return nil, err
+		}
+
+		// Other feeds of followers may follow us as well, only timelines show posts
+		for _, follower := range resp.Results {
+			feedGroup, followerUserSerial, _ := strings.Cut(follower.FeedID, ":")
+			if feedGroup == s.topology.TimelineGroup {
+				userSerials = append(userSerials, followerUserSerial)
+			}
+		}
+
+		if len(resp.Results) < followersPageSize {
+			return userSerials, nil
+		}
+	}
+}
@@ -0,0 +1,126 @@
+package getstream
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	stream "gopkg.in/GetStream/stream-go2.v3"
+)
+
+// Stream only accepts letters, digits and underscores in feed group names
+var validFeedGroup = regexp.MustCompile(`^\w+$`)
+
+// Topology names the Stream feed groups of the Service and which feeds of a
+// user follow which feeds of the users they follow. Groups must be set up
+// in the Stream app with the matching feed type.
+type Topology struct {
+	// PostGroup is the flat group users post to
+	PostGroup string
+	// TimelineGroup is the flat group showing the posts of followed users
+	TimelineGroup string
+	// NotificationGroup and AggregatedGroup are optional views of the posts
+	// of followed users, wired up by follow rules
+	NotificationGroup string
+	AggregatedGroup   string
+	// PostVerb is the verb of post activities
+	PostVerb string
+	// FollowRules are applied in order on follow and unfollow
+	FollowRules []FollowRule
+}
+
+// FollowRule makes the Follower group of a user follow the Followed group
+// of every user they follow
+type FollowRule struct {
+	Follower string
+	Followed string
+}
+
+func (r FollowRule) String() string {
+	return r.Follower + ":" + r.Followed
+}
+
+// ParseFollowRules parses rules written as `<follower group>:<followed group>`
+func ParseFollowRules(rules []string) ([]FollowRule, error) {
+	followRules := []FollowRule{}
+	for _, rule := range rules {
+		follower, followed, ok := strings.Cut(rule, ":")
+		if !ok {
+			return nil, fmt.Errorf("follow rule %q must be `<follower group>:<followed group>`", rule)
+		}
+		followRules = append(followRules, FollowRule{
+			Follower: strings.TrimSpace(follower),
+			Followed: strings.TrimSpace(followed),
+		})
+	}
+	return followRules, nil
+}
+
+// Validate reports topologies the Service can't work with
+func (t Topology) Validate() error {
+	if t.PostGroup == "" || t.TimelineGroup == "" {
+		return errors.New("post and timeline feed groups are mandatory")
+	}
+	if t.PostVerb == "" {
+		return errors.New("post verb is mandatory")
+	}
+
+	groups := []string{}
+	for _, group := range []string{t.PostGroup, t.TimelineGroup, t.NotificationGroup, t.AggregatedGroup} {
+		if group == "" {
+			continue
+		}
+		if !validFeedGroup.MatchString(group) {
+			return fmt.Errorf("feed group %q must be letters, digits or `_`", group)
+		}
+		if slices.Contains(groups, group) {
+			return fmt.Errorf("feed group %q is configured twice", group)
+		}
+		groups = append(groups, group)
+	}
+
+	for i, rule := range t.FollowRules {
+		if !slices.Contains(groups, rule.Follower) {
+			return fmt.Errorf("follow rule %s: %q isn't a configured feed group", rule, rule.Follower)
+		}
+		// Stream feeds can only follow flat feeds
+		if rule.Followed != t.PostGroup && rule.Followed != t.TimelineGroup {
+			return fmt.Errorf("follow rule %s: %q must be the post or timeline feed group", rule, rule.Followed)
+		}
+		if slices.Contains(t.FollowRules[:i], rule) {
+			return fmt.Errorf("follow rule %s is configured twice", rule)
+		}
+	}
+
+	// Timelines, realtime fan out and relationships rely on this edge
+	timelineRule := FollowRule{Follower: t.TimelineGroup, Followed: t.PostGroup}
+	if !slices.Contains(t.FollowRules, timelineRule) {
+		return fmt.Errorf("follow rule %s is mandatory", timelineRule)
+	}
+	return nil
+}
+
+// followerGroups lists the groups following on follow, e.g. `timeline,user`
+func (t Topology) followerGroups() string {
+	groups := []string{}
+	for _, rule := range t.FollowRules {
+		if !slices.Contains(groups, rule.Follower) {
+			groups = append(groups, rule.Follower)
+		}
+	}
+	return strings.Join(groups, ",")
+}
+
+// feed returns the feed of userSerial in group, of the type of that group
+func (s *service) feed(group, userSerial string) (stream.Feed, error) {
+	switch group {
+	case s.topology.NotificationGroup:
+		return s.getstreamClient.NotificationFeed(group, userSerial)
+	case s.topology.AggregatedGroup:
+		return s.getstreamClient.AggregatedFeed(group, userSerial)
+	default:
+		return s.getstreamClient.FlatFeed(group, userSerial)
+	}
+}
@@ -0,0 +1,90 @@
+package getstream
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestParseFollowRules(t *testing.T) {
+	tests := []struct {
+		name    string
+		rules   []string
+		want    []FollowRule
+		wantErr bool
+	}{
+		{
+			name:  "rules in order",
+			rules: []string{"timeline:user", " notification : user "},
+			want:  []FollowRule{{Follower: "timeline", Followed: "user"}, {Follower: "notification", Followed: "user"}},
+		},
+		{name: "no rules", rules: nil, want: []FollowRule{}},
+		{name: "missing separator", rules: []string{"timeline:user", "timeline"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseFollowRules(tt.rules)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, want error %v", err, tt.wantErr)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTopologyValidate(t *testing.T) {
+	// valid is the setup of a new Stream app with a notification feed
+	valid := func() Topology {
+		return Topology{
+			PostGroup:         "user",
+			TimelineGroup:     "timeline",
+			NotificationGroup: "notification",
+			PostVerb:          "post",
+			FollowRules: []FollowRule{
+				{Follower: "timeline", Followed: "user"},
+				{Follower: "notification", Followed: "user"},
+			},
+		}
+	}
+
+	tests := []struct {
+		name    string
+		change  func(t *Topology)
+		wantErr bool
+	}{
+		{name: "valid", change: func(t *Topology) {}},
+		{name: "following the timeline group", change: func(t *Topology) {
+			t.FollowRules = append(t.FollowRules, FollowRule{Follower: "user", Followed: "timeline"})
+		}},
+		{name: "missing post group", change: func(t *Topology) { t.PostGroup = "" }, wantErr: true},
+		{name: "missing timeline group", change: func(t *Topology) { t.TimelineGroup = "" }, wantErr: true},
+		{name: "missing post verb", change: func(t *Topology) { t.PostVerb = "" }, wantErr: true},
+		{name: "invalid group name", change: func(t *Topology) { t.AggregatedGroup = "my-feed" }, wantErr: true},
+		{name: "group configured twice", change: func(t *Topology) { t.AggregatedGroup = "notification" }, wantErr: true},
+		{name: "follower isn't a group", change: func(t *Topology) {
+			t.FollowRules = append(t.FollowRules, FollowRule{Follower: "aggregated", Followed: "user"})
+		}, wantErr: true},
+		{name: "following a non-flat group", change: func(t *Topology) {
+			t.FollowRules = append(t.FollowRules, FollowRule{Follower: "timeline", Followed: "notification"})
+		}, wantErr: true},
+		{name: "empty followed group", change: func(t *Topology) {
+			t.FollowRules = append(t.FollowRules, FollowRule{Follower: "timeline"})
+		}, wantErr: true},
+		{name: "rule configured twice", change: func(t *Topology) {
+			t.FollowRules = append(t.FollowRules, FollowRule{Follower: "notification", Followed: "user"})
+		}, wantErr: true},
+		{name: "missing timeline rule", change: func(t *Topology) { t.FollowRules = t.FollowRules[1:] }, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			topology := valid()
+			tt.change(&topology)
+			if err := topology.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("err = %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+}
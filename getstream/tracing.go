@@ -0,0 +1,400 @@
+package getstream
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	stream "gopkg.in/GetStream/stream-go2.v3"
+)
+
+const tracerName = "github.com/wisnuanggoro/go-getstream/getstream"
+
+// tracingService wraps every call of the wrapped Service in a span carrying
+// the feed group, enrichment options and hashed user serials
+type tracingService struct {
+	next     Service
+	topology Topology
+	tracer   trace.Tracer
+}
+
+// NewTracingService names feed groups as laid out by topology
+func NewTracingService(next Service, topology Topology) Service {
+	return &tracingService{
+		next:     next,
+		topology: topology,
+		tracer:   otel.Tracer(tracerName),
+	}
+}
+
+func (t *tracingService) start(ctx context.Context, method string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return t.tracer.Start(ctx, "getstream.Service/"+method,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attribute.String("getstream.method", method)),
+		trace.WithAttributes(attrs...),
+	)
+}
+
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetAttributes(attribute.String("getstream.error_class", ErrorClass(err)))
+		// Rule violations are expected outcomes, not failed calls
+		if ErrorClass(err) != ErrorClassDomain {
+			span.SetStatus(codes.Error, err.Error())
+		}
+	}
+	span.End()
+}
+
+// hashedSerial keeps user serials out of trace backends while still letting
+// spans of the same user be grouped
+func hashedSerial(key, userSerial string) attribute.KeyValue {
+	sum := sha256.Sum256([]byte(userSerial))
+	return attribute.String(key, hex.EncodeToString(sum[:8]))
+}
+
+func (t *tracingService) AddPostByUserSerial(ctx context.Context, userSerial, postContent, postType string) (*stream.AddActivityResponse, error) {
+	ctx, span := t.start(ctx, "AddPostByUserSerial",
+		attribute.String("getstream.feed_group", t.topology.PostGroup),
+		hashedSerial("getstream.user_serial_hash", userSerial),
+	)
+	resp, err := t.next.AddPostByUserSerial(ctx, userSerial, postContent, postType)
+	endSpan(span, err)
+	return resp, err
+}
+
+func (t *tracingService) AddScheduledPostByUserSerial(ctx context.Context, userSerial, scheduleID, postContent, postType string, publishAt time.Time) (*stream.AddActivityResponse, error) {
+	ctx, span := t.start(ctx, "AddScheduledPostByUserSerial",
+		attribute.String("getstream.feed_group", t.topology.PostGroup),
+		hashedSerial("getstream.user_serial_hash", userSerial),
+		attribute.String("getstream.schedule_id", scheduleID),
+	)
+	resp, err := t.next.AddScheduledPostByUserSerial(ctx, userSerial, scheduleID, postContent, postType, publishAt)
+	endSpan(span, err)
+	return resp, err
+}
+
+func (t *tracingService) GetPostByUserSerial(ctx context.Context, viewerUserSerial, userSerial string) (*stream.FlatFeedResponse, error) {
+	ctx, span := t.start(ctx, "GetPostByUserSerial",
+		attribute.String("getstream.feed_group", t.topology.PostGroup),
+		hashedSerial("getstream.viewer_user_serial_hash", viewerUserSerial),
+		hashedSerial("getstream.user_serial_hash", userSerial),
+	)
+	resp, err := t.next.GetPostByUserSerial(ctx, viewerUserSerial, userSerial)
+	endSpan(span, err)
+	return resp, err
+}
+
+func (t *tracingService) GetPostDetailByUserSerial(ctx context.Context, viewerUserSerial, userSerial string) (*stream.EnrichedFlatFeedResponse, error) {
+	ctx, span := t.start(ctx, "GetPostDetailByUserSerial",
+		attribute.String("getstream.feed_group", t.topology.PostGroup),
+		attribute.String("getstream.enrichment", "reaction_kinds:like,reaction_counts"),
+		hashedSerial("getstream.viewer_user_serial_hash", viewerUserSerial),
+		hashedSerial("getstream.user_serial_hash", userSerial),
+	)
+	resp, err := t.next.GetPostDetailByUserSerial(ctx, viewerUserSerial, userSerial)
+	endSpan(span, err)
+	return resp, err
+}
+
+func (t *tracingService) EditPostByPostID(ctx context.Context, userSerial, postID, postContent, postType string) (*stream.UpdateActivityResponse, error) {
+	ctx, span := t.start(ctx, "EditPostByPostID",
+		attribute.String("getstream.feed_group", t.topology.PostGroup),
+		hashedSerial("getstream.user_serial_hash", userSerial),
+		attribute.String("getstream.post_id", postID),
+	)
+	resp, err := t.next.EditPostByPostID(ctx, userSerial, postID, postContent, postType)
+	endSpan(span, err)
+	return resp, err
+}
+
+func (t *tracingService) GetPostHistoryByPostID(ctx context.Context, viewerUserSerial, postID string) ([]PostRevision, error) {
+	ctx, span := t.start(ctx, "GetPostHistoryByPostID",
+		attribute.String("getstream.feed_group", t.topology.PostGroup),
+		hashedSerial("getstream.viewer_user_serial_hash", viewerUserSerial),
+		attribute.String("getstream.post_id", postID),
+	)
+	resp, err := t.next.GetPostHistoryByPostID(ctx, viewerUserSerial, postID)
+	endSpan(span, err)
+	return resp, err
+}
+
+func (t *tracingService) DeletePostByPostID(ctx context.Context, userSerial, postID string) error {
+	ctx, span := t.start(ctx, "DeletePostByPostID",
+		attribute.String("getstream.feed_group", t.topology.PostGroup),
+		hashedSerial("getstream.user_serial_hash", userSerial),
+		attribute.String("getstream.post_id", postID),
+	)
+	err := t.next.DeletePostByPostID(ctx, userSerial, postID)
+	endSpan(span, err)
+	return err
+}
+
+func (t *tracingService) GetTimelineByUserSerial(ctx context.Context, userSerial string) (*stream.FlatFeedResponse, error) {
+	ctx, span := t.start(ctx, "GetTimelineByUserSerial",
+		attribute.String("getstream.feed_group", t.topology.TimelineGroup),
+		hashedSerial("getstream.user_serial_hash", userSerial),
+	)
+	resp, err := t.next.GetTimelineByUserSerial(ctx, userSerial)
+	endSpan(span, err)
+	return resp, err
+}
+
+func (t *tracingService) GetDetailTimelineByUserSerial(ctx context.Context, userSerial string) (*stream.EnrichedFlatFeedResponse, error) {
+	ctx, span := t.start(ctx, "GetDetailTimelineByUserSerial",
+		attribute.String("getstream.feed_group", t.topology.TimelineGroup),
+		attribute.String("getstream.enrichment", "recent_reactions,reaction_counts"),
+		hashedSerial("getstream.user_serial_hash", userSerial),
+	)
+	resp, err := t.next.GetDetailTimelineByUserSerial(ctx, userSerial)
+	endSpan(span, err)
+	return resp, err
+}
+
+func (t *tracingService) GetFeedFollowersByUserSerial(ctx context.Context, userSerial string) (*stream.FollowersResponse, error) {
+	ctx, span := t.start(ctx, "GetFeedFollowersByUserSerial",
+		attribute.String("getstream.feed_group", t.topology.PostGroup),
+		hashedSerial("getstream.user_serial_hash", userSerial),
+	)
+	resp, err := t.next.GetFeedFollowersByUserSerial(ctx, userSerial)
+	endSpan(span, err)
+	return resp, err
+}
+
+func (t *tracingService) GetFollowedFeedsByUserSerial(ctx context.Context, userSerial string) (*stream.FollowingResponse, error) {
+	ctx, span := t.start(ctx, "GetFollowedFeedsByUserSerial",
+		attribute.String("getstream.feed_group", t.topology.TimelineGroup),
+		hashedSerial("getstream.user_serial_hash", userSerial),
+	)
+	resp, err := t.next.GetFollowedFeedsByUserSerial(ctx, userSerial)
+	endSpan(span, err)
+	return resp, err
+}
+
+func (t *tracingService) GetTimelineFollowersByUserSerial(ctx context.Context, userSerial string) ([]string, error) {
+	ctx, span := t.start(ctx, "GetTimelineFollowersByUserSerial",
+		attribute.String("getstream.feed_group", t.topology.PostGroup),
+		hashedSerial("getstream.user_serial_hash", userSerial),
+	)
+	resp, err := t.next.GetTimelineFollowersByUserSerial(ctx, userSerial)
+	endSpan(span, err)
+	return resp, err
+}
+
+func (t *tracingService) Follow(ctx context.Context, ownUserSerial, targetUserSerial string) (*FollowRequest, error) {
+	ctx, span := t.start(ctx, "Follow",
+		attribute.String("getstream.feed_group", t.topology.followerGroups()),
+		hashedSerial("getstream.own_user_serial_hash", ownUserSerial),
+		hashedSerial("getstream.target_user_serial_hash", targetUserSerial),
+	)
+	resp, err := t.next.Follow(ctx, ownUserSerial, targetUserSerial)
+	endSpan(span, err)
+	return resp, err
+}
+
+func (t *tracingService) Unfollow(ctx context.Context, ownUserSerial, targetUserSerial string) error {
+	ctx, span := t.start(ctx, "Unfollow",
+		attribute.String("getstream.feed_group", t.topology.followerGroups()),
+		hashedSerial("getstream.own_user_serial_hash", ownUserSerial),
+		hashedSerial("getstream.target_user_serial_hash", targetUserSerial),
+	)
+	err := t.next.Unfollow(ctx, ownUserSerial, targetUserSerial)
+	endSpan(span, err)
+	return err
+}
+
+func (t *tracingService) AddLikeToPostID(ctx context.Context, likerUserSerial, postID string) (*stream.Reaction, error) {
+	ctx, span := t.start(ctx, "AddLikeToPostID",
+		hashedSerial("getstream.liker_user_serial_hash", likerUserSerial),
+		attribute.String("getstream.post_id", postID),
+	)
+	resp, err := t.next.AddLikeToPostID(ctx, likerUserSerial, postID)
+	endSpan(span, err)
+	return resp, err
+}
+
+func (t *tracingService) RetrieveLikeDetailOnPostID(ctx context.Context, postID string, limit int) (*stream.FilterReactionResponse, error) {
+	ctx, span := t.start(ctx, "RetrieveLikeDetailOnPostID",
+		attribute.String("getstream.post_id", postID),
+		attribute.Int("getstream.limit", limit),
+	)
+	resp, err := t.next.RetrieveLikeDetailOnPostID(ctx, postID, limit)
+	endSpan(span, err)
+	return resp, err
+}
+
+func (t *tracingService) RetrieveLikeDetailOnPostIDWithPagination(ctx context.Context, postID, nextLikeID string, limit int) (*stream.FilterReactionResponse, error) {
+	ctx, span := t.start(ctx, "RetrieveLikeDetailOnPostIDWithPagination",
+		attribute.String("getstream.post_id", postID),
+		attribute.String("getstream.next_like_id", nextLikeID),
+		attribute.Int("getstream.limit", limit),
+	)
+	resp, err := t.next.RetrieveLikeDetailOnPostIDWithPagination(ctx, postID, nextLikeID, limit)
+	endSpan(span, err)
+	return resp, err
+}
+
+func (t *tracingService) RemoveLikeByReactionID(ctx context.Context, reactionID string) error {
+	ctx, span := t.start(ctx, "RemoveLikeByReactionID",
+		attribute.String("getstream.reaction_id", reactionID),
+	)
+	err := t.next.RemoveLikeByReactionID(ctx, reactionID)
+	endSpan(span, err)
+	return err
+}
+
+func (t *tracingService) BlockUser(ctx context.Context, ownUserSerial, targetUserSerial string) error {
+	ctx, span := t.start(ctx, "BlockUser",
+		attribute.String("getstream.feed_group", t.topology.followerGroups()),
+		hashedSerial("getstream.own_user_serial_hash", ownUserSerial),
+		hashedSerial("getstream.target_user_serial_hash", targetUserSerial),
+	)
+	err := t.next.BlockUser(ctx, ownUserSerial, targetUserSerial)
+	endSpan(span, err)
+	return err
+}
+
+func (t *tracingService) UnblockUser(ctx context.Context, ownUserSerial, targetUserSerial string) error {
+	ctx, span := t.start(ctx, "UnblockUser",
+		hashedSerial("getstream.own_user_serial_hash", ownUserSerial),
+		hashedSerial("getstream.target_user_serial_hash", targetUserSerial),
+	)
+	err := t.next.UnblockUser(ctx, ownUserSerial, targetUserSerial)
+	endSpan(span, err)
+	return err
+}
+
+func (t *tracingService) GetBlockedUsersByUserSerial(ctx context.Context, userSerial string) ([]string, error) {
+	ctx, span := t.start(ctx, "GetBlockedUsersByUserSerial",
+		hashedSerial("getstream.user_serial_hash", userSerial),
+	)
+	resp, err := t.next.GetBlockedUsersByUserSerial(ctx, userSerial)
+	endSpan(span, err)
+	return resp, err
+}
+
+func (t *tracingService) MuteUser(ctx context.Context, ownUserSerial, targetUserSerial string) error {
+	ctx, span := t.start(ctx, "MuteUser",
+		hashedSerial("getstream.own_user_serial_hash", ownUserSerial),
+		hashedSerial("getstream.target_user_serial_hash", targetUserSerial),
+	)
+	err := t.next.MuteUser(ctx, ownUserSerial, targetUserSerial)
+	endSpan(span, err)
+	return err
+}
+
+func (t *tracingService) UnmuteUser(ctx context.Context, ownUserSerial, targetUserSerial string) error {
+	ctx, span := t.start(ctx, "UnmuteUser",
+		hashedSerial("getstream.own_user_serial_hash", ownUserSerial),
+		hashedSerial("getstream.target_user_serial_hash", targetUserSerial),
+	)
+	err := t.next.UnmuteUser(ctx, ownUserSerial, targetUserSerial)
+	endSpan(span, err)
+	return err
+}
+
+func (t *tracingService) GetMutedUsersByUserSerial(ctx context.Context, userSerial string) ([]string, error) {
+	ctx, span := t.start(ctx, "GetMutedUsersByUserSerial",
+		hashedSerial("getstream.user_serial_hash", userSerial),
+	)
+	resp, err := t.next.GetMutedUsersByUserSerial(ctx, userSerial)
+	endSpan(span, err)
+	return resp, err
+}
+
+func (t *tracingService) SetPrivateAccount(ctx context.Context, userSerial string, private bool) error {
+	ctx, span := t.start(ctx, "SetPrivateAccount",
+		hashedSerial("getstream.user_serial_hash", userSerial),
+		attribute.Bool("getstream.private", private),
+	)
+	err := t.next.SetPrivateAccount(ctx, userSerial, private)
+	endSpan(span, err)
+	return err
+}
+
+func (t *tracingService) GetIncomingFollowRequests(ctx context.Context, userSerial string) ([]FollowRequest, error) {
+	ctx, span := t.start(ctx, "GetIncomingFollowRequests",
+		hashedSerial("getstream.user_serial_hash", userSerial),
+	)
+	resp, err := t.next.GetIncomingFollowRequests(ctx, userSerial)
+	endSpan(span, err)
+	return resp, err
+}
+
+func (t *tracingService) GetOutgoingFollowRequests(ctx context.Context, userSerial string) ([]FollowRequest, error) {
+	ctx, span := t.start(ctx, "GetOutgoingFollowRequests",
+		hashedSerial("getstream.user_serial_hash", userSerial),
+	)
+	resp, err := t.next.GetOutgoingFollowRequests(ctx, userSerial)
+	endSpan(span, err)
+	return resp, err
+}
+
+func (t *tracingService) ApproveFollowRequest(ctx context.Context, userSerial, requestID string) (*FollowRequest, error) {
+	ctx, span := t.start(ctx, "ApproveFollowRequest",
+		attribute.String("getstream.feed_group", t.topology.followerGroups()),
+		hashedSerial("getstream.user_serial_hash", userSerial),
+		attribute.String("getstream.request_id", requestID),
+	)
+	resp, err := t.next.ApproveFollowRequest(ctx, userSerial, requestID)
+	endSpan(span, err)
+	return resp, err
+}
+
+func (t *tracingService) RejectFollowRequest(ctx context.Context, userSerial, requestID string) (*FollowRequest, error) {
+	ctx, span := t.start(ctx, "RejectFollowRequest",
+		hashedSerial("getstream.user_serial_hash", userSerial),
+		attribute.String("getstream.request_id", requestID),
+	)
+	resp, err := t.next.RejectFollowRequest(ctx, userSerial, requestID)
+	endSpan(span, err)
+	return resp, err
+}
+
+func (t *tracingService) GetFollowSuggestionsByUserSerial(ctx context.Context, userSerial string, limit int) ([]FollowSuggestion, error) {
+	ctx, span := t.start(ctx, "GetFollowSuggestionsByUserSerial",
+		attribute.String("getstream.feed_group", t.topology.PostGroup),
+		hashedSerial("getstream.user_serial_hash", userSerial),
+		attribute.Int("getstream.limit", limit),
+	)
+	resp, err := t.next.GetFollowSuggestionsByUserSerial(ctx, userSerial, limit)
+	endSpan(span, err)
+	return resp, err
+}
+
+func (t *tracingService) GetRelationship(ctx context.Context, userSerial, otherSerial string) (*Relationship, error) {
+	ctx, span := t.start(ctx, "GetRelationship",
+		attribute.String("getstream.feed_group", t.topology.TimelineGroup),
+		hashedSerial("getstream.user_serial_hash", userSerial),
+		hashedSerial("getstream.other_serial_hash", otherSerial),
+	)
+	resp, err := t.next.GetRelationship(ctx, userSerial, otherSerial)
+	endSpan(span, err)
+	return resp, err
+}
+
+func (t *tracingService) GetRelationships(ctx context.Context, userSerial string, otherSerials []string) ([]Relationship, error) {
+	ctx, span := t.start(ctx, "GetRelationships",
+		attribute.String("getstream.feed_group", t.topology.TimelineGroup),
+		hashedSerial("getstream.user_serial_hash", userSerial),
+		attribute.Int("getstream.other_serials_count", len(otherSerials)),
+	)
+	resp, err := t.next.GetRelationships(ctx, userSerial, otherSerials)
+	endSpan(span, err)
+	return resp, err
+}
+
+func (t *tracingService) Ping(ctx context.Context) error {
+	ctx, span := t.start(ctx, "Ping",
+		attribute.String("getstream.feed_group", t.topology.PostGroup),
+	)
+	err := t.next.Ping(ctx)
+	endSpan(span, err)
+	return err
+}
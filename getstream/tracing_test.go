@@ -0,0 +1,91 @@
+package getstream
+
+import (
+	"context"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	stream "gopkg.in/GetStream/stream-go2.v3"
+)
+
+// recordSpans routes spans of the global tracer provider to the returned
+// exporter until the test ends
+func recordSpans(t *testing.T) *tracetest.InMemoryExporter {
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(provider)
+	t.Cleanup(func() {
+		otel.SetTracerProvider(previous)
+		provider.Shutdown(context.Background())
+	})
+	return exporter
+}
+
+func spanAttrs(span tracetest.SpanStub) map[attribute.Key]string {
+	attrs := map[attribute.Key]string{}
+	for _, attr := range span.Attributes {
+		attrs[attr.Key] = attr.Value.Emit()
+	}
+	return attrs
+}
+
+func TestTracingService(t *testing.T) {
+	tests := []struct {
+		name           string
+		err            error
+		wantStatus     codes.Code
+		wantErrorClass string
+	}{
+		{name: "success", wantStatus: codes.Unset},
+		{name: "rule violation", err: ErrBlocked, wantStatus: codes.Unset, wantErrorClass: ErrorClassDomain},
+		{name: "server error", err: stream.APIError{StatusCode: 500}, wantStatus: codes.Error, wantErrorClass: ErrorClassServer},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exporter := recordSpans(t)
+			svc := NewTracingService(&faultyService{err: tt.err}, testTopology())
+
+			// Spans are children of the caller's span
+			ctx, parent := otel.Tracer("test").Start(context.Background(), "request")
+			_, err := svc.GetTimelineByUserSerial(ctx, "1")
+			parent.End()
+			if (err == nil) != (tt.err == nil) {
+				t.Fatalf("err = %v, want %v", err, tt.err)
+			}
+
+			spans := exporter.GetSpans()
+			if len(spans) != 2 {
+				t.Fatalf("spans = %d, want the call and its parent", len(spans))
+			}
+			span := spans[0]
+			if span.Name != "getstream.Service/GetTimelineByUserSerial" || span.SpanKind != trace.SpanKindClient {
+				t.Errorf("span %q of kind %v, want a client span named after the method", span.Name, span.SpanKind)
+			}
+			if span.Parent.SpanID() != parent.SpanContext().SpanID() {
+				t.Error("span isn't a child of the caller's span")
+			}
+			if span.Status.Code != tt.wantStatus {
+				t.Errorf("status = %v, want %v", span.Status.Code, tt.wantStatus)
+			}
+
+			attrs := spanAttrs(span)
+			if attrs["getstream.feed_group"] != "timeline" || attrs["getstream.error_class"] != tt.wantErrorClass {
+				t.Errorf("attributes = %v", attrs)
+			}
+			// User serials are only exported hashed
+			if hash := attrs["getstream.user_serial_hash"]; hash == "" || hash == "1" {
+				t.Errorf("user serial hash = %q", hash)
+			}
+			if recorded := len(span.Events) > 0; recorded != (tt.err != nil) {
+				t.Errorf("error recorded = %v, want %v", recorded, tt.err != nil)
+			}
+		})
+	}
+}
@@ -0,0 +1,180 @@
+package graphqlapi
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/graphql-go/graphql/language/parser"
+)
+
+// defaultListSize is the number of items a list field is expected to return
+// when the query doesn't pass a `limit`, e.g. Stream returns the first 10
+// followers and 25 activities of a feed
+var defaultListSize = map[string]int{
+	"posts":       25,
+	"timeline":    25,
+	"followers":   10,
+	"following":   10,
+	"likes":       defaultLikeLimit,
+	"suggestions": defaultSuggestionLimit,
+}
+
+// cost is the estimated number of resolved fields of a query and its depth
+type cost struct {
+	complexity int
+	depth      int
+}
+
+// estimateCost walks the operation named operationName, or every operation
+// when empty, counting each field once per item of the lists it's nested in.
+// Complexities stop growing past maxComplexity so large limits can't
+// overflow them.
+func estimateCost(query, operationName string, variables map[string]interface{}, maxComplexity int) (cost, error) {
+	document, err := parser.Parse(parser.ParseParams{Source: query})
+	if err != nil {
+		return cost{}, err
+	}
+
+	fragments := map[string]*ast.FragmentDefinition{}
+	for _, definition := range document.Definitions {
+		if fragment, ok := definition.(*ast.FragmentDefinition); ok {
+			fragments[fragment.Name.Value] = fragment
+		}
+	}
+
+	ceiling := maxComplexity
+	if ceiling < math.MaxInt {
+		ceiling++
+	}
+	e := estimator{fragments: fragments, variables: variables, ceiling: ceiling}
+	total := cost{}
+	for _, definition := range document.Definitions {
+		operation, ok := definition.(*ast.OperationDefinition)
+		if !ok {
+			continue
+		}
+		if operationName != "" && (operation.Name == nil || operation.Name.Value != operationName) {
+			continue
+		}
+
+		c, err := e.selectionSet(operation.SelectionSet, map[string]bool{})
+		if err != nil {
+			return cost{}, err
+		}
+		total.complexity = max(total.complexity, c.complexity)
+		total.depth = max(total.depth, c.depth)
+	}
+	return total, nil
+}
+
+type estimator struct {
+	fragments map[string]*ast.FragmentDefinition
+	variables map[string]interface{}
+	// ceiling is the complexity past the maximum, costs saturate there
+	ceiling int
+}
+
+// add and multiply saturate at the ceiling, their inputs are within it
+func (e estimator) add(a, b int) int {
+	if a > e.ceiling-b {
+		return e.ceiling
+	}
+	return a + b
+}
+
+func (e estimator) multiply(a, b int) int {
+	if a != 0 && b > e.ceiling/a {
+		return e.ceiling
+	}
+	return min(a*b, e.ceiling)
+}
+
+// selectionSet returns the cost of the selections, visiting tracks the
+// fragments being expanded to stop on cycles
+func (e estimator) selectionSet(set *ast.SelectionSet, visiting map[string]bool) (cost, error) {
+	total := cost{}
+	if set == nil {
+		return total, nil
+	}
+
+	for _, selection := range set.Selections {
+		var c cost
+		var err error
+
+		switch selection := selection.(type) {
+		case *ast.Field:
+			c, err = e.field(selection, visiting)
+		case *ast.InlineFragment:
+			c, err = e.selectionSet(selection.SelectionSet, visiting)
+		case *ast.FragmentSpread:
+			name := selection.Name.Value
+			fragment, ok := e.fragments[name]
+			if !ok {
+				return cost{}, fmt.Errorf("unknown fragment %q", name)
+			}
+			if visiting[name] {
+				return cost{}, fmt.Errorf("fragment %q spreads itself", name)
+			}
+			visiting[name] = true
+			c, err = e.selectionSet(fragment.SelectionSet, visiting)
+			delete(visiting, name)
+		}
+		if err != nil {
+			return cost{}, err
+		}
+
+		total.complexity = e.add(total.complexity, c.complexity)
+		total.depth = max(total.depth, c.depth)
+	}
+	return total, nil
+}
+
+func (e estimator) field(field *ast.Field, visiting map[string]bool) (cost, error) {
+	children, err := e.selectionSet(field.SelectionSet, visiting)
+	if err != nil {
+		return cost{}, err
+	}
+
+	items := 1
+	if size, ok := defaultListSize[field.Name.Value]; ok {
+		items = size
+		if limit, ok := e.intArgument(field, "limit"); ok && limit > 0 {
+			items = min(limit, maxPageSize)
+		}
+	}
+
+	return cost{
+		complexity: e.add(1, e.multiply(items, children.complexity)),
+		depth:      1 + children.depth,
+	}, nil
+}
+
+func (e estimator) intArgument(field *ast.Field, name string) (int, bool) {
+	for _, argument := range field.Arguments {
+		if argument.Name.Value != name {
+			continue
+		}
+
+		switch value := argument.Value.(type) {
+		case *ast.IntValue:
+			i, err := strconv.Atoi(value.Value)
+			if errors.Is(err, strconv.ErrRange) && !strings.HasPrefix(value.Value, "-") {
+				return math.MaxInt, true
+			}
+			return i, err == nil
+		case *ast.Variable:
+			switch v := e.variables[value.Name.Value].(type) {
+			case float64:
+				// Converting floats past the int range is undefined
+				return int(max(min(v, maxPageSize), 0)), true
+			case int:
+				return v, true
+			}
+		}
+	}
+	return 0, false
+}
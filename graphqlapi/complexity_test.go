@@ -0,0 +1,107 @@
+package graphqlapi
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestEstimateCost(t *testing.T) {
+	tests := []struct {
+		name           string
+		query          string
+		operationName  string
+		variables      map[string]interface{}
+		wantComplexity int
+		wantDepth      int
+		wantErr        bool
+	}{
+		{name: "scalar field", query: `{ user(serial: "1") { serial } }`, wantComplexity: 2, wantDepth: 2},
+		{name: "list counts its default size", query: `{ timeline(serial: "1") { id } }`, wantComplexity: 26, wantDepth: 2},
+		{
+			name:           "nested lists multiply",
+			query:          `{ user(serial: "1") { followers { user { serial } } } }`,
+			wantComplexity: 22,
+			wantDepth:      4,
+		},
+		{name: "limit argument", query: `{ user(serial: "1") { suggestions(limit: 3) { serial } } }`, wantComplexity: 5, wantDepth: 3},
+		{
+			name:           "limit variable",
+			query:          `query Q($n: Int) { user(serial: "1") { suggestions(limit: $n) { serial } } }`,
+			variables:      map[string]interface{}{"n": float64(2)},
+			wantComplexity: 4,
+			wantDepth:      3,
+		},
+		{
+			name:           "fragments are expanded",
+			query:          `{ user(serial: "1") { ...F } } fragment F on User { serial posts { id } }`,
+			wantComplexity: 28,
+			wantDepth:      3,
+		},
+		{
+			name:           "inline fragments are expanded",
+			query:          `{ user(serial: "1") { ... on User { posts { id } } } }`,
+			wantComplexity: 27,
+			wantDepth:      3,
+		},
+		{
+			name:           "named operation only",
+			query:          `query Cheap { user(serial: "1") { serial } } query Costly { timeline(serial: "1") { id } }`,
+			operationName:  "Cheap",
+			wantComplexity: 2,
+			wantDepth:      2,
+		},
+		{
+			name:           "costliest operation without a name",
+			query:          `query Cheap { user(serial: "1") { serial } } query Costly { timeline(serial: "1") { id } }`,
+			wantComplexity: 26,
+			wantDepth:      2,
+		},
+		{
+			name:           "limit is clamped to the page size",
+			query:          `{ user(serial: "1") { suggestions(limit: 1000) { serial } } }`,
+			wantComplexity: 102,
+			wantDepth:      3,
+		},
+		{
+			name:           "limit past the int range is clamped",
+			query:          `{ user(serial: "1") { suggestions(limit: 99999999999999999999) { serial } } }`,
+			wantComplexity: 102,
+			wantDepth:      3,
+		},
+		{
+			name:           "limit variable past the int range is clamped",
+			query:          `query Q($n: Int) { user(serial: "1") { suggestions(limit: $n) { serial } } }`,
+			variables:      map[string]interface{}{"n": float64(1e300)},
+			wantComplexity: 102,
+			wantDepth:      3,
+		},
+		{
+			name:           "nested limits saturate past the maximum",
+			query:          `{ user(serial: "1") { suggestions(limit: 2147483647) { suggestions(limit: 2147483647) { suggestions(limit: 2147483647) { serial } } } } }`,
+			wantComplexity: 10001,
+			wantDepth:      5,
+		},
+		{
+			// 100^10 overflows int64 without saturation
+			name:           "deep nesting saturates instead of overflowing",
+			query:          `{ user(serial: "1") { ` + strings.Repeat(`suggestions(limit: 100) { `, 10) + `serial` + strings.Repeat(` }`, 10) + ` } }`,
+			wantComplexity: 10001,
+			wantDepth:      12,
+		},
+		{name: "fragment cycle", query: `{ user(serial: "1") { ...A } } fragment A on User { suggestions { ...A } }`, wantErr: true},
+		{name: "unknown fragment", query: `{ user(serial: "1") { ...Missing } }`, wantErr: true},
+		{name: "syntax error", query: `{ user(serial: "1") { serial }`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := estimateCost(tt.query, tt.operationName, tt.variables, 10000)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, want error %v", err, tt.wantErr)
+			}
+			if got.complexity != tt.wantComplexity || got.depth != tt.wantDepth {
+				t.Errorf("got complexity %d and depth %d, want %d and %d", got.complexity, got.depth, tt.wantComplexity, tt.wantDepth)
+			}
+		})
+	}
+}
@@ -0,0 +1,93 @@
+package graphqlapi
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+
+	"github.com/wisnuanggoro/go-getstream/getstream"
+)
+
+// Handler serves GraphQL queries over the Service. Responses follow the
+// GraphQL over HTTP format rather than the REST envelope, so standard
+// clients can read them.
+type Handler interface {
+	Serve(c *gin.Context)
+}
+
+type handler struct {
+	getstreamSvc  getstream.Service
+	schema        graphql.Schema
+	maxComplexity int
+	maxDepth      int
+}
+
+type request struct {
+	Query         string                 `json:"query" form:"query"`
+	OperationName string                 `json:"operationName" form:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+}
+
+func NewHandler(getstreamSvc getstream.Service, maxComplexity, maxDepth int) (Handler, error) {
+	schema, err := newSchema(getstreamSvc)
+	if err != nil {
+		return nil, err
+	}
+
+	return &handler{
+		getstreamSvc:  getstreamSvc,
+		schema:        schema,
+		maxComplexity: maxComplexity,
+		maxDepth:      maxDepth,
+	}, nil
+}
+
+func (h *handler) Serve(c *gin.Context) {
+	var req request
+	var err error
+	if c.Request.Method == http.MethodGet {
+		err = c.ShouldBindQuery(&req)
+	} else {
+		err = c.ShouldBindJSON(&req)
+	}
+	if err != nil || req.Query == "" {
+		respondWithError(c, http.StatusBadRequest, "a JSON body with a query is mandatory")
+		return
+	}
+
+	// Reject expensive queries before any of them reaches Stream
+	cost, err := estimateCost(req.Query, req.OperationName, req.Variables, h.maxComplexity)
+	if err != nil {
+		respondWithError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	if cost.depth > h.maxDepth {
+		respondWithError(c, http.StatusBadRequest, fmt.Sprintf("query depth %d exceeds the maximum of %d", cost.depth, h.maxDepth))
+		return
+	}
+	if cost.complexity > h.maxComplexity {
+		respondWithError(c, http.StatusBadRequest, fmt.Sprintf("query complexity exceeds the maximum of %d", h.maxComplexity))
+		return
+	}
+
+	ctx := context.WithValue(c.Request.Context(), loadersKey{}, newLoaders(h.getstreamSvc))
+	result := graphql.Do(graphql.Params{
+		Schema:         h.schema,
+		RequestString:  req.Query,
+		OperationName:  req.OperationName,
+		VariableValues: req.Variables,
+		Context:        ctx,
+	})
+
+	c.JSON(http.StatusOK, result)
+}
+
+func respondWithError(c *gin.Context, code int, message string) {
+	c.JSON(code, &graphql.Result{
+		Errors: []gqlerrors.FormattedError{{Message: message}},
+	})
+}
@@ -0,0 +1,85 @@
+package graphqlapi
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	stream "gopkg.in/GetStream/stream-go2.v3"
+
+	"github.com/wisnuanggoro/go-getstream/getstream"
+)
+
+// timelineService serves empty timelines and counts the calls reaching it
+type timelineService struct {
+	getstream.Service
+	calls int
+}
+
+func (s *timelineService) GetDetailTimelineByUserSerial(ctx context.Context, userSerial string) (*stream.EnrichedFlatFeedResponse, error) {
+	s.calls++
+	return &stream.EnrichedFlatFeedResponse{}, nil
+}
+
+func TestHandlerLimits(t *testing.T) {
+	tests := []struct {
+		name      string
+		query     string
+		wantCode  int
+		wantError string
+	}{
+		{name: "within limits", query: `{ timeline(serial: "1") { id } }`, wantCode: http.StatusOK},
+		{
+			name:      "too deep",
+			query:     `{ timeline(serial: "1") { author { followers { user { suggestions { serial } } } } } }`,
+			wantCode:  http.StatusBadRequest,
+			wantError: "query depth 6 exceeds the maximum of 5",
+		},
+		{
+			name:      "too complex",
+			query:     `{ timeline(serial: "1") { id author { serial } likes { id } } }`,
+			wantCode:  http.StatusBadRequest,
+			wantError: "query complexity exceeds the maximum of 100",
+		},
+		{
+			// Used to wrap around to a complexity of 0
+			name:      "nested limits past the int range",
+			query:     `{ user(serial: "1") { suggestions(limit: 2147483647) { suggestions(limit: 2147483647) { suggestions(limit: 2147483647) { serial } } } } }`,
+			wantCode:  http.StatusBadRequest,
+			wantError: "query complexity exceeds the maximum of 100",
+		},
+		{name: "not a query", query: `{ timeline(`, wantCode: http.StatusBadRequest},
+	}
+
+	gin.SetMode(gin.TestMode)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := &timelineService{}
+			h, err := NewHandler(svc, 100, 5)
+			if err != nil {
+				t.Fatal(err)
+			}
+			router := gin.New()
+			router.POST("/graphql", h.Serve)
+
+			body, _ := json.Marshal(request{Query: tt.query})
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader(string(body))))
+
+			if w.Code != tt.wantCode {
+				t.Fatalf("code = %d, want %d: %s", w.Code, tt.wantCode, w.Body)
+			}
+			if tt.wantError != "" && !strings.Contains(w.Body.String(), tt.wantError) {
+				t.Errorf("body = %s, want error %q", w.Body, tt.wantError)
+			}
+			// Rejected queries never reach Stream
+			if tt.wantCode != http.StatusOK && svc.calls != 0 {
+				t.Errorf("rejected query made %d service calls", svc.calls)
+			}
+		})
+	}
+}
@@ -0,0 +1,63 @@
+package graphqlapi
+
+import (
+	"context"
+	"sync"
+)
+
+type loaded[V any] struct {
+	value V
+	err   error
+}
+
+// loader collects the keys resolvers ask for and fetches them with a single
+// batch call. Load returns a thunk, the executor calls thunks only after
+// every sibling field was resolved, so the first thunk called fetches the
+// keys of all of them. Results are kept for the rest of the request.
+type loader[K comparable, V any] struct {
+	batch func(ctx context.Context, keys []K) (map[K]V, error)
+
+	mu      sync.Mutex
+	pending map[K]struct{}
+	results map[K]loaded[V]
+}
+
+func newLoader[K comparable, V any](batch func(ctx context.Context, keys []K) (map[K]V, error)) *loader[K, V] {
+	return &loader[K, V]{
+		batch:   batch,
+		pending: map[K]struct{}{},
+		results: map[K]loaded[V]{},
+	}
+}
+
+func (l *loader[K, V]) Load(ctx context.Context, key K) func() (interface{}, error) {
+	l.mu.Lock()
+	if _, ok := l.results[key]; !ok {
+		l.pending[key] = struct{}{}
+	}
+	l.mu.Unlock()
+
+	return func() (interface{}, error) {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+
+		if _, ok := l.results[key]; !ok {
+			l.dispatch(ctx)
+		}
+		result := l.results[key]
+		return result.value, result.err
+	}
+}
+
+func (l *loader[K, V]) dispatch(ctx context.Context) {
+	keys := make([]K, 0, len(l.pending))
+	for key := range l.pending {
+		keys = append(keys, key)
+	}
+	l.pending = map[K]struct{}{}
+
+	values, err := l.batch(ctx, keys)
+	for _, key := range keys {
+		l.results[key] = loaded[V]{value: values[key], err: err}
+	}
+}
@@ -0,0 +1,429 @@
+package graphqlapi
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	"github.com/graphql-go/graphql"
+	stream "gopkg.in/GetStream/stream-go2.v3"
+
+	"github.com/wisnuanggoro/go-getstream/getstream"
+)
+
+const (
+	defaultLikeLimit       = 10
+	defaultSuggestionLimit = 10
+	// maxPageSize bounds the `limit` of list fields, larger ones are clamped
+	maxPageSize          = 100
+	maxRelationshipBatch = 50
+	// Likes of different posts are fetched concurrently, at most this many at once
+	maxConcurrentLikeLoads = 8
+)
+
+// user is the source of the User type, viewer is who asked for the query
+// and is passed down to the users reached through it
+type user struct {
+	serial string
+	viewer string
+}
+
+type post struct {
+	activity stream.EnrichedActivity
+	viewer   string
+}
+
+type followEdge struct {
+	follower stream.Follower
+	// other is the user on the other side of the edge from the queried user
+	other  string
+	viewer string
+}
+
+type reaction struct {
+	reaction stream.Reaction
+	viewer   string
+}
+
+type likesKey struct {
+	postID string
+	limit  int
+}
+
+type relationshipKey struct {
+	viewer string
+	other  string
+}
+
+// loaders batch the lookups of a single request
+type loaders struct {
+	likes         *loader[likesKey, []stream.Reaction]
+	relationships *loader[relationshipKey, *getstream.Relationship]
+}
+
+type loadersKey struct{}
+
+func newLoaders(getstreamSvc getstream.Service) *loaders {
+	return &loaders{
+		likes: newLoader(func(ctx context.Context, keys []likesKey) (map[likesKey][]stream.Reaction, error) {
+			return loadLikes(ctx, getstreamSvc, keys)
+		}),
+		relationships: newLoader(func(ctx context.Context, keys []relationshipKey) (map[relationshipKey]*getstream.Relationship, error) {
+			return loadRelationships(ctx, getstreamSvc, keys)
+		}),
+	}
+}
+
+func loadersFromContext(ctx context.Context) *loaders {
+	return ctx.Value(loadersKey{}).(*loaders)
+}
+
+// loadLikes fetches the likes of every post concurrently, Stream has no
+// call returning the reactions of several activities
+func loadLikes(ctx context.Context, getstreamSvc getstream.Service, keys []likesKey) (map[likesKey][]stream.Reaction, error) {
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	var firstErr error
+	likes := map[likesKey][]stream.Reaction{}
+	slots := make(chan struct{}, maxConcurrentLikeLoads)
+
+	for _, key := range keys {
+		wg.Add(1)
+		slots <- struct{}{}
+		go func(key likesKey) {
+			defer func() {
+				<-slots
+				wg.Done()
+			}()
+
+			resp, err := getstreamSvc.RetrieveLikeDetailOnPostID(ctx, key.postID, key.limit)
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				if firstErr == nil {
+					firstErr = err
+				}
+				return
+			}
+			likes[key] = resp.Results
+		}(key)
+	}
+	wg.Wait()
+
+	return likes, firstErr
+}
+
+// loadRelationships looks up the relationships of each viewer with one
+// GetRelationships call per batch of users
+func loadRelationships(ctx context.Context, getstreamSvc getstream.Service, keys []relationshipKey) (map[relationshipKey]*getstream.Relationship, error) {
+	othersByViewer := map[string][]string{}
+	for _, key := range keys {
+		othersByViewer[key.viewer] = append(othersByViewer[key.viewer], key.other)
+	}
+
+	relationships := map[relationshipKey]*getstream.Relationship{}
+	for viewer, others := range othersByViewer {
+		for start := 0; start < len(others); start += maxRelationshipBatch {
+			end := min(start+maxRelationshipBatch, len(others))
+			resp, err := getstreamSvc.GetRelationships(ctx, viewer, others[start:end])
+			if err != nil {
+				return nil, err
+			}
+			for i := range resp {
+				relationships[relationshipKey{viewer: viewer, other: resp[i].OtherSerial}] = &resp[i]
+			}
+		}
+	}
+	return relationships, nil
+}
+
+// newSchema builds the schema resolving every field through getstreamSvc
+func newSchema(getstreamSvc getstream.Service) (graphql.Schema, error) {
+	relationshipType := graphql.NewObject(graphql.ObjectConfig{
+		Name:        "Relationship",
+		Description: "Follow edges between the viewer and a user",
+		Fields: graphql.Fields{
+			"following":  &graphql.Field{Type: graphql.NewNonNull(graphql.Boolean), Description: "The viewer follows the user"},
+			"followedBy": &graphql.Field{Type: graphql.NewNonNull(graphql.Boolean), Description: "The user follows the viewer"},
+			"mutual":     &graphql.Field{Type: graphql.NewNonNull(graphql.Boolean)},
+		},
+	})
+
+	// Types referring to each other get their fields once all of them exist
+	userType := graphql.NewObject(graphql.ObjectConfig{Name: "User", Fields: graphql.Fields{}})
+	postType := graphql.NewObject(graphql.ObjectConfig{Name: "Post", Fields: graphql.Fields{}})
+	reactionType := graphql.NewObject(graphql.ObjectConfig{Name: "Reaction", Fields: graphql.Fields{}})
+	followEdgeType := graphql.NewObject(graphql.ObjectConfig{Name: "FollowEdge", Fields: graphql.Fields{}})
+
+	userType.AddFieldConfig("serial", &graphql.Field{
+		Type: graphql.NewNonNull(graphql.String),
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			return p.Source.(user).serial, nil
+		},
+	})
+	userType.AddFieldConfig("posts", &graphql.Field{
+		Type:        graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(postType))),
+		Description: "Posts of the user, hidden from non-followers of a private user",
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			u := p.Source.(user)
+			resp, err := getstreamSvc.GetPostDetailByUserSerial(p.Context, u.viewer, u.serial)
+			if err != nil {
+				return nil, err
+			}
+			return posts(resp.Results, u.viewer), nil
+		},
+	})
+	userType.AddFieldConfig("followers", &graphql.Field{
+		Type:        graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(followEdgeType))),
+		Description: "First followers of the user",
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			u := p.Source.(user)
+			resp, err := getstreamSvc.GetFeedFollowersByUserSerial(p.Context, u.serial)
+			if err != nil {
+				return nil, err
+			}
+
+			edges := make([]followEdge, 0, len(resp.Results))
+			for _, follower := range resp.Results {
+				edges = append(edges, followEdge{follower: follower, other: serialFromFeedID(follower.FeedID), viewer: u.viewer})
+			}
+			return edges, nil
+		},
+	})
+	userType.AddFieldConfig("following", &graphql.Field{
+		Type:        graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(followEdgeType))),
+		Description: "First users the user follows",
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			u := p.Source.(user)
+			resp, err := getstreamSvc.GetFollowedFeedsByUserSerial(p.Context, u.serial)
+			if err != nil {
+				return nil, err
+			}
+
+			edges := make([]followEdge, 0, len(resp.Results))
+			for _, followed := range resp.Results {
+				edges = append(edges, followEdge{follower: followed, other: serialFromFeedID(followed.TargetID), viewer: u.viewer})
+			}
+			return edges, nil
+		},
+	})
+	userType.AddFieldConfig("relationship", &graphql.Field{
+		Type:        relationshipType,
+		Description: "Relationship of the viewer with the user, null without a viewer or for the viewer themselves",
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			u := p.Source.(user)
+			if u.viewer == "" || u.viewer == u.serial {
+				return nil, nil
+			}
+
+			load := loadersFromContext(p.Context).relationships.Load(p.Context, relationshipKey{viewer: u.viewer, other: u.serial})
+			return func() (interface{}, error) {
+				loaded, err := load()
+				if err != nil {
+					return nil, err
+				}
+				relationship := loaded.(*getstream.Relationship)
+				if relationship == nil {
+					return nil, nil
+				}
+				return map[string]interface{}{
+					"following":  relationship.Following,
+					"followedBy": relationship.FollowedBy,
+					"mutual":     relationship.Mutual,
+				}, nil
+			}, nil
+		},
+	})
+	userType.AddFieldConfig("suggestions", &graphql.Field{
+		Type:        graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(userType))),
+		Description: "Users worth following, from friends of friends",
+		Args: graphql.FieldConfigArgument{
+			"limit": &graphql.ArgumentConfig{Type: graphql.Int, DefaultValue: defaultSuggestionLimit, Description: "Larger limits are clamped to 100"},
+		},
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			u := p.Source.(user)
+			resp, err := getstreamSvc.GetFollowSuggestionsByUserSerial(p.Context, u.serial, pageSize(p.Args["limit"], defaultSuggestionLimit))
+			if err != nil {
+				return nil, err
+			}
+
+			users := make([]user, 0, len(resp))
+			for _, suggestion := range resp {
+				users = append(users, user{serial: suggestion.UserSerial, viewer: u.viewer})
+			}
+			return users, nil
+		},
+	})
+
+	postType.AddFieldConfig("id", &graphql.Field{
+		Type: graphql.NewNonNull(graphql.ID),
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			return p.Source.(post).activity.ID, nil
+		},
+	})
+	postType.AddFieldConfig("author", &graphql.Field{
+		Type: graphql.NewNonNull(userType),
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			po := p.Source.(post)
+			return user{serial: serialFromFeedID(po.activity.Actor.ID), viewer: po.viewer}, nil
+		},
+	})
+	postType.AddFieldConfig("content", &graphql.Field{
+		Type: graphql.String,
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			return p.Source.(post).activity.Extra["post"], nil
+		},
+	})
+	postType.AddFieldConfig("postType", &graphql.Field{
+		Type: graphql.String,
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			return p.Source.(post).activity.Extra["postType"], nil
+		},
+	})
+	postType.AddFieldConfig("time", &graphql.Field{
+		Type: graphql.DateTime,
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			return p.Source.(post).activity.Time.Time, nil
+		},
+	})
+	postType.AddFieldConfig("likeCount", &graphql.Field{
+		Type: graphql.NewNonNull(graphql.Int),
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			return p.Source.(post).activity.ReactionCounts["like"], nil
+		},
+	})
+	postType.AddFieldConfig("likes", &graphql.Field{
+		Type:        graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(reactionType))),
+		Description: "Latest likes of the post",
+		Args: graphql.FieldConfigArgument{
+			"limit": &graphql.ArgumentConfig{Type: graphql.Int, DefaultValue: defaultLikeLimit, Description: "Larger limits are clamped to 100"},
+		},
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			po := p.Source.(post)
+			key := likesKey{postID: po.activity.ID, limit: pageSize(p.Args["limit"], defaultLikeLimit)}
+			load := loadersFromContext(p.Context).likes.Load(p.Context, key)
+			return func() (interface{}, error) {
+				likes, err := load()
+				if err != nil {
+					return nil, err
+				}
+
+				reactions := []reaction{}
+				for _, like := range likes.([]stream.Reaction) {
+					reactions = append(reactions, reaction{reaction: like, viewer: po.viewer})
+				}
+				return reactions, nil
+			}, nil
+		},
+	})
+
+	reactionType.AddFieldConfig("id", &graphql.Field{
+		Type: graphql.NewNonNull(graphql.ID),
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			return p.Source.(reaction).reaction.ID, nil
+		},
+	})
+	reactionType.AddFieldConfig("kind", &graphql.Field{
+		Type: graphql.NewNonNull(graphql.String),
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			return p.Source.(reaction).reaction.Kind, nil
+		},
+	})
+	reactionType.AddFieldConfig("postID", &graphql.Field{
+		Type: graphql.NewNonNull(graphql.String),
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			return p.Source.(reaction).reaction.ActivityID, nil
+		},
+	})
+	reactionType.AddFieldConfig("user", &graphql.Field{
+		Type: graphql.NewNonNull(userType),
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			r := p.Source.(reaction)
+			return user{serial: r.reaction.UserID, viewer: r.viewer}, nil
+		},
+	})
+
+	followEdgeType.AddFieldConfig("feedID", &graphql.Field{
+		Type:        graphql.NewNonNull(graphql.String),
+		Description: "Feed following the target, e.g. `timeline:<serial>`",
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			return p.Source.(followEdge).follower.FeedID, nil
+		},
+	})
+	followEdgeType.AddFieldConfig("targetID", &graphql.Field{
+		Type:        graphql.NewNonNull(graphql.String),
+		Description: "Feed being followed, e.g. `user:<serial>`",
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			return p.Source.(followEdge).follower.TargetID, nil
+		},
+	})
+	followEdgeType.AddFieldConfig("user", &graphql.Field{
+		Type:        graphql.NewNonNull(userType),
+		Description: "User on the other side of the edge",
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			edge := p.Source.(followEdge)
+			return user{serial: edge.other, viewer: edge.viewer}, nil
+		},
+	})
+
+	queryType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			"user": &graphql.Field{
+				Type: graphql.NewNonNull(userType),
+				Args: graphql.FieldConfigArgument{
+					"serial": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+					"viewer": &graphql.ArgumentConfig{Type: graphql.String, Description: "User viewing the profile, needed for private posts and relationships"},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					viewer, _ := p.Args["viewer"].(string)
+					return user{serial: p.Args["serial"].(string), viewer: viewer}, nil
+				},
+			},
+			"timeline": &graphql.Field{
+				Type:        graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(postType))),
+				Description: "Timeline of a user, without posts of muted users",
+				Args: graphql.FieldConfigArgument{
+					"serial": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					serial := p.Args["serial"].(string)
+					resp, err := getstreamSvc.GetDetailTimelineByUserSerial(p.Context, serial)
+					if err != nil {
+						return nil, err
+					}
+					return posts(resp.Results, serial), nil
+				},
+			},
+		},
+	})
+
+	return graphql.NewSchema(graphql.SchemaConfig{Query: queryType})
+}
+
+func posts(activities []stream.EnrichedActivity, viewer string) []post {
+	posts := make([]post, 0, len(activities))
+	for _, activity := range activities {
+		posts = append(posts, post{activity: activity, viewer: viewer})
+	}
+	return posts
+}
+
+// serialFromFeedID returns `<serial>` from a feed ID like `user:<serial>`
+func serialFromFeedID(feedID string) string {
+	_, serial, found := strings.Cut(feedID, ":")
+	if !found {
+		return feedID
+	}
+	return serial
+}
+
+// pageSize returns the positive limit in arg clamped to maxPageSize, else
+// fallback
+func pageSize(arg interface{}, fallback int) int {
+	if i, ok := arg.(int); ok && i > 0 {
+		return min(i, maxPageSize)
+	}
+	return fallback
+}
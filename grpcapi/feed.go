@@ -0,0 +1,438 @@
+package grpcapi
+
+import (
+	"context"
+	"errors"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	stream "gopkg.in/GetStream/stream-go2.v3"
+
+	"github.com/wisnuanggoro/go-getstream/getstream"
+	pb "github.com/wisnuanggoro/go-getstream/grpcapi/getstreampb"
+)
+
+const (
+	defaultLikeLimit       = 10
+	defaultSuggestionLimit = 10
+	maxRelationshipBatch   = 50
+)
+
+// feedServer implements pb.FeedServiceServer on top of the same Service as
+// the REST handlers
+type feedServer struct {
+	pb.UnimplementedFeedServiceServer
+	getstreamSvc getstream.Service
+}
+
+func newFeedServer(getstreamSvc getstream.Service) *feedServer {
+	return &feedServer{getstreamSvc: getstreamSvc}
+}
+
+// Posts
+
+func (s *feedServer) AddPost(ctx context.Context, req *pb.AddPostRequest) (*pb.Activity, error) {
+	if req.UserSerial == "" || req.PostContent == "" || req.PostType == "" {
+		return nil, status.Error(codes.InvalidArgument, "user_serial, post_content, and post_type are mandatory")
+	}
+
+	resp, err := s.getstreamSvc.AddPostByUserSerial(ctx, req.UserSerial, req.PostContent, req.PostType)
+	if err != nil {
+		return nil, statusFromError(err)
+	}
+	return activityToProto(resp.Activity), nil
+}
+
+func (s *feedServer) GetPosts(ctx context.Context, req *pb.GetPostsRequest) (*pb.ActivityList, error) {
+	if req.UserSerial == "" {
+		return nil, status.Error(codes.InvalidArgument, "user_serial is mandatory")
+	}
+
+	resp, err := s.getstreamSvc.GetPostByUserSerial(ctx, req.ViewerSerial, req.UserSerial)
+	if err != nil {
+		return nil, statusFromError(err)
+	}
+	return activityListToProto(resp.Results), nil
+}
+
+func (s *feedServer) GetPostDetails(ctx context.Context, req *pb.GetPostsRequest) (*pb.EnrichedActivityList, error) {
+	if req.UserSerial == "" {
+		return nil, status.Error(codes.InvalidArgument, "user_serial is mandatory")
+	}
+
+	resp, err := s.getstreamSvc.GetPostDetailByUserSerial(ctx, req.ViewerSerial, req.UserSerial)
+	if err != nil {
+		return nil, statusFromError(err)
+	}
+	return enrichedActivityListToProto(resp.Results), nil
+}
+
+func (s *feedServer) EditPost(ctx context.Context, req *pb.EditPostRequest) (*pb.Activity, error) {
+	if req.UserSerial == "" || req.PostId == "" || req.PostContent == "" || req.PostType == "" {
+		return nil, status.Error(codes.InvalidArgument, "user_serial, post_id, post_content, and post_type are mandatory")
+	}
+
+	resp, err := s.getstreamSvc.EditPostByPostID(ctx, req.UserSerial, req.PostId, req.PostContent, req.PostType)
+	if err != nil {
+		return nil, statusFromError(err)
+	}
+	return activityToProto(resp.Activity), nil
+}
+
+func (s *feedServer) GetPostHistory(ctx context.Context, req *pb.GetPostHistoryRequest) (*pb.PostRevisionList, error) {
+	if req.PostId == "" {
+		return nil, status.Error(codes.InvalidArgument, "post_id is mandatory")
+	}
+
+	resp, err := s.getstreamSvc.GetPostHistoryByPostID(ctx, req.ViewerSerial, req.PostId)
+	if err != nil {
+		return nil, statusFromError(err)
+	}
+
+	revisions := make([]*pb.PostRevision, 0, len(resp))
+	for _, revision := range resp {
+		revisions = append(revisions, &pb.PostRevision{
+			PostId:   revision.PostID,
+			Post:     revision.Post,
+			PostType: revision.PostType,
+			Editor:   revision.Editor,
+			EditedAt: timestamppb.New(revision.EditedAt),
+		})
+	}
+	return &pb.PostRevisionList{Revisions: revisions}, nil
+}
+
+func (s *feedServer) DeletePost(ctx context.Context, req *pb.DeletePostRequest) (*emptypb.Empty, error) {
+	if req.UserSerial == "" || req.PostId == "" {
+		return nil, status.Error(codes.InvalidArgument, "user_serial and post_id are mandatory")
+	}
+
+	err := s.getstreamSvc.DeletePostByPostID(ctx, req.UserSerial, req.PostId)
+	if err != nil {
+		return nil, statusFromError(err)
+	}
+	return &emptypb.Empty{}, nil
+}
+
+// Timeline
+
+func (s *feedServer) GetTimeline(ctx context.Context, req *pb.UserRequest) (*pb.ActivityList, error) {
+	if req.UserSerial == "" {
+		return nil, status.Error(codes.InvalidArgument, "user_serial is mandatory")
+	}
+
+	resp, err := s.getstreamSvc.GetTimelineByUserSerial(ctx, req.UserSerial)
+	if err != nil {
+		return nil, statusFromError(err)
+	}
+	return activityListToProto(resp.Results), nil
+}
+
+func (s *feedServer) GetDetailTimeline(ctx context.Context, req *pb.UserRequest) (*pb.EnrichedActivityList, error) {
+	if req.UserSerial == "" {
+		return nil, status.Error(codes.InvalidArgument, "user_serial is mandatory")
+	}
+
+	resp, err := s.getstreamSvc.GetDetailTimelineByUserSerial(ctx, req.UserSerial)
+	if err != nil {
+		return nil, statusFromError(err)
+	}
+	return enrichedActivityListToProto(resp.Results), nil
+}
+
+// Follow graph
+
+func (s *feedServer) Follow(ctx context.Context, req *pb.FollowRequest) (*pb.FollowResponse, error) {
+	if req.OwnUserSerial == "" || req.TargetUserSerial == "" {
+		return nil, status.Error(codes.InvalidArgument, "own_user_serial and target_user_serial are mandatory")
+	}
+
+	request, err := s.getstreamSvc.Follow(ctx, req.OwnUserSerial, req.TargetUserSerial)
+	if err != nil {
+		return nil, statusFromError(err)
+	}
+
+	// Following a private user only creates a request
+	if request == nil {
+		return &pb.FollowResponse{}, nil
+	}
+	return &pb.FollowResponse{
+		PendingRequest: &pb.PendingFollowRequest{
+			Id:               request.ID,
+			OwnUserSerial:    request.OwnUserSerial,
+			TargetUserSerial: request.TargetUserSerial,
+			Status:           string(request.Status),
+			CreatedAt:        timestamppb.New(request.CreatedAt),
+		},
+	}, nil
+}
+
+func (s *feedServer) Unfollow(ctx context.Context, req *pb.FollowRequest) (*emptypb.Empty, error) {
+	if req.OwnUserSerial == "" || req.TargetUserSerial == "" {
+		return nil, status.Error(codes.InvalidArgument, "own_user_serial and target_user_serial are mandatory")
+	}
+
+	err := s.getstreamSvc.Unfollow(ctx, req.OwnUserSerial, req.TargetUserSerial)
+	if err != nil {
+		return nil, statusFromError(err)
+	}
+	return &emptypb.Empty{}, nil
+}
+
+func (s *feedServer) GetFollowers(ctx context.Context, req *pb.UserRequest) (*pb.FollowEdgeList, error) {
+	if req.UserSerial == "" {
+		return nil, status.Error(codes.InvalidArgument, "user_serial is mandatory")
+	}
+
+	resp, err := s.getstreamSvc.GetFeedFollowersByUserSerial(ctx, req.UserSerial)
+	if err != nil {
+		return nil, statusFromError(err)
+	}
+	return followEdgeListToProto(resp.Results), nil
+}
+
+func (s *feedServer) GetFollowing(ctx context.Context, req *pb.UserRequest) (*pb.FollowEdgeList, error) {
+	if req.UserSerial == "" {
+		return nil, status.Error(codes.InvalidArgument, "user_serial is mandatory")
+	}
+
+	resp, err := s.getstreamSvc.GetFollowedFeedsByUserSerial(ctx, req.UserSerial)
+	if err != nil {
+		return nil, statusFromError(err)
+	}
+	return followEdgeListToProto(resp.Results), nil
+}
+
+func (s *feedServer) GetFollowSuggestions(ctx context.Context, req *pb.GetFollowSuggestionsRequest) (*pb.FollowSuggestionList, error) {
+	if req.UserSerial == "" {
+		return nil, status.Error(codes.InvalidArgument, "user_serial is mandatory")
+	}
+
+	limit := defaultSuggestionLimit
+	if req.Limit > 0 {
+		limit = int(req.Limit)
+	}
+
+	resp, err := s.getstreamSvc.GetFollowSuggestionsByUserSerial(ctx, req.UserSerial, limit)
+	if err != nil {
+		return nil, statusFromError(err)
+	}
+
+	suggestions := make([]*pb.FollowSuggestion, 0, len(resp))
+	for _, suggestion := range resp {
+		var lastActivityAt *timestamppb.Timestamp
+		if suggestion.LastActivityAt != nil {
+			lastActivityAt = timestamppb.New(*suggestion.LastActivityAt)
+		}
+		suggestions = append(suggestions, &pb.FollowSuggestion{
+			UserSerial:     suggestion.UserSerial,
+			MutualCount:    int32(suggestion.MutualCount),
+			FollowsYou:     suggestion.FollowsYou,
+			LastActivityAt: lastActivityAt,
+		})
+	}
+	return &pb.FollowSuggestionList{Suggestions: suggestions}, nil
+}
+
+func (s *feedServer) GetRelationships(ctx context.Context, req *pb.GetRelationshipsRequest) (*pb.RelationshipList, error) {
+	if req.UserSerial == "" || len(req.OtherSerials) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "user_serial and other_serials are mandatory")
+	}
+	if len(req.OtherSerials) > maxRelationshipBatch {
+		return nil, status.Errorf(codes.InvalidArgument, "other_serials can't have more than %d users", maxRelationshipBatch)
+	}
+
+	resp, err := s.getstreamSvc.GetRelationships(ctx, req.UserSerial, req.OtherSerials)
+	if err != nil {
+		return nil, statusFromError(err)
+	}
+
+	relationships := make([]*pb.Relationship, 0, len(resp))
+	for _, relationship := range resp {
+		relationships = append(relationships, &pb.Relationship{
+			UserSerial:  relationship.UserSerial,
+			OtherSerial: relationship.OtherSerial,
+			Following:   relationship.Following,
+			FollowedBy:  relationship.FollowedBy,
+			Mutual:      relationship.Mutual,
+		})
+	}
+	return &pb.RelationshipList{Relationships: relationships}, nil
+}
+
+// Likes
+
+func (s *feedServer) AddLike(ctx context.Context, req *pb.AddLikeRequest) (*pb.Reaction, error) {
+	if req.LikerUserSerial == "" || req.PostId == "" {
+		return nil, status.Error(codes.InvalidArgument, "liker_user_serial and post_id are mandatory")
+	}
+
+	resp, err := s.getstreamSvc.AddLikeToPostID(ctx, req.LikerUserSerial, req.PostId)
+	if err != nil {
+		return nil, statusFromError(err)
+	}
+	return reactionToProto(resp.ID, resp.Kind, resp.ActivityID, resp.UserID, resp.Data), nil
+}
+
+func (s *feedServer) GetLikes(ctx context.Context, req *pb.GetLikesRequest) (*pb.ReactionList, error) {
+	if req.PostId == "" {
+		return nil, status.Error(codes.InvalidArgument, "post_id is mandatory")
+	}
+
+	limit := defaultLikeLimit
+	if req.Limit > 0 {
+		limit = int(req.Limit)
+	}
+
+	var resp *stream.FilterReactionResponse
+	var err error
+	if req.NextLikeId == "" {
+		resp, err = s.getstreamSvc.RetrieveLikeDetailOnPostID(ctx, req.PostId, limit)
+	} else {
+		resp, err = s.getstreamSvc.RetrieveLikeDetailOnPostIDWithPagination(ctx, req.PostId, req.NextLikeId, limit)
+	}
+	if err != nil {
+		return nil, statusFromError(err)
+	}
+
+	reactions := make([]*pb.Reaction, 0, len(resp.Results))
+	for _, reaction := range resp.Results {
+		reactions = append(reactions, reactionToProto(reaction.ID, reaction.Kind, reaction.ActivityID, reaction.UserID, reaction.Data))
+	}
+	return &pb.ReactionList{Reactions: reactions}, nil
+}
+
+func (s *feedServer) RemoveLike(ctx context.Context, req *pb.RemoveLikeRequest) (*emptypb.Empty, error) {
+	if req.ReactionId == "" {
+		return nil, status.Error(codes.InvalidArgument, "reaction_id is mandatory")
+	}
+
+	err := s.getstreamSvc.RemoveLikeByReactionID(ctx, req.ReactionId)
+	if err != nil {
+		return nil, statusFromError(err)
+	}
+	return &emptypb.Empty{}, nil
+}
+
+// statusFromError maps errors returned by the Service to a gRPC status, the
+// same way the REST handlers map them to an HTTP status code
+func statusFromError(err error) error {
+	code := codes.Internal
+	switch {
+	case errors.Is(err, getstream.ErrPostNotFound),
+		errors.Is(err, getstream.ErrFollowRequestNotFound):
+		code = codes.NotFound
+	case errors.Is(err, getstream.ErrNotPostOwner),
+		errors.Is(err, getstream.ErrBlocked),
+		errors.Is(err, getstream.ErrPrivateAccount):
+		code = codes.PermissionDenied
+	case errors.Is(err, getstream.ErrFollowRequestNotPending):
+		code = codes.FailedPrecondition
+	case errors.Is(err, getstream.ErrSameUser):
+		code = codes.InvalidArgument
+	default:
+		switch getstream.ErrorClass(err) {
+		case getstream.ErrorClassCircuitOpen, getstream.ErrorClassNetwork:
+			code = codes.Unavailable
+		case getstream.ErrorClassRateLimited:
+			code = codes.ResourceExhausted
+		case getstream.ErrorClassTimeout:
+			code = codes.DeadlineExceeded
+		}
+	}
+	return status.Error(code, err.Error())
+}
+
+func activityToProto(activity stream.Activity) *pb.Activity {
+	return &pb.Activity{
+		Id:        activity.ID,
+		Actor:     activity.Actor,
+		Verb:      activity.Verb,
+		Object:    activity.Object,
+		ForeignId: activity.ForeignID,
+		Time:      timestamppb.New(activity.Time.Time),
+		To:        activity.To,
+		Extra:     structToProto(activity.Extra),
+	}
+}
+
+func activityListToProto(activities []stream.Activity) *pb.ActivityList {
+	list := &pb.ActivityList{Activities: make([]*pb.Activity, 0, len(activities))}
+	for _, activity := range activities {
+		list.Activities = append(list.Activities, activityToProto(activity))
+	}
+	return list
+}
+
+func enrichedActivityListToProto(activities []stream.EnrichedActivity) *pb.EnrichedActivityList {
+	list := &pb.EnrichedActivityList{Activities: make([]*pb.EnrichedActivity, 0, len(activities))}
+	for _, activity := range activities {
+		reactionCounts := map[string]int32{}
+		for kind, count := range activity.ReactionCounts {
+			reactionCounts[kind] = int32(count)
+		}
+
+		list.Activities = append(list.Activities, &pb.EnrichedActivity{
+			Activity: &pb.Activity{
+				Id:        activity.ID,
+				Actor:     activity.Actor.ID,
+				Verb:      activity.Verb,
+				Object:    activity.Object.ID,
+				ForeignId: activity.ForeignID,
+				Time:      timestamppb.New(activity.Time.Time),
+				To:        activity.To,
+				Extra:     structToProto(activity.Extra),
+			},
+			ReactionCounts:  reactionCounts,
+			OwnReactions:    enrichedReactionsToProto(activity.OwnReactions),
+			LatestReactions: enrichedReactionsToProto(activity.LatestReactions),
+		})
+	}
+	return list
+}
+
+func enrichedReactionsToProto(reactionsByKind map[string][]*stream.EnrichedReaction) map[string]*pb.ReactionList {
+	lists := map[string]*pb.ReactionList{}
+	for kind, reactions := range reactionsByKind {
+		list := &pb.ReactionList{Reactions: make([]*pb.Reaction, 0, len(reactions))}
+		for _, reaction := range reactions {
+			list.Reactions = append(list.Reactions, reactionToProto(reaction.ID, reaction.Kind, reaction.ActivityID, reaction.UserID, reaction.Data))
+		}
+		lists[kind] = list
+	}
+	return lists
+}
+
+func reactionToProto(id, kind, activityID, userID string, data map[string]interface{}) *pb.Reaction {
+	return &pb.Reaction{
+		Id:         id,
+		Kind:       kind,
+		ActivityId: activityID,
+		UserId:     userID,
+		Data:       structToProto(data),
+	}
+}
+
+func followEdgeListToProto(followers []stream.Follower) *pb.FollowEdgeList {
+	list := &pb.FollowEdgeList{Edges: make([]*pb.FollowEdge, 0, len(followers))}
+	for _, follower := range followers {
+		list.Edges = append(list.Edges, &pb.FollowEdge{FeedId: follower.FeedID, TargetId: follower.TargetID})
+	}
+	return list
+}
+
+// structToProto converts custom fields, dropping them if one of the values
+// has no JSON equivalent
+func structToProto(fields map[string]interface{}) *structpb.Struct {
+	if len(fields) == 0 {
+		return nil
+	}
+	s, err := structpb.NewStruct(fields)
+	if err != nil {
+		return nil
+	}
+	return s
+}
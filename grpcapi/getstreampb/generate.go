@@ -0,0 +1,11 @@
+// Package getstreampb holds the protobuf messages and gRPC stubs generated
+// from getstream.proto
+package getstreampb
+
+// Stubs are generated with protoc 29.3, protoc-gen-go v1.36.5 and
+// protoc-gen-go-grpc v1.5.1, matching the protobuf and grpc modules. Run
+// `go generate ./grpcapi/...` with protoc 29.3 on the PATH.
+//go:generate sh -c "protoc --version | grep -qx 'libprotoc 29.3' || { echo 'getstreampb needs protoc 29.3' >&2; exit 1; }"
+//go:generate go install google.golang.org/protobuf/cmd/protoc-gen-go@v1.36.5
+//go:generate go install google.golang.org/grpc/cmd/protoc-gen-go-grpc@v1.5.1
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative getstream.proto
@@ -0,0 +1,147 @@
+package grpcapi
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	grpchealth "google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+
+	"github.com/wisnuanggoro/go-getstream/getstream"
+	pb "github.com/wisnuanggoro/go-getstream/grpcapi/getstreampb"
+	"github.com/wisnuanggoro/go-getstream/logging"
+	"github.com/wisnuanggoro/go-getstream/tenant"
+	"github.com/wisnuanggoro/go-getstream/uid"
+)
+
+// Server is the gRPC server of this service, exposing the FeedService next
+// to the standard health and reflection services
+type Server struct {
+	addr            string
+	grpcServer      *grpc.Server
+	health          *grpchealth.Server
+	shutdownTimeout time.Duration
+}
+
+// New serves every tenant of registry with getstreamSvc, calls name their
+// tenant in the tenantHeader metadata or are resolved by their authority
+func New(addr string, getstreamSvc getstream.Service, registry *tenant.Registry, tenantHeader string, shutdownTimeout time.Duration) *Server {
+	grpcServer := grpc.NewServer(grpc.ChainUnaryInterceptor(recoverPanic, requestID, resolveTenant(registry, strings.ToLower(tenantHeader))))
+
+	health := grpchealth.NewServer()
+	pb.RegisterFeedServiceServer(grpcServer, newFeedServer(getstreamSvc))
+	healthpb.RegisterHealthServer(grpcServer, health)
+	reflection.Register(grpcServer)
+
+	return &Server{
+		addr:            addr,
+		grpcServer:      grpcServer,
+		health:          health,
+		shutdownTimeout: shutdownTimeout,
+	}
+}
+
+// Run serves until ctx is cancelled, then waits up to the shutdown timeout
+// for in-flight calls to finish
+func (s *Server) Run(ctx context.Context) error {
+	listener, err := net.Listen("tcp", s.addr)
+	if err != nil {
+		return err
+	}
+
+	serveErr := make(chan error, 1)
+	go func() {
+		slog.Info("grpc server listening", slog.String("addr", s.addr))
+		serveErr <- s.grpcServer.Serve(listener)
+	}()
+
+	select {
+	case err := <-serveErr:
+		return err
+	case <-ctx.Done():
+	}
+
+	slog.Info("shutting down grpc server, draining calls", slog.Duration("timeout", s.shutdownTimeout))
+	s.health.Shutdown()
+
+	stopped := make(chan struct{})
+	go func() {
+		s.grpcServer.GracefulStop()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+	case <-time.After(s.shutdownTimeout):
+		s.grpcServer.Stop()
+	}
+	return nil
+}
+
+// requestID reuses the caller's x-request-id metadata or generates one, like
+// the HTTP middleware does with the X-Request-ID header
+func requestID(ctx context.Context, req interface{}, _ *grpc.UnaryServerInfo, next grpc.UnaryHandler) (interface{}, error) {
+	id := ""
+	if values := metadata.ValueFromIncomingContext(ctx, "x-request-id"); len(values) > 0 {
+		id = values[0]
+	}
+	if !logging.ValidRequestID(id) {
+		id = uid.New()
+	}
+
+	grpc.SetHeader(ctx, metadata.Pairs("x-request-id", id))
+	return next(logging.WithRequestID(ctx, id), req)
+}
+
+// resolveTenant adds the tenant of the call to its context, like the HTTP
+// middleware does. Health checks serve the whole process.
+func resolveTenant(registry *tenant.Registry, key string) grpc.UnaryServerInterceptor {
+	feedService := "/" + pb.FeedService_ServiceDesc.ServiceName + "/"
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, next grpc.UnaryHandler) (interface{}, error) {
+		if !strings.HasPrefix(info.FullMethod, feedService) {
+			return next(ctx, req)
+		}
+
+		id, authority := "", ""
+		if values := metadata.ValueFromIncomingContext(ctx, key); len(values) > 0 {
+			id = values[0]
+		}
+		if values := metadata.ValueFromIncomingContext(ctx, ":authority"); len(values) > 0 {
+			authority = values[0]
+		}
+
+		t, err := registry.Resolve(id, authority)
+		if err != nil {
+			switch {
+			case errors.Is(err, tenant.ErrTenantMismatch):
+				return nil, status.Error(codes.PermissionDenied, key+" metadata doesn't match the tenant of this authority")
+			case id != "":
+				return nil, status.Error(codes.NotFound, "unknown tenant "+id)
+			default:
+				return nil, status.Error(codes.InvalidArgument, key+" metadata is mandatory on this authority")
+			}
+		}
+		return next(tenant.WithID(ctx, t.ID), req)
+	}
+}
+
+// recoverPanic turns a panicking call into an Internal error instead of
+// crashing the process, like gin.Recovery does for HTTP
+func recoverPanic(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, next grpc.UnaryHandler) (resp interface{}, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			slog.ErrorContext(ctx, "grpc call panicked", slog.String("method", info.FullMethod), slog.Any("panic", r))
+			err = status.Error(codes.Internal, "internal error")
+		}
+	}()
+	return next(ctx, req)
+}
@@ -0,0 +1,572 @@
+package grpcapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	stream "gopkg.in/GetStream/stream-go2.v3"
+
+	"github.com/wisnuanggoro/go-getstream/getstream"
+	pb "github.com/wisnuanggoro/go-getstream/grpcapi/getstreampb"
+	"github.com/wisnuanggoro/go-getstream/tenant"
+)
+
+// recordingService records each call as `<method> <tenant> <args...>` and
+// fails them with err when set
+type recordingService struct {
+	getstream.Service
+	err error
+
+	mu    sync.Mutex
+	calls []string
+}
+
+func (s *recordingService) record(ctx context.Context, method string, args ...interface{}) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	call := []string{method, tenant.ID(ctx)}
+	for _, arg := range args {
+		call = append(call, fmt.Sprint(arg))
+	}
+	s.calls = append(s.calls, strings.Join(call, " "))
+	return s.err
+}
+
+func (s *recordingService) lastCall() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.calls) == 0 {
+		return ""
+	}
+	return s.calls[len(s.calls)-1]
+}
+
+func testActivity(id string) stream.Activity {
+	var activity stream.Activity
+	activity.ID = id
+	activity.Actor = "user:1"
+	activity.Verb = "post"
+	activity.Object = "1"
+	activity.Extra = map[string]interface{}{"post": "hello"}
+	return activity
+}
+
+func testReaction(id string) stream.Reaction {
+	var reaction stream.Reaction
+	reaction.ID = id
+	reaction.Kind = "like"
+	reaction.ActivityID = "activity-1"
+	reaction.UserID = "2"
+	return reaction
+}
+
+func (s *recordingService) AddPostByUserSerial(ctx context.Context, userSerial, postContent, postType string) (*stream.AddActivityResponse, error) {
+	if err := s.record(ctx, "AddPost", userSerial, postContent, postType); err != nil {
+		return nil, err
+	}
+	resp := &stream.AddActivityResponse{}
+	resp.Activity = testActivity("activity-1")
+	return resp, nil
+}
+
+func (s *recordingService) GetPostByUserSerial(ctx context.Context, viewerUserSerial, userSerial string) (*stream.FlatFeedResponse, error) {
+	if err := s.record(ctx, "GetPosts", viewerUserSerial, userSerial); err != nil {
+		return nil, err
+	}
+	return &stream.FlatFeedResponse{Results: []stream.Activity{testActivity("activity-1")}}, nil
+}
+
+func (s *recordingService) GetPostDetailByUserSerial(ctx context.Context, viewerUserSerial, userSerial string) (*stream.EnrichedFlatFeedResponse, error) {
+	if err := s.record(ctx, "GetPostDetails", viewerUserSerial, userSerial); err != nil {
+		return nil, err
+	}
+	return &stream.EnrichedFlatFeedResponse{Results: []stream.EnrichedActivity{{}}}, nil
+}
+
+func (s *recordingService) EditPostByPostID(ctx context.Context, userSerial, postID, postContent, postType string) (*stream.UpdateActivityResponse, error) {
+	if err := s.record(ctx, "EditPost", userSerial, postID, postContent, postType); err != nil {
+		return nil, err
+	}
+	resp := &stream.UpdateActivityResponse{}
+	resp.Activity = testActivity(postID)
+	return resp, nil
+}
+
+func (s *recordingService) GetPostHistoryByPostID(ctx context.Context, viewerUserSerial, postID string) ([]getstream.PostRevision, error) {
+	if err := s.record(ctx, "GetPostHistory", viewerUserSerial, postID); err != nil {
+		return nil, err
+	}
+	return []getstream.PostRevision{{PostID: postID, Post: "first", Editor: "1", EditedAt: time.Now()}}, nil
+}
+
+func (s *recordingService) DeletePostByPostID(ctx context.Context, userSerial, postID string) error {
+	return s.record(ctx, "DeletePost", userSerial, postID)
+}
+
+func (s *recordingService) GetTimelineByUserSerial(ctx context.Context, userSerial string) (*stream.FlatFeedResponse, error) {
+	if err := s.record(ctx, "GetTimeline", userSerial); err != nil {
+		return nil, err
+	}
+	return &stream.FlatFeedResponse{Results: []stream.Activity{testActivity("activity-1")}}, nil
+}
+
+func (s *recordingService) GetDetailTimelineByUserSerial(ctx context.Context, userSerial string) (*stream.EnrichedFlatFeedResponse, error) {
+	if err := s.record(ctx, "GetDetailTimeline", userSerial); err != nil {
+		return nil, err
+	}
+	return &stream.EnrichedFlatFeedResponse{Results: []stream.EnrichedActivity{{}}}, nil
+}
+
+func (s *recordingService) Follow(ctx context.Context, ownUserSerial, targetUserSerial string) (*getstream.FollowRequest, error) {
+	if err := s.record(ctx, "Follow", ownUserSerial, targetUserSerial); err != nil {
+		return nil, err
+	}
+	return &getstream.FollowRequest{ID: "request-1", OwnUserSerial: ownUserSerial, TargetUserSerial: targetUserSerial, Status: getstream.FollowRequestPending}, nil
+}
+
+func (s *recordingService) Unfollow(ctx context.Context, ownUserSerial, targetUserSerial string) error {
+	return s.record(ctx, "Unfollow", ownUserSerial, targetUserSerial)
+}
+
+func (s *recordingService) GetFeedFollowersByUserSerial(ctx context.Context, userSerial string) (*stream.FollowersResponse, error) {
+	if err := s.record(ctx, "GetFollowers", userSerial); err != nil {
+		return nil, err
+	}
+	return &stream.FollowersResponse{Results: []stream.Follower{{FeedID: "timeline:2", TargetID: "user:" + userSerial}}}, nil
+}
+
+func (s *recordingService) GetFollowedFeedsByUserSerial(ctx context.Context, userSerial string) (*stream.FollowingResponse, error) {
+	if err := s.record(ctx, "GetFollowing", userSerial); err != nil {
+		return nil, err
+	}
+	return &stream.FollowingResponse{Results: []stream.Follower{{FeedID: "timeline:" + userSerial, TargetID: "user:2"}}}, nil
+}
+
+func (s *recordingService) GetFollowSuggestionsByUserSerial(ctx context.Context, userSerial string, limit int) ([]getstream.FollowSuggestion, error) {
+	if err := s.record(ctx, "GetFollowSuggestions", userSerial, limit); err != nil {
+		return nil, err
+	}
+	return []getstream.FollowSuggestion{{UserSerial: "3", MutualCount: 2}}, nil
+}
+
+func (s *recordingService) GetRelationships(ctx context.Context, userSerial string, otherSerials []string) ([]getstream.Relationship, error) {
+	if err := s.record(ctx, "GetRelationships", userSerial, otherSerials); err != nil {
+		return nil, err
+	}
+	relationships := []getstream.Relationship{}
+	for _, otherSerial := range otherSerials {
+		relationships = append(relationships, getstream.Relationship{UserSerial: userSerial, OtherSerial: otherSerial, Following: true})
+	}
+	return relationships, nil
+}
+
+func (s *recordingService) AddLikeToPostID(ctx context.Context, likerUserSerial, postID string) (*stream.Reaction, error) {
+	if err := s.record(ctx, "AddLike", likerUserSerial, postID); err != nil {
+		return nil, err
+	}
+	reaction := testReaction("reaction-1")
+	return &reaction, nil
+}
+
+func (s *recordingService) RetrieveLikeDetailOnPostID(ctx context.Context, postID string, limit int) (*stream.FilterReactionResponse, error) {
+	if err := s.record(ctx, "GetLikes", postID, limit); err != nil {
+		return nil, err
+	}
+	return &stream.FilterReactionResponse{Results: []stream.Reaction{testReaction("reaction-1")}}, nil
+}
+
+func (s *recordingService) RetrieveLikeDetailOnPostIDWithPagination(ctx context.Context, postID, nextLikeID string, limit int) (*stream.FilterReactionResponse, error) {
+	if err := s.record(ctx, "GetLikesAfter", postID, nextLikeID, limit); err != nil {
+		return nil, err
+	}
+	return &stream.FilterReactionResponse{Results: []stream.Reaction{testReaction("reaction-2")}}, nil
+}
+
+func (s *recordingService) RemoveLikeByReactionID(ctx context.Context, reactionID string) error {
+	return s.record(ctx, "RemoveLike", reactionID)
+}
+
+// testRegistry has two tenants served on their own authority, and globex
+// also reachable on any other authority by naming it
+func testRegistry(t *testing.T) *tenant.Registry {
+	registry, err := tenant.NewRegistry([]tenant.Tenant{
+		{ID: "acme", APIKey: "key", APISecret: "secret", Hosts: []string{"acme.example"}},
+		{ID: "globex", APIKey: "key", APISecret: "secret", Hosts: []string{"globex.example"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return registry
+}
+
+// dial serves svc over an in-memory listener and connects to it as authority
+func dial(t *testing.T, svc getstream.Service, authority string) *grpc.ClientConn {
+	listener := bufconn.Listen(1 << 20)
+	server := New("", svc, testRegistry(t), "X-Tenant-ID", time.Second)
+	go server.grpcServer.Serve(listener)
+	t.Cleanup(server.grpcServer.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///"+authority,
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func TestResolveTenant(t *testing.T) {
+	tests := []struct {
+		name      string
+		authority string
+		tenantID  string
+		// wantTenant is the tenant the call is served by, when it succeeds
+		wantTenant string
+		wantCode   codes.Code
+	}{
+		{name: "tenant of the authority", authority: "acme.example", wantTenant: "acme"},
+		{name: "authority matching the metadata", authority: "acme.example", tenantID: "acme", wantTenant: "acme"},
+		{name: "metadata on a shared authority", authority: "api.example", tenantID: "globex", wantTenant: "globex"},
+		{name: "metadata naming another tenant", authority: "acme.example", tenantID: "globex", wantCode: codes.PermissionDenied},
+		{name: "unknown tenant", authority: "api.example", tenantID: "initech", wantCode: codes.NotFound},
+		{name: "missing tenant", authority: "api.example", wantCode: codes.InvalidArgument},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := &recordingService{}
+			client := pb.NewFeedServiceClient(dial(t, svc, tt.authority))
+
+			ctx := context.Background()
+			if tt.tenantID != "" {
+				ctx = metadata.AppendToOutgoingContext(ctx, "x-tenant-id", tt.tenantID)
+			}
+			_, err := client.GetTimeline(ctx, &pb.UserRequest{UserSerial: "1"})
+			if code := status.Code(err); code != tt.wantCode {
+				t.Fatalf("code = %v, want %v: %v", code, tt.wantCode, err)
+			}
+			if tt.wantCode != codes.OK {
+				if call := svc.lastCall(); call != "" {
+					t.Errorf("service called with %q", call)
+				}
+				return
+			}
+			if want := "GetTimeline " + tt.wantTenant + " 1"; svc.lastCall() != want {
+				t.Errorf("call = %q, want %q", svc.lastCall(), want)
+			}
+		})
+	}
+}
+
+func TestHealthWithoutTenant(t *testing.T) {
+	client := healthpb.NewHealthClient(dial(t, &recordingService{}, "api.example"))
+
+	resp, err := client.Check(context.Background(), &healthpb.HealthCheckRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Status != healthpb.HealthCheckResponse_SERVING {
+		t.Errorf("status = %v, want SERVING", resp.Status)
+	}
+}
+
+func TestFeedServiceRoundTrip(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name     string
+		call     func(client pb.FeedServiceClient) error
+		wantCall string
+	}{
+		{
+			name: "AddPost",
+			call: func(client pb.FeedServiceClient) error {
+				resp, err := client.AddPost(ctx, &pb.AddPostRequest{UserSerial: "1", PostContent: "hello", PostType: "text"})
+				if err == nil && (resp.Id != "activity-1" || resp.Extra.AsMap()["post"] != "hello") {
+					return fmt.Errorf("activity = %v", resp)
+				}
+				return err
+			},
+			wantCall: "AddPost acme 1 hello text",
+		},
+		{
+			name: "GetPosts",
+			call: func(client pb.FeedServiceClient) error {
+				resp, err := client.GetPosts(ctx, &pb.GetPostsRequest{UserSerial: "1", ViewerSerial: "2"})
+				if err == nil && len(resp.Activities) != 1 {
+					return fmt.Errorf("activities = %v", resp.Activities)
+				}
+				return err
+			},
+			wantCall: "GetPosts acme 2 1",
+		},
+		{
+			name: "GetPostDetails",
+			call: func(client pb.FeedServiceClient) error {
+				resp, err := client.GetPostDetails(ctx, &pb.GetPostsRequest{UserSerial: "1", ViewerSerial: "2"})
+				if err == nil && len(resp.Activities) != 1 {
+					return fmt.Errorf("activities = %v", resp.Activities)
+				}
+				return err
+			},
+			wantCall: "GetPostDetails acme 2 1",
+		},
+		{
+			name: "EditPost",
+			call: func(client pb.FeedServiceClient) error {
+				resp, err := client.EditPost(ctx, &pb.EditPostRequest{UserSerial: "1", PostId: "activity-1", PostContent: "edited", PostType: "text"})
+				if err == nil && resp.Id != "activity-1" {
+					return fmt.Errorf("activity = %v", resp)
+				}
+				return err
+			},
+			wantCall: "EditPost acme 1 activity-1 edited text",
+		},
+		{
+			name: "GetPostHistory",
+			call: func(client pb.FeedServiceClient) error {
+				resp, err := client.GetPostHistory(ctx, &pb.GetPostHistoryRequest{PostId: "activity-1", ViewerSerial: "2"})
+				if err == nil && (len(resp.Revisions) != 1 || resp.Revisions[0].Post != "first") {
+					return fmt.Errorf("revisions = %v", resp.Revisions)
+				}
+				return err
+			},
+			wantCall: "GetPostHistory acme 2 activity-1",
+		},
+		{
+			name: "DeletePost",
+			call: func(client pb.FeedServiceClient) error {
+				_, err := client.DeletePost(ctx, &pb.DeletePostRequest{UserSerial: "1", PostId: "activity-1"})
+				return err
+			},
+			wantCall: "DeletePost acme 1 activity-1",
+		},
+		{
+			name: "GetTimeline",
+			call: func(client pb.FeedServiceClient) error {
+				resp, err := client.GetTimeline(ctx, &pb.UserRequest{UserSerial: "1"})
+				if err == nil && len(resp.Activities) != 1 {
+					return fmt.Errorf("activities = %v", resp.Activities)
+				}
+				return err
+			},
+			wantCall: "GetTimeline acme 1",
+		},
+		{
+			name: "GetDetailTimeline",
+			call: func(client pb.FeedServiceClient) error {
+				resp, err := client.GetDetailTimeline(ctx, &pb.UserRequest{UserSerial: "1"})
+				if err == nil && len(resp.Activities) != 1 {
+					return fmt.Errorf("activities = %v", resp.Activities)
+				}
+				return err
+			},
+			wantCall: "GetDetailTimeline acme 1",
+		},
+		{
+			name: "Follow",
+			call: func(client pb.FeedServiceClient) error {
+				resp, err := client.Follow(ctx, &pb.FollowRequest{OwnUserSerial: "2", TargetUserSerial: "1"})
+				if err == nil && resp.PendingRequest.GetId() != "request-1" {
+					return fmt.Errorf("pending request = %v", resp.PendingRequest)
+				}
+				return err
+			},
+			wantCall: "Follow acme 2 1",
+		},
+		{
+			name: "Unfollow",
+			call: func(client pb.FeedServiceClient) error {
+				_, err := client.Unfollow(ctx, &pb.FollowRequest{OwnUserSerial: "2", TargetUserSerial: "1"})
+				return err
+			},
+			wantCall: "Unfollow acme 2 1",
+		},
+		{
+			name: "GetFollowers",
+			call: func(client pb.FeedServiceClient) error {
+				resp, err := client.GetFollowers(ctx, &pb.UserRequest{UserSerial: "1"})
+				if err == nil && (len(resp.Edges) != 1 || resp.Edges[0].FeedId != "timeline:2") {
+					return fmt.Errorf("edges = %v", resp.Edges)
+				}
+				return err
+			},
+			wantCall: "GetFollowers acme 1",
+		},
+		{
+			name: "GetFollowing",
+			call: func(client pb.FeedServiceClient) error {
+				resp, err := client.GetFollowing(ctx, &pb.UserRequest{UserSerial: "1"})
+				if err == nil && (len(resp.Edges) != 1 || resp.Edges[0].TargetId != "user:2") {
+					return fmt.Errorf("edges = %v", resp.Edges)
+				}
+				return err
+			},
+			wantCall: "GetFollowing acme 1",
+		},
+		{
+			name: "GetFollowSuggestions",
+			call: func(client pb.FeedServiceClient) error {
+				resp, err := client.GetFollowSuggestions(ctx, &pb.GetFollowSuggestionsRequest{UserSerial: "1"})
+				if err == nil && (len(resp.Suggestions) != 1 || resp.Suggestions[0].MutualCount != 2) {
+					return fmt.Errorf("suggestions = %v", resp.Suggestions)
+				}
+				return err
+			},
+			wantCall: fmt.Sprintf("GetFollowSuggestions acme 1 %d", defaultSuggestionLimit),
+		},
+		{
+			name: "GetRelationships",
+			call: func(client pb.FeedServiceClient) error {
+				resp, err := client.GetRelationships(ctx, &pb.GetRelationshipsRequest{UserSerial: "1", OtherSerials: []string{"2", "3"}})
+				if err == nil && len(resp.Relationships) != 2 {
+					return fmt.Errorf("relationships = %v", resp.Relationships)
+				}
+				return err
+			},
+			wantCall: "GetRelationships acme 1 [2 3]",
+		},
+		{
+			name: "AddLike",
+			call: func(client pb.FeedServiceClient) error {
+				resp, err := client.AddLike(ctx, &pb.AddLikeRequest{LikerUserSerial: "2", PostId: "activity-1"})
+				if err == nil && resp.Id != "reaction-1" {
+					return fmt.Errorf("reaction = %v", resp)
+				}
+				return err
+			},
+			wantCall: "AddLike acme 2 activity-1",
+		},
+		{
+			name: "GetLikes",
+			call: func(client pb.FeedServiceClient) error {
+				resp, err := client.GetLikes(ctx, &pb.GetLikesRequest{PostId: "activity-1", Limit: 5})
+				if err == nil && (len(resp.Reactions) != 1 || resp.Reactions[0].Id != "reaction-1") {
+					return fmt.Errorf("reactions = %v", resp.Reactions)
+				}
+				return err
+			},
+			wantCall: "GetLikes acme activity-1 5",
+		},
+		{
+			name: "GetLikes after a like",
+			call: func(client pb.FeedServiceClient) error {
+				resp, err := client.GetLikes(ctx, &pb.GetLikesRequest{PostId: "activity-1", NextLikeId: "reaction-1"})
+				if err == nil && (len(resp.Reactions) != 1 || resp.Reactions[0].Id != "reaction-2") {
+					return fmt.Errorf("reactions = %v", resp.Reactions)
+				}
+				return err
+			},
+			wantCall: fmt.Sprintf("GetLikesAfter acme activity-1 reaction-1 %d", defaultLikeLimit),
+		},
+		{
+			name: "RemoveLike",
+			call: func(client pb.FeedServiceClient) error {
+				_, err := client.RemoveLike(ctx, &pb.RemoveLikeRequest{ReactionId: "reaction-1"})
+				return err
+			},
+			wantCall: "RemoveLike acme reaction-1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := &recordingService{}
+			client := pb.NewFeedServiceClient(dial(t, svc, "acme.example"))
+
+			if err := tt.call(client); err != nil {
+				t.Fatal(err)
+			}
+			if call := svc.lastCall(); call != tt.wantCall {
+				t.Errorf("call = %q, want %q", call, tt.wantCall)
+			}
+
+			// Errors of the Service reach the client as a status
+			svc.err = getstream.ErrPostNotFound
+			if err := tt.call(client); status.Code(err) != codes.NotFound {
+				t.Errorf("failed call: code = %v, want NotFound", status.Code(err))
+			}
+		})
+	}
+}
+
+func TestFeedServiceValidatesRequests(t *testing.T) {
+	svc := &recordingService{}
+	client := pb.NewFeedServiceClient(dial(t, svc, "acme.example"))
+	ctx := context.Background()
+
+	others := make([]string, maxRelationshipBatch+1)
+	for i := range others {
+		others[i] = fmt.Sprint(i)
+	}
+	calls := map[string]func() error{
+		"AddPost without content": func() error {
+			_, err := client.AddPost(ctx, &pb.AddPostRequest{UserSerial: "1", PostType: "text"})
+			return err
+		},
+		"Follow without target": func() error {
+			_, err := client.Follow(ctx, &pb.FollowRequest{OwnUserSerial: "1"})
+			return err
+		},
+		"GetRelationships of too many users": func() error {
+			_, err := client.GetRelationships(ctx, &pb.GetRelationshipsRequest{UserSerial: "1", OtherSerials: others})
+			return err
+		},
+	}
+	for name, call := range calls {
+		if err := call(); status.Code(err) != codes.InvalidArgument {
+			t.Errorf("%s: code = %v, want InvalidArgument", name, status.Code(err))
+		}
+	}
+	if call := svc.lastCall(); call != "" {
+		t.Errorf("service called with %q", call)
+	}
+}
+
+func TestStatusFromError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want codes.Code
+	}{
+		{name: "post not found", err: getstream.ErrPostNotFound, want: codes.NotFound},
+		{name: "follow request not found", err: getstream.ErrFollowRequestNotFound, want: codes.NotFound},
+		{name: "not the owner", err: getstream.ErrNotPostOwner, want: codes.PermissionDenied},
+		{name: "blocked", err: getstream.ErrBlocked, want: codes.PermissionDenied},
+		{name: "private account", err: fmt.Errorf("get posts: %w", getstream.ErrPrivateAccount), want: codes.PermissionDenied},
+		{name: "follow request closed", err: getstream.ErrFollowRequestNotPending, want: codes.FailedPrecondition},
+		{name: "same user", err: getstream.ErrSameUser, want: codes.InvalidArgument},
+		{name: "circuit open", err: getstream.ErrCircuitOpen, want: codes.Unavailable},
+		{name: "timeout", err: context.DeadlineExceeded, want: codes.DeadlineExceeded},
+		{name: "rate limited", err: stream.APIError{StatusCode: 429}, want: codes.ResourceExhausted},
+		{name: "anything else", err: errors.New("boom"), want: codes.Internal},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := statusFromError(tt.err)
+			if code := status.Code(err); code != tt.want {
+				t.Errorf("code = %v, want %v", code, tt.want)
+			}
+			if msg := status.Convert(err).Message(); msg != tt.err.Error() {
+				t.Errorf("message = %q, want %q", msg, tt.err.Error())
+			}
+		})
+	}
+}
@@ -0,0 +1,108 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+func (h *handler) BlockUser(c *gin.Context) {
+	ownUserSerial := c.Query("ownUserSerial")
+	targetUserSerial := c.Query("targetUserSerial")
+	if ownUserSerial == "" || targetUserSerial == "" {
+		AddResponseToContext(c, http.StatusBadRequest, "ownUserSerial and targetUserSerial are mandatory", nil)
+		return
+	}
+
+	err := h.getstreamSvc.BlockUser(c.Request.Context(), ownUserSerial, targetUserSerial)
+	if err != nil {
+		AddResponseToContext(c, statusCodeFromError(err), err.Error(), nil)
+		return
+	}
+
+	AddResponseToContext(c, http.StatusOK, fmt.Sprintf("%s has successfully blocked %s!", ownUserSerial, targetUserSerial), nil)
+}
+
+func (h *handler) UnblockUser(c *gin.Context) {
+	ownUserSerial := c.Query("ownUserSerial")
+	targetUserSerial := c.Query("targetUserSerial")
+	if ownUserSerial == "" || targetUserSerial == "" {
+		AddResponseToContext(c, http.StatusBadRequest, "ownUserSerial and targetUserSerial are mandatory", nil)
+		return
+	}
+
+	err := h.getstreamSvc.UnblockUser(c.Request.Context(), ownUserSerial, targetUserSerial)
+	if err != nil {
+		AddResponseToContext(c, statusCodeFromError(err), err.Error(), nil)
+		return
+	}
+
+	AddResponseToContext(c, http.StatusOK, fmt.Sprintf("%s has successfully unblocked %s!", ownUserSerial, targetUserSerial), nil)
+}
+
+func (h *handler) GetBlockedUsersByUserSerial(c *gin.Context) {
+	userSerial := c.Param("userSerial")
+	if userSerial == "" {
+		AddResponseToContext(c, http.StatusBadRequest, "userSerial is mandatory", nil)
+		return
+	}
+
+	resp, err := h.getstreamSvc.GetBlockedUsersByUserSerial(c.Request.Context(), userSerial)
+	if err != nil {
+		AddResponseToContext(c, statusCodeFromError(err), err.Error(), nil)
+		return
+	}
+
+	AddResponseToContext(c, http.StatusOK, "Success", resp)
+}
+
+func (h *handler) MuteUser(c *gin.Context) {
+	ownUserSerial := c.Query("ownUserSerial")
+	targetUserSerial := c.Query("targetUserSerial")
+	if ownUserSerial == "" || targetUserSerial == "" {
+		AddResponseToContext(c, http.StatusBadRequest, "ownUserSerial and targetUserSerial are mandatory", nil)
+		return
+	}
+
+	err := h.getstreamSvc.MuteUser(c.Request.Context(), ownUserSerial, targetUserSerial)
+	if err != nil {
+		AddResponseToContext(c, statusCodeFromError(err), err.Error(), nil)
+		return
+	}
+
+	AddResponseToContext(c, http.StatusOK, fmt.Sprintf("%s has successfully muted %s!", ownUserSerial, targetUserSerial), nil)
+}
+
+func (h *handler) UnmuteUser(c *gin.Context) {
+	ownUserSerial := c.Query("ownUserSerial")
+	targetUserSerial := c.Query("targetUserSerial")
+	if ownUserSerial == "" || targetUserSerial == "" {
+		AddResponseToContext(c, http.StatusBadRequest, "ownUserSerial and targetUserSerial are mandatory", nil)
+		return
+	}
+
+	err := h.getstreamSvc.UnmuteUser(c.Request.Context(), ownUserSerial, targetUserSerial)
+	if err != nil {
+		AddResponseToContext(c, statusCodeFromError(err), err.Error(), nil)
+		return
+	}
+
+	AddResponseToContext(c, http.StatusOK, fmt.Sprintf("%s has successfully unmuted %s!", ownUserSerial, targetUserSerial), nil)
+}
+
+func (h *handler) GetMutedUsersByUserSerial(c *gin.Context) {
+	userSerial := c.Param("userSerial")
+	if userSerial == "" {
+		AddResponseToContext(c, http.StatusBadRequest, "userSerial is mandatory", nil)
+		return
+	}
+
+	resp, err := h.getstreamSvc.GetMutedUsersByUserSerial(c.Request.Context(), userSerial)
+	if err != nil {
+		AddResponseToContext(c, statusCodeFromError(err), err.Error(), nil)
+		return
+	}
+
+	AddResponseToContext(c, http.StatusOK, "Success", resp)
+}
@@ -0,0 +1,115 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/wisnuanggoro/go-getstream/draft"
+
+	"github.com/gin-gonic/gin"
+)
+
+type draftHandler struct {
+	draftSvc draft.Service
+}
+
+type DraftHandler interface {
+	CreateDraft(c *gin.Context)
+	GetDraftsByUserSerial(c *gin.Context)
+	GetDraftByDraftID(c *gin.Context)
+	UpdateDraft(c *gin.Context)
+	DeleteDraft(c *gin.Context)
+}
+
+func NewDraftHandler(draftSvc draft.Service) DraftHandler {
+	return &draftHandler{
+		draftSvc: draftSvc,
+	}
+}
+
+func (h *draftHandler) CreateDraft(c *gin.Context) {
+	userSerial := c.Query("userSerial")
+	postContent := c.Query("postContent")
+	postType := c.Query("postType")
+	if userSerial == "" {
+		AddResponseToContext(c, http.StatusBadRequest, "userSerial is mandatory", nil)
+		return
+	}
+
+	resp, err := h.draftSvc.CreateDraft(userSerial, postContent, postType)
+	if err != nil {
+		AddResponseToContext(c, statusCodeFromError(err), err.Error(), nil)
+		return
+	}
+
+	AddResponseToContext(c, http.StatusCreated, "Draft has been successfully saved!", resp)
+}
+
+func (h *draftHandler) GetDraftsByUserSerial(c *gin.Context) {
+	userSerial := c.Param("userSerial")
+	if userSerial == "" {
+		AddResponseToContext(c, http.StatusBadRequest, "userSerial is mandatory", nil)
+		return
+	}
+
+	resp, err := h.draftSvc.GetDraftsByUserSerial(userSerial)
+	if err != nil {
+		AddResponseToContext(c, statusCodeFromError(err), err.Error(), nil)
+		return
+	}
+
+	AddResponseToContext(c, http.StatusOK, "Success", resp)
+}
+
+func (h *draftHandler) GetDraftByDraftID(c *gin.Context) {
+	userSerial := c.Param("userSerial")
+	draftID := c.Param("draftID")
+	if userSerial == "" || draftID == "" {
+		AddResponseToContext(c, http.StatusBadRequest, "userSerial and draftID are mandatory", nil)
+		return
+	}
+
+	resp, err := h.draftSvc.GetDraft(userSerial, draftID)
+	if err != nil {
+		AddResponseToContext(c, statusCodeFromError(err), err.Error(), nil)
+		return
+	}
+
+	AddResponseToContext(c, http.StatusOK, "Success", resp)
+}
+
+func (h *draftHandler) UpdateDraft(c *gin.Context) {
+	userSerial := c.Query("userSerial")
+	draftID := c.Query("draftID")
+	postContent := c.Query("postContent")
+	postType := c.Query("postType")
+	if userSerial == "" || draftID == "" {
+		AddResponseToContext(c, http.StatusBadRequest, "userSerial and draftID are mandatory", nil)
+		return
+	}
+
+	resp, err := h.draftSvc.UpdateDraft(userSerial, draftID, postContent, postType)
+	if err != nil {
+		AddResponseToContext(c, statusCodeFromError(err), err.Error(), nil)
+		return
+	}
+
+	AddResponseToContext(c, http.StatusOK, fmt.Sprintf("Draft with ID %s has been successfully updated!", draftID), resp)
+}
+
+func (h *draftHandler) DeleteDraft(c *gin.Context) {
+	userSerial := c.Query("userSerial")
+	draftID := c.Query("draftID")
+	if userSerial == "" || draftID == "" {
+		AddResponseToContext(c, http.StatusBadRequest, "userSerial and draftID are mandatory", nil)
+		return
+	}
+
+	err := h.draftSvc.DeleteDraft(userSerial, draftID)
+	if err != nil {
+		AddResponseToContext(c, statusCodeFromError(err), err.Error(), nil)
+		return
+	}
+
+	AddResponseToContext(c, http.StatusOK, fmt.Sprintf("Draft with ID %s has been successfully deleted!", draftID), nil)
+}
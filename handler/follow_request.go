@@ -0,0 +1,96 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+func (h *handler) SetPrivateAccount(c *gin.Context) {
+	userSerial := c.Param("userSerial")
+	private, err := strconv.ParseBool(c.Query("private"))
+	if userSerial == "" || err != nil {
+		AddResponseToContext(c, http.StatusBadRequest, "userSerial and private (true or false) are mandatory", nil)
+		return
+	}
+
+	err = h.getstreamSvc.SetPrivateAccount(c.Request.Context(), userSerial, private)
+	if err != nil {
+		AddResponseToContext(c, statusCodeFromError(err), err.Error(), nil)
+		return
+	}
+
+	visibility := "public"
+	if private {
+		visibility = "private"
+	}
+	AddResponseToContext(c, http.StatusOK, fmt.Sprintf("%s is now a %s account!", userSerial, visibility), nil)
+}
+
+func (h *handler) GetIncomingFollowRequests(c *gin.Context) {
+	userSerial := c.Param("userSerial")
+	if userSerial == "" {
+		AddResponseToContext(c, http.StatusBadRequest, "userSerial is mandatory", nil)
+		return
+	}
+
+	resp, err := h.getstreamSvc.GetIncomingFollowRequests(c.Request.Context(), userSerial)
+	if err != nil {
+		AddResponseToContext(c, statusCodeFromError(err), err.Error(), nil)
+		return
+	}
+
+	AddResponseToContext(c, http.StatusOK, "Success", resp)
+}
+
+func (h *handler) GetOutgoingFollowRequests(c *gin.Context) {
+	userSerial := c.Param("userSerial")
+	if userSerial == "" {
+		AddResponseToContext(c, http.StatusBadRequest, "userSerial is mandatory", nil)
+		return
+	}
+
+	resp, err := h.getstreamSvc.GetOutgoingFollowRequests(c.Request.Context(), userSerial)
+	if err != nil {
+		AddResponseToContext(c, statusCodeFromError(err), err.Error(), nil)
+		return
+	}
+
+	AddResponseToContext(c, http.StatusOK, "Success", resp)
+}
+
+func (h *handler) ApproveFollowRequest(c *gin.Context) {
+	requestID := c.Param("requestID")
+	userSerial := c.Query("userSerial")
+	if requestID == "" || userSerial == "" {
+		AddResponseToContext(c, http.StatusBadRequest, "requestID and userSerial are mandatory", nil)
+		return
+	}
+
+	resp, err := h.getstreamSvc.ApproveFollowRequest(c.Request.Context(), userSerial, requestID)
+	if err != nil {
+		AddResponseToContext(c, statusCodeFromError(err), err.Error(), nil)
+		return
+	}
+
+	AddResponseToContext(c, http.StatusOK, fmt.Sprintf("%s has successfully followed %s!", resp.OwnUserSerial, resp.TargetUserSerial), resp)
+}
+
+func (h *handler) RejectFollowRequest(c *gin.Context) {
+	requestID := c.Param("requestID")
+	userSerial := c.Query("userSerial")
+	if requestID == "" || userSerial == "" {
+		AddResponseToContext(c, http.StatusBadRequest, "requestID and userSerial are mandatory", nil)
+		return
+	}
+
+	resp, err := h.getstreamSvc.RejectFollowRequest(c.Request.Context(), userSerial, requestID)
+	if err != nil {
+		AddResponseToContext(c, statusCodeFromError(err), err.Error(), nil)
+		return
+	}
+
+	AddResponseToContext(c, http.StatusOK, fmt.Sprintf("Follow request with ID %s has been rejected!", requestID), resp)
+}
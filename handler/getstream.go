@@ -4,20 +4,29 @@ import (
 	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/wisnuanggoro/go-getstream/getstream"
+	"github.com/wisnuanggoro/go-getstream/schedule"
 
 	"github.com/gin-gonic/gin"
 )
 
+// maxRelationshipBatch caps how many users one relationship lookup may cover
+const maxRelationshipBatch = 50
+
 type handler struct {
 	getstreamSvc getstream.Service
+	scheduleSvc  schedule.Service
 }
 
 type GetstreamHandler interface {
 	AddPostByUserSerial(c *gin.Context)
 	GetPostByUserSerial(c *gin.Context)
 	GetPostDetailByUserSerial(c *gin.Context)
+	EditPostByPostID(c *gin.Context)
+	GetPostHistoryByPostID(c *gin.Context)
 	DeletePostByPostID(c *gin.Context)
 	GetTimelineByUserSerial(c *gin.Context)
 	GetDetailTimelineByUserSerial(c *gin.Context)
@@ -25,15 +34,30 @@ type GetstreamHandler interface {
 	Unfollow(c *gin.Context)
 	GetFeedFollowersByUserSerial(c *gin.Context)
 	GetFollowedFeedsByUserSerial(c *gin.Context)
+	GetFollowSuggestionsByUserSerial(c *gin.Context)
+	GetRelationship(c *gin.Context)
+	GetRelationships(c *gin.Context)
 	AddLikeToPostID(c *gin.Context)
 	RetrieveLikeDetailOnPostID(c *gin.Context)
 	RetrieveLikeDetailOnPostIDWithPagination(c *gin.Context)
 	RemoveLikeByReactionID(c *gin.Context)
+	BlockUser(c *gin.Context)
+	UnblockUser(c *gin.Context)
+	GetBlockedUsersByUserSerial(c *gin.Context)
+	MuteUser(c *gin.Context)
+	UnmuteUser(c *gin.Context)
+	GetMutedUsersByUserSerial(c *gin.Context)
+	SetPrivateAccount(c *gin.Context)
+	GetIncomingFollowRequests(c *gin.Context)
+	GetOutgoingFollowRequests(c *gin.Context)
+	ApproveFollowRequest(c *gin.Context)
+	RejectFollowRequest(c *gin.Context)
 }
 
-func NewGetstreamHandler(getstreamSvc getstream.Service) GetstreamHandler {
+func NewGetstreamHandler(getstreamSvc getstream.Service, scheduleSvc schedule.Service) GetstreamHandler {
 	return &handler{
 		getstreamSvc: getstreamSvc,
+		scheduleSvc:  scheduleSvc,
 	}
 }
 
@@ -46,9 +70,27 @@ func (h *handler) AddPostByUserSerial(c *gin.Context) {
 		return
 	}
 
-	resp, err := h.getstreamSvc.AddPostByUserSerial(userSerial, postContent, postType)
+	// Posts with `publishAt` are published later by the schedule worker
+	if publishAtString := c.Query("publishAt"); publishAtString != "" {
+		publishAt, err := time.Parse(time.RFC3339, publishAtString)
+		if err != nil {
+			AddResponseToContext(c, http.StatusBadRequest, "publishAt must be an RFC3339 timestamp", nil)
+			return
+		}
+
+		scheduled, err := h.scheduleSvc.SchedulePost(userSerial, postContent, postType, publishAt)
+		if err != nil {
+			AddResponseToContext(c, statusCodeFromError(err), err.Error(), nil)
+			return
+		}
+
+		AddResponseToContext(c, http.StatusAccepted, fmt.Sprintf("Post has been scheduled for %s!", scheduled.PublishAt.Format(time.RFC3339)), scheduled)
+		return
+	}
+
+	resp, err := h.getstreamSvc.AddPostByUserSerial(c.Request.Context(), userSerial, postContent, postType)
 	if err != nil {
-		AddResponseToContext(c, http.StatusInternalServerError, err.Error(), nil)
+		AddResponseToContext(c, statusCodeFromError(err), err.Error(), nil)
 		return
 	}
 
@@ -57,14 +99,15 @@ func (h *handler) AddPostByUserSerial(c *gin.Context) {
 
 func (h *handler) GetPostByUserSerial(c *gin.Context) {
 	userSerial := c.Param("userSerial")
+	viewerSerial := c.Query("viewerSerial")
 	if userSerial == "" {
 		AddResponseToContext(c, http.StatusBadRequest, "userSerial is mandatory", nil)
 		return
 	}
 
-	resp, err := h.getstreamSvc.GetPostByUserSerial(userSerial)
+	resp, err := h.getstreamSvc.GetPostByUserSerial(c.Request.Context(), viewerSerial, userSerial)
 	if err != nil {
-		AddResponseToContext(c, http.StatusInternalServerError, err.Error(), nil)
+		AddResponseToContext(c, statusCodeFromError(err), err.Error(), nil)
 		return
 	}
 
@@ -73,20 +116,59 @@ func (h *handler) GetPostByUserSerial(c *gin.Context) {
 
 func (h *handler) GetPostDetailByUserSerial(c *gin.Context) {
 	userSerial := c.Param("userSerial")
+	viewerSerial := c.Query("viewerSerial")
 	if userSerial == "" {
 		AddResponseToContext(c, http.StatusBadRequest, "userSerial is mandatory", nil)
 		return
 	}
 
-	resp, err := h.getstreamSvc.GetPostDetailByUserSerial(userSerial)
+	resp, err := h.getstreamSvc.GetPostDetailByUserSerial(c.Request.Context(), viewerSerial, userSerial)
 	if err != nil {
-		AddResponseToContext(c, http.StatusInternalServerError, err.Error(), nil)
+		AddResponseToContext(c, statusCodeFromError(err), err.Error(), nil)
 		return
 	}
 
 	AddResponseToContext(c, http.StatusOK, "success", resp)
 }
 
+func (h *handler) EditPostByPostID(c *gin.Context) {
+	userSerial := c.Query("userSerial")
+	postID := c.Query("postID")
+	postContent := c.Query("postContent")
+	postType := c.Query("postType")
+	if userSerial == "" || postID == "" || postContent == "" || postType == "" {
+		AddResponseToContext(c, http.StatusBadRequest, "userSerial, postID, postContent, and postType are mandatory", nil)
+		return
+	}
+
+	resp, err := h.getstreamSvc.EditPostByPostID(c.Request.Context(), userSerial, postID, postContent, postType)
+	if err != nil {
+		AddResponseToContext(c, statusCodeFromError(err), err.Error(), nil)
+		return
+	}
+
+	AddResponseToContext(c, http.StatusOK, fmt.Sprintf("Post with ID %s has been successfully edited!", postID), resp)
+}
+
+func (h *handler) GetPostHistoryByPostID(c *gin.Context) {
+	// gin needs sibling wildcards to share a name, so the route registers
+	// the postID under `:userSerial` like the other `/post/:userSerial/*` routes
+	postID := c.Param("userSerial")
+	viewerSerial := c.Query("viewerSerial")
+	if postID == "" {
+		AddResponseToContext(c, http.StatusBadRequest, "postID is mandatory", nil)
+		return
+	}
+
+	resp, err := h.getstreamSvc.GetPostHistoryByPostID(c.Request.Context(), viewerSerial, postID)
+	if err != nil {
+		AddResponseToContext(c, statusCodeFromError(err), err.Error(), nil)
+		return
+	}
+
+	AddResponseToContext(c, http.StatusOK, "Success", resp)
+}
+
 func (h *handler) DeletePostByPostID(c *gin.Context) {
 	userSerial := c.Query("userSerial")
 	postID := c.Query("postID")
@@ -95,9 +177,9 @@ func (h *handler) DeletePostByPostID(c *gin.Context) {
 		return
 	}
 
-	err := h.getstreamSvc.DeletePostByPostID(userSerial, postID)
+	err := h.getstreamSvc.DeletePostByPostID(c.Request.Context(), userSerial, postID)
 	if err != nil {
-		AddResponseToContext(c, http.StatusInternalServerError, err.Error(), nil)
+		AddResponseToContext(c, statusCodeFromError(err), err.Error(), nil)
 		return
 	}
 
@@ -111,9 +193,9 @@ func (h *handler) GetTimelineByUserSerial(c *gin.Context) {
 		return
 	}
 
-	resp, err := h.getstreamSvc.GetTimelineByUserSerial(userSerial)
+	resp, err := h.getstreamSvc.GetTimelineByUserSerial(c.Request.Context(), userSerial)
 	if err != nil {
-		AddResponseToContext(c, http.StatusInternalServerError, err.Error(), nil)
+		AddResponseToContext(c, statusCodeFromError(err), err.Error(), nil)
 		return
 	}
 
@@ -127,9 +209,9 @@ func (h *handler) GetDetailTimelineByUserSerial(c *gin.Context) {
 		return
 	}
 
-	resp, err := h.getstreamSvc.GetDetailTimelineByUserSerial(userSerial)
+	resp, err := h.getstreamSvc.GetDetailTimelineByUserSerial(c.Request.Context(), userSerial)
 	if err != nil {
-		AddResponseToContext(c, http.StatusInternalServerError, err.Error(), nil)
+		AddResponseToContext(c, statusCodeFromError(err), err.Error(), nil)
 		return
 	}
 
@@ -144,9 +226,15 @@ func (h *handler) Follow(c *gin.Context) {
 		return
 	}
 
-	err := h.getstreamSvc.Follow(ownUserSerial, targetUserSerial)
+	request, err := h.getstreamSvc.Follow(c.Request.Context(), ownUserSerial, targetUserSerial)
 	if err != nil {
-		AddResponseToContext(c, http.StatusInternalServerError, err.Error(), nil)
+		AddResponseToContext(c, statusCodeFromError(err), err.Error(), nil)
+		return
+	}
+
+	// Private users have to approve the follow first
+	if request != nil {
+		AddResponseToContext(c, http.StatusAccepted, fmt.Sprintf("%s has requested to follow %s!", ownUserSerial, targetUserSerial), request)
 		return
 	}
 
@@ -161,9 +249,9 @@ func (h *handler) Unfollow(c *gin.Context) {
 		return
 	}
 
-	err := h.getstreamSvc.Unfollow(ownUserSerial, targetUserSerial)
+	err := h.getstreamSvc.Unfollow(c.Request.Context(), ownUserSerial, targetUserSerial)
 	if err != nil {
-		AddResponseToContext(c, http.StatusInternalServerError, err.Error(), nil)
+		AddResponseToContext(c, statusCodeFromError(err), err.Error(), nil)
 		return
 	}
 
@@ -177,9 +265,9 @@ func (h *handler) GetFeedFollowersByUserSerial(c *gin.Context) {
 		return
 	}
 
-	resp, err := h.getstreamSvc.GetFeedFollowersByUserSerial(userSerial)
+	resp, err := h.getstreamSvc.GetFeedFollowersByUserSerial(c.Request.Context(), userSerial)
 	if err != nil {
-		AddResponseToContext(c, http.StatusInternalServerError, err.Error(), nil)
+		AddResponseToContext(c, statusCodeFromError(err), err.Error(), nil)
 		return
 	}
 
@@ -193,9 +281,77 @@ func (h *handler) GetFollowedFeedsByUserSerial(c *gin.Context) {
 		return
 	}
 
-	resp, err := h.getstreamSvc.GetFollowedFeedsByUserSerial(userSerial)
+	resp, err := h.getstreamSvc.GetFollowedFeedsByUserSerial(c.Request.Context(), userSerial)
+	if err != nil {
+		AddResponseToContext(c, statusCodeFromError(err), err.Error(), nil)
+		return
+	}
+
+	AddResponseToContext(c, http.StatusOK, "Success", resp)
+}
+
+func (h *handler) GetFollowSuggestionsByUserSerial(c *gin.Context) {
+	userSerial := c.Param("userSerial")
+	limitString := c.Query("limit")
+	if userSerial == "" {
+		AddResponseToContext(c, http.StatusBadRequest, "userSerial is mandatory", nil)
+		return
+	}
+
+	limit := 10
+	if limitString != "" {
+		i, err := strconv.Atoi(limitString)
+		if err == nil && i > 0 {
+			limit = i
+		}
+	}
+
+	resp, err := h.getstreamSvc.GetFollowSuggestionsByUserSerial(c.Request.Context(), userSerial, limit)
+	if err != nil {
+		AddResponseToContext(c, statusCodeFromError(err), err.Error(), nil)
+		return
+	}
+
+	AddResponseToContext(c, http.StatusOK, "Success", resp)
+}
+
+func (h *handler) GetRelationship(c *gin.Context) {
+	userSerial := c.Param("userSerial")
+	otherSerial := c.Param("otherSerial")
+	if userSerial == "" || otherSerial == "" {
+		AddResponseToContext(c, http.StatusBadRequest, "userSerial and otherSerial are mandatory", nil)
+		return
+	}
+
+	resp, err := h.getstreamSvc.GetRelationship(c.Request.Context(), userSerial, otherSerial)
+	if err != nil {
+		AddResponseToContext(c, statusCodeFromError(err), err.Error(), nil)
+		return
+	}
+
+	AddResponseToContext(c, http.StatusOK, "Success", resp)
+}
+
+func (h *handler) GetRelationships(c *gin.Context) {
+	userSerial := c.Param("userSerial")
+	otherSerials := []string{}
+	for _, otherSerial := range strings.Split(c.Query("otherSerials"), ",") {
+		if otherSerial = strings.TrimSpace(otherSerial); otherSerial != "" {
+			otherSerials = append(otherSerials, otherSerial)
+		}
+	}
+	if userSerial == "" || len(otherSerials) == 0 {
+		AddResponseToContext(c, http.StatusBadRequest, "userSerial and otherSerials (comma separated) are mandatory", nil)
+		return
+	}
+	if len(otherSerials) > maxRelationshipBatch {
+		AddResponseToContext(c, http.StatusBadRequest, fmt.Sprintf("otherSerials can't have more than %d users", maxRelationshipBatch), nil)
+		return
+	}
+
+	resp, err := h.getstreamSvc.GetRelationships(c.Request.Context(), userSerial, otherSerials)
 	if err != nil {
-		AddResponseToContext(c, http.StatusInternalServerError, err.Error(), nil)
+		AddResponseToContext(c, statusCodeFromError(err), err.Error(), nil)
 		return
 	}
 
@@ -210,9 +366,9 @@ func (h *handler) AddLikeToPostID(c *gin.Context) {
 		return
 	}
 
-	resp, err := h.getstreamSvc.AddLikeToPostID(likerUserSerial, postID)
+	resp, err := h.getstreamSvc.AddLikeToPostID(c.Request.Context(), likerUserSerial, postID)
 	if err != nil {
-		AddResponseToContext(c, http.StatusInternalServerError, err.Error(), nil)
+		AddResponseToContext(c, statusCodeFromError(err), err.Error(), nil)
 		return
 	}
 
@@ -236,9 +392,9 @@ func (h *handler) RetrieveLikeDetailOnPostID(c *gin.Context) {
 		}
 	}
 
-	resp, err := h.getstreamSvc.RetrieveLikeDetailOnPostID(postID, pageSize)
+	resp, err := h.getstreamSvc.RetrieveLikeDetailOnPostID(c.Request.Context(), postID, pageSize)
 	if err != nil {
-		AddResponseToContext(c, http.StatusInternalServerError, err.Error(), nil)
+		AddResponseToContext(c, statusCodeFromError(err), err.Error(), nil)
 		return
 	}
 
@@ -263,9 +419,9 @@ func (h *handler) RetrieveLikeDetailOnPostIDWithPagination(c *gin.Context) {
 		}
 	}
 
-	resp, err := h.getstreamSvc.RetrieveLikeDetailOnPostIDWithPagination(postID, nextLikeID, pageSize)
+	resp, err := h.getstreamSvc.RetrieveLikeDetailOnPostIDWithPagination(c.Request.Context(), postID, nextLikeID, pageSize)
 	if err != nil {
-		AddResponseToContext(c, http.StatusInternalServerError, err.Error(), nil)
+		AddResponseToContext(c, statusCodeFromError(err), err.Error(), nil)
 		return
 	}
 
@@ -279,9 +435,9 @@ func (h *handler) RemoveLikeByReactionID(c *gin.Context) {
 		return
 	}
 
-	err := h.getstreamSvc.RemoveLikeByReactionID(reactionID)
+	err := h.getstreamSvc.RemoveLikeByReactionID(c.Request.Context(), reactionID)
 	if err != nil {
-		AddResponseToContext(c, http.StatusInternalServerError, err.Error(), nil)
+		AddResponseToContext(c, statusCodeFromError(err), err.Error(), nil)
 		return
 	}
 
@@ -0,0 +1,290 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	stream "gopkg.in/GetStream/stream-go2.v3"
+
+	"github.com/wisnuanggoro/go-getstream/getstream"
+)
+
+// flakyStream is a local Stream API answering every request with the
+// current mode: `ok` with an empty feed, `fail` with a 500 or `stall` past
+// the client timeout
+type flakyStream struct {
+	server *httptest.Server
+
+	mu    sync.Mutex
+	mode  string
+	calls int
+}
+
+func newFlakyStream(t *testing.T) *flakyStream {
+	f := &flakyStream{mode: "ok"}
+	f.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		f.calls++
+		mode := f.mode
+		f.mu.Unlock()
+
+		w.Header().Set("Content-Type", "application/json")
+		switch mode {
+		case "fail":
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(map[string]interface{}{"status_code": http.StatusInternalServerError, "exception": "InternalError"})
+		case "stall":
+			select {
+			case <-time.After(time.Second):
+			case <-r.Context().Done():
+			}
+		default:
+			json.NewEncoder(w).Encode(map[string]interface{}{"results": []interface{}{}})
+		}
+	}))
+	t.Cleanup(f.server.Close)
+	return f
+}
+
+func (f *flakyStream) set(mode string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.mode = mode
+}
+
+func (f *flakyStream) callCount() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.calls
+}
+
+// redirectTransport sends requests for the Stream API to target
+type redirectTransport struct {
+	target *url.URL
+}
+
+func (r redirectTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.URL.Scheme = r.target.Scheme
+	req.URL.Host = r.target.Host
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+// newResilientRouter serves the timeline over the resilient Service of a
+// Stream client sending its requests to f
+func newResilientRouter(t *testing.T, f *flakyStream, policy getstream.ResiliencePolicy) *gin.Engine {
+	target, err := url.Parse(f.server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client, err := stream.NewClient("key", "secret", stream.WithHTTPRequester(&http.Client{
+		Timeout:   50 * time.Millisecond,
+		Transport: redirectTransport{target: target},
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	topology := getstream.Topology{
+		PostGroup:     "user",
+		TimelineGroup: "timeline",
+		PostVerb:      "post",
+		FollowRules:   []getstream.FollowRule{{Follower: "timeline", Followed: "user"}},
+	}
+	svc := getstream.NewService(client, getstream.NewMemoryStore(), topology, time.Minute, getstream.NewEvents())
+	svc = getstream.NewResilientService(svc, policy)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/timeline/:userSerial/summary", NewGetstreamHandler(svc, nil).GetTimelineByUserSerial)
+	return router
+}
+
+func getTimeline(router *gin.Engine) int {
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/timeline/1/summary", nil))
+	return w.Code
+}
+
+func TestGetTimelineThroughBreaker(t *testing.T) {
+	tests := []struct {
+		name string
+		// mode Stream fails in until the breaker opens
+		mode     string
+		wantCode int
+	}{
+		{name: "server errors", mode: "fail", wantCode: http.StatusInternalServerError},
+		{name: "stalled requests", mode: "stall", wantCode: http.StatusServiceUnavailable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			flaky := newFlakyStream(t)
+			policy := getstream.ResiliencePolicy{
+				MaxAttempts:      1,
+				InitialBackoff:   time.Millisecond,
+				MaxBackoff:       time.Millisecond,
+				FailureThreshold: 2,
+				OpenTimeout:      100 * time.Millisecond,
+			}
+			router := newResilientRouter(t, flaky, policy)
+
+			flaky.set(tt.mode)
+			for i := 0; i < 2; i++ {
+				if code := getTimeline(router); code != tt.wantCode {
+					t.Fatalf("failure %d: code = %d, want %d", i, code, tt.wantCode)
+				}
+			}
+
+			// Open, Stream isn't called while the breaker waits
+			calls := flaky.callCount()
+			if code := getTimeline(router); code != http.StatusServiceUnavailable {
+				t.Fatalf("open: code = %d, want 503", code)
+			}
+			if got := flaky.callCount(); got != calls {
+				t.Fatalf("open breaker called Stream %d times", got-calls)
+			}
+
+			// Half-open once the timeout passed, a failed probe opens it again
+			time.Sleep(policy.OpenTimeout + 20*time.Millisecond)
+			getTimeline(router)
+			if got := flaky.callCount(); got != calls+1 {
+				t.Fatalf("half-open breaker called Stream %d times, want a single probe", got-calls)
+			}
+			if code := getTimeline(router); code != http.StatusServiceUnavailable {
+				t.Fatalf("reopened: code = %d, want 503", code)
+			}
+
+			// A successful probe closes it
+			flaky.set("ok")
+			time.Sleep(policy.OpenTimeout + 20*time.Millisecond)
+			for i := 0; i < 2; i++ {
+				if code := getTimeline(router); code != http.StatusOK {
+					t.Fatalf("closed %d: code = %d, want 200", i, code)
+				}
+			}
+		})
+	}
+}
+
+// historyService answers post histories with revisions, or err, and
+// records the post ID it was asked for
+type historyService struct {
+	getstream.Service
+	revisions []getstream.PostRevision
+	err       error
+
+	postID       string
+	viewerSerial string
+}
+
+func (s *historyService) GetPostHistoryByPostID(ctx context.Context, viewerUserSerial, postID string) ([]getstream.PostRevision, error) {
+	s.postID, s.viewerSerial = postID, viewerUserSerial
+	return s.revisions, s.err
+}
+
+func TestGetPostHistoryByPostID(t *testing.T) {
+	tests := []struct {
+		name          string
+		err           error
+		wantCode      int
+		wantRevisions int
+	}{
+		{name: "edited post", wantCode: http.StatusOK, wantRevisions: 1},
+		{name: "unknown post", err: getstream.ErrPostNotFound, wantCode: http.StatusNotFound},
+		{name: "private author", err: getstream.ErrPrivateAccount, wantCode: http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := &historyService{
+				revisions: []getstream.PostRevision{{PostID: "post-1", Post: "first", Editor: "1"}},
+				err:       tt.err,
+			}
+
+			// Registered next to its siblings like in the app, which makes
+			// gin require the shared `:userSerial` wildcard
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			h := NewGetstreamHandler(svc, nil)
+			router.GET("/post/:userSerial/summary", h.GetPostByUserSerial)
+			router.GET("/post/:userSerial/history", h.GetPostHistoryByPostID)
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/post/post-1/history?viewerSerial=2", nil))
+			if w.Code != tt.wantCode {
+				t.Fatalf("code = %d, want %d", w.Code, tt.wantCode)
+			}
+			if svc.postID != "post-1" || svc.viewerSerial != "2" {
+				t.Errorf("asked for post %q viewed by %q, want post-1 viewed by 2", svc.postID, svc.viewerSerial)
+			}
+
+			var body struct {
+				Data []getstream.PostRevision `json:"data"`
+			}
+			if err := json.NewDecoder(w.Body).Decode(&body); err != nil {
+				t.Fatal(err)
+			}
+			if len(body.Data) != tt.wantRevisions {
+				t.Errorf("revisions = %+v, want %d", body.Data, tt.wantRevisions)
+			}
+		})
+	}
+}
+
+// relationshipService returns no relationship, counting the calls
+type relationshipService struct {
+	getstream.Service
+	calls int
+}
+
+func (s *relationshipService) GetRelationships(ctx context.Context, userSerial string, otherSerials []string) ([]getstream.Relationship, error) {
+	s.calls++
+	return []getstream.Relationship{}, nil
+}
+
+func TestGetRelationshipsBatch(t *testing.T) {
+	tooMany := make([]string, maxRelationshipBatch+1)
+	for i := range tooMany {
+		tooMany[i] = fmt.Sprint(i)
+	}
+
+	tests := []struct {
+		name         string
+		otherSerials string
+		wantCode     int
+	}{
+		{name: "a few users", otherSerials: "2, 3,,4", wantCode: http.StatusOK},
+		{name: "a full batch", otherSerials: strings.Join(tooMany[:maxRelationshipBatch], ","), wantCode: http.StatusOK},
+		{name: "no user", otherSerials: " , ", wantCode: http.StatusBadRequest},
+		{name: "too many users", otherSerials: strings.Join(tooMany, ","), wantCode: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := &relationshipService{}
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			router.GET("/user/:userSerial/relationships", NewGetstreamHandler(svc, nil).GetRelationships)
+
+			w := httptest.NewRecorder()
+			target := "/user/1/relationships?otherSerials=" + url.QueryEscape(tt.otherSerials)
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, target, nil))
+			if w.Code != tt.wantCode {
+				t.Fatalf("code = %d, want %d", w.Code, tt.wantCode)
+			}
+			if called := svc.calls > 0; called != (tt.wantCode == http.StatusOK) {
+				t.Errorf("service called = %v with code %d", called, w.Code)
+			}
+		})
+	}
+}
@@ -0,0 +1,39 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/wisnuanggoro/go-getstream/health"
+
+	"github.com/gin-gonic/gin"
+)
+
+type healthHandler struct {
+	checker *health.Checker
+}
+
+type HealthHandler interface {
+	Liveness(c *gin.Context)
+	Readiness(c *gin.Context)
+}
+
+func NewHealthHandler(checker *health.Checker) HealthHandler {
+	return &healthHandler{
+		checker: checker,
+	}
+}
+
+func (h *healthHandler) Liveness(c *gin.Context) {
+	// The process answers, that's all liveness needs
+	AddResponseToContext(c, http.StatusOK, "alive", gin.H{"status": health.StatusUp})
+}
+
+func (h *healthHandler) Readiness(c *gin.Context) {
+	report := h.checker.Check()
+	if report.Status != health.StatusUp {
+		AddResponseToContext(c, http.StatusServiceUnavailable, "not ready", report)
+		return
+	}
+
+	AddResponseToContext(c, http.StatusOK, "ready", report)
+}
@@ -0,0 +1,84 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/wisnuanggoro/go-getstream/schedule"
+
+	"github.com/gin-gonic/gin"
+)
+
+type scheduleHandler struct {
+	scheduleSvc schedule.Service
+}
+
+type ScheduleHandler interface {
+	GetScheduledPostsByUserSerial(c *gin.Context)
+	CancelScheduledPost(c *gin.Context)
+	ReschedulePost(c *gin.Context)
+}
+
+func NewScheduleHandler(scheduleSvc schedule.Service) ScheduleHandler {
+	return &scheduleHandler{
+		scheduleSvc: scheduleSvc,
+	}
+}
+
+func (h *scheduleHandler) GetScheduledPostsByUserSerial(c *gin.Context) {
+	userSerial := c.Param("userSerial")
+	if userSerial == "" {
+		AddResponseToContext(c, http.StatusBadRequest, "userSerial is mandatory", nil)
+		return
+	}
+
+	resp, err := h.scheduleSvc.GetScheduledPostsByUserSerial(userSerial)
+	if err != nil {
+		AddResponseToContext(c, statusCodeFromError(err), err.Error(), nil)
+		return
+	}
+
+	AddResponseToContext(c, http.StatusOK, "Success", resp)
+}
+
+func (h *scheduleHandler) CancelScheduledPost(c *gin.Context) {
+	userSerial := c.Query("userSerial")
+	scheduleID := c.Query("scheduleID")
+	if userSerial == "" || scheduleID == "" {
+		AddResponseToContext(c, http.StatusBadRequest, "userSerial and scheduleID are mandatory", nil)
+		return
+	}
+
+	resp, err := h.scheduleSvc.CancelScheduledPost(userSerial, scheduleID)
+	if err != nil {
+		AddResponseToContext(c, statusCodeFromError(err), err.Error(), nil)
+		return
+	}
+
+	AddResponseToContext(c, http.StatusOK, fmt.Sprintf("Scheduled post with ID %s has been successfully cancelled!", scheduleID), resp)
+}
+
+func (h *scheduleHandler) ReschedulePost(c *gin.Context) {
+	userSerial := c.Query("userSerial")
+	scheduleID := c.Query("scheduleID")
+	publishAtString := c.Query("publishAt")
+	if userSerial == "" || scheduleID == "" || publishAtString == "" {
+		AddResponseToContext(c, http.StatusBadRequest, "userSerial, scheduleID, and publishAt are mandatory", nil)
+		return
+	}
+
+	publishAt, err := time.Parse(time.RFC3339, publishAtString)
+	if err != nil {
+		AddResponseToContext(c, http.StatusBadRequest, "publishAt must be an RFC3339 timestamp", nil)
+		return
+	}
+
+	resp, err := h.scheduleSvc.ReschedulePost(userSerial, scheduleID, publishAt)
+	if err != nil {
+		AddResponseToContext(c, statusCodeFromError(err), err.Error(), nil)
+		return
+	}
+
+	AddResponseToContext(c, http.StatusOK, fmt.Sprintf("Scheduled post with ID %s has been rescheduled to %s!", scheduleID, resp.PublishAt.Format(time.RFC3339)), resp)
+}
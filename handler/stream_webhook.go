@@ -0,0 +1,59 @@
+package handler
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+
+	"github.com/wisnuanggoro/go-getstream/streamwebhook"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Stream batches callbacks, a body bigger than this isn't one of them
+const streamWebhookMaxBodySize = 4 << 20
+
+type streamWebhookHandler struct {
+	apiSecret  string
+	dispatcher *streamwebhook.Dispatcher
+}
+
+type StreamWebhookHandler interface {
+	ReceiveStreamWebhook(c *gin.Context)
+}
+
+func NewStreamWebhookHandler(apiSecret string, dispatcher *streamwebhook.Dispatcher) StreamWebhookHandler {
+	return &streamWebhookHandler{
+		apiSecret:  apiSecret,
+		dispatcher: dispatcher,
+	}
+}
+
+// ReceiveStreamWebhook accepts the callbacks signed with our API secret.
+// Failing handlers are only logged, answering with an error would make
+// Stream send the whole batch again to the handlers that succeeded.
+func (h *streamWebhookHandler) ReceiveStreamWebhook(c *gin.Context) {
+	body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, streamWebhookMaxBodySize))
+	if err != nil {
+		AddResponseToContext(c, http.StatusBadRequest, "body is too large or unreadable", nil)
+		return
+	}
+
+	if !streamwebhook.VerifySignature(h.apiSecret, body, c.GetHeader(streamwebhook.SignatureHeader)) {
+		AddResponseToContext(c, http.StatusUnauthorized, "invalid signature", nil)
+		return
+	}
+
+	events, err := streamwebhook.Parse(body)
+	if err != nil {
+		AddResponseToContext(c, http.StatusBadRequest, "body must be a JSON array of callbacks", nil)
+		return
+	}
+
+	err = h.dispatcher.Dispatch(c.Request.Context(), events)
+	if err != nil {
+		slog.WarnContext(c.Request.Context(), "handle stream webhook", slog.Int("events", len(events)), slog.String("error", err.Error()))
+	}
+
+	AddResponseToContext(c, http.StatusOK, "success", gin.H{"events": len(events)})
+}
@@ -0,0 +1,143 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/wisnuanggoro/go-getstream/getstream"
+	"github.com/wisnuanggoro/go-getstream/pubsub"
+	"github.com/wisnuanggoro/go-getstream/realtime"
+
+	"github.com/gin-gonic/gin"
+)
+
+type timelineStreamHandler struct {
+	getstreamSvc      getstream.Service
+	broker            *pubsub.Broker
+	heartbeatInterval time.Duration
+}
+
+type TimelineStreamHandler interface {
+	StreamTimelineByUserSerial(c *gin.Context)
+}
+
+func NewTimelineStreamHandler(getstreamSvc getstream.Service, broker *pubsub.Broker, heartbeatInterval time.Duration) TimelineStreamHandler {
+	return &timelineStreamHandler{
+		getstreamSvc:      getstreamSvc,
+		broker:            broker,
+		heartbeatInterval: heartbeatInterval,
+	}
+}
+
+// StreamTimelineByUserSerial pushes new timeline activities as Server-Sent
+// Events. Reconnecting clients send the ID of the last event they got in
+// `Last-Event-ID` to receive the ones they missed.
+func (h *timelineStreamHandler) StreamTimelineByUserSerial(c *gin.Context) {
+	userSerial := c.Param("userSerial")
+	if userSerial == "" {
+		AddResponseToContext(c, http.StatusBadRequest, "userSerial is mandatory", nil)
+		return
+	}
+
+	// Browsers send the header, clients that can't set headers use the query
+	lastEventIDString := c.GetHeader("Last-Event-ID")
+	if lastEventIDString == "" {
+		lastEventIDString = c.Query("lastEventID")
+	}
+	lastEventID, _ := strconv.ParseUint(lastEventIDString, 10, 64)
+
+	subscription, missed := h.broker.Subscribe(realtime.TimelineTopic(userSerial), lastEventID)
+	defer subscription.Close()
+
+	// The stream outlives the server write timeout
+	_ = http.NewResponseController(c.Writer).SetWriteDeadline(time.Time{})
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+	c.Writer.Flush()
+
+	muted := mutedUserSerials(c.Request.Context(), h.getstreamSvc, userSerial)
+	for _, event := range missed {
+		if !h.writeEvent(c, event, muted) {
+			return
+		}
+	}
+
+	heartbeat := time.NewTicker(h.heartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case event, ok := <-subscription.Events():
+			// Dropped for falling behind, the client reconnects and resumes
+			if !ok {
+				return
+			}
+			// Mutes may change while connected, each live event gets the current ones
+			if !h.writeEvent(c, event, mutedUserSerials(c.Request.Context(), h.getstreamSvc, userSerial)) {
+				return
+			}
+		case <-heartbeat.C:
+			if _, err := io.WriteString(c.Writer, ": heartbeat\n\n"); err != nil {
+				return
+			}
+			c.Writer.Flush()
+		}
+	}
+}
+
+// writeEvent sends event unless its actor is muted, returning false once
+// the client is gone
+func (h *timelineStreamHandler) writeEvent(c *gin.Context, event pubsub.Event, muted []string) bool {
+	if isMutedActor(muted, event) {
+		return true
+	}
+
+	_, err := fmt.Fprintf(c.Writer, "id: %d\nevent: %s\ndata: %s\n\n", event.ID, event.Type, event.Data)
+	if err != nil {
+		return false
+	}
+	c.Writer.Flush()
+	return true
+}
+
+// mutedUserSerials returns who userSerial muted, none when the Service
+// fails as a missed filter beats a dropped event
+func mutedUserSerials(ctx context.Context, getstreamSvc getstream.Service, userSerial string) []string {
+	muted, err := getstreamSvc.GetMutedUsersByUserSerial(ctx, userSerial)
+	if err != nil {
+		return nil
+	}
+	return muted
+}
+
+// isMutedActor applies the same filter as GetTimelineByUserSerial to a
+// timeline event
+func isMutedActor(muted []string, event pubsub.Event) bool {
+	if len(muted) == 0 {
+		return false
+	}
+
+	var activity struct {
+		Actor string `json:"actor"`
+	}
+	if err := json.Unmarshal(event.Data, &activity); err != nil {
+		return false
+	}
+
+	// Actors are user feed IDs, `user:<serial>`
+	_, actorUserSerial, _ := strings.Cut(activity.Actor, ":")
+	return slices.Contains(muted, actorUserSerial)
+}
@@ -1,20 +1,52 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+
+	"github.com/wisnuanggoro/go-getstream/draft"
+	"github.com/wisnuanggoro/go-getstream/getstream"
+	"github.com/wisnuanggoro/go-getstream/response"
+	"github.com/wisnuanggoro/go-getstream/schedule"
+	"github.com/wisnuanggoro/go-getstream/webhook"
 )
 
+// AddResponseToContext is kept for the handlers, the envelope is written by
+// the response package so middleware can share it without importing handler
 func AddResponseToContext(ctx *gin.Context, code int, detail string, data interface{}) {
-	ctx.JSON(
-		code,
-		gin.H{
-			"data":    data,
-			"status":  code,
-			"message": http.StatusText(code),
-			"detail":  detail,
-		},
-	)
-	return
+	response.AddToContext(ctx, code, detail, data)
+}
+
+// statusCodeFromError maps errors returned by the services to an HTTP status code
+func statusCodeFromError(err error) int {
+	switch {
+	case errors.Is(err, getstream.ErrPostNotFound),
+		errors.Is(err, getstream.ErrFollowRequestNotFound),
+		errors.Is(err, draft.ErrNotFound),
+		errors.Is(err, schedule.ErrNotFound),
+		errors.Is(err, webhook.ErrSubscriptionNotFound),
+		errors.Is(err, webhook.ErrDeliveryNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, getstream.ErrNotPostOwner),
+		errors.Is(err, getstream.ErrBlocked),
+		errors.Is(err, getstream.ErrPrivateAccount):
+		return http.StatusForbidden
+	case errors.Is(err, getstream.ErrFollowRequestNotPending),
+		errors.Is(err, schedule.ErrNotPending),
+		errors.Is(err, webhook.ErrDeliveryInProgress):
+		return http.StatusConflict
+	case errors.Is(err, getstream.ErrSameUser),
+		errors.Is(err, schedule.ErrPublishAtInPast),
+		errors.Is(err, webhook.ErrInvalidURL),
+		errors.Is(err, webhook.ErrInvalidEventTypes):
+		return http.StatusBadRequest
+	// Stream is down or too slow, the request may work later
+	case errors.Is(err, getstream.ErrCircuitOpen),
+		getstream.ErrorClass(err) == getstream.ErrorClassTimeout:
+		return http.StatusServiceUnavailable
+	default:
+		return http.StatusInternalServerError
+	}
 }
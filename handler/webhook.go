@@ -0,0 +1,105 @@
+package handler
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/wisnuanggoro/go-getstream/webhook"
+
+	"github.com/gin-gonic/gin"
+)
+
+type webhookHandler struct {
+	webhookSvc webhook.Service
+}
+
+type WebhookHandler interface {
+	CreateSubscription(c *gin.Context)
+	GetSubscriptions(c *gin.Context)
+	DeleteSubscription(c *gin.Context)
+	GetDeliveriesBySubscriptionID(c *gin.Context)
+	ReplayDelivery(c *gin.Context)
+}
+
+func NewWebhookHandler(webhookSvc webhook.Service) WebhookHandler {
+	return &webhookHandler{
+		webhookSvc: webhookSvc,
+	}
+}
+
+func (h *webhookHandler) CreateSubscription(c *gin.Context) {
+	url := c.Query("url")
+	eventTypes := c.Query("eventTypes")
+	if url == "" || eventTypes == "" {
+		AddResponseToContext(c, http.StatusBadRequest, "url and eventTypes are mandatory", nil)
+		return
+	}
+
+	resp, err := h.webhookSvc.CreateSubscription(url, strings.Split(eventTypes, ","))
+	if err != nil {
+		AddResponseToContext(c, statusCodeFromError(err), err.Error(), nil)
+		return
+	}
+
+	AddResponseToContext(c, http.StatusCreated, "Webhook subscription has been created, keep the secret, it won't be shown again", resp)
+}
+
+func (h *webhookHandler) GetSubscriptions(c *gin.Context) {
+	resp, err := h.webhookSvc.GetSubscriptions()
+	if err != nil {
+		AddResponseToContext(c, statusCodeFromError(err), err.Error(), nil)
+		return
+	}
+
+	AddResponseToContext(c, http.StatusOK, "success", resp)
+}
+
+func (h *webhookHandler) DeleteSubscription(c *gin.Context) {
+	subscriptionID := c.Param("subscriptionID")
+	if subscriptionID == "" {
+		AddResponseToContext(c, http.StatusBadRequest, "subscriptionID is mandatory", nil)
+		return
+	}
+
+	err := h.webhookSvc.DeleteSubscription(subscriptionID)
+	if err != nil {
+		AddResponseToContext(c, statusCodeFromError(err), err.Error(), nil)
+		return
+	}
+
+	AddResponseToContext(c, http.StatusOK, "success", nil)
+}
+
+// GetDeliveriesBySubscriptionID lists the queued and finished deliveries
+// of a subscription with the log of every attempt
+func (h *webhookHandler) GetDeliveriesBySubscriptionID(c *gin.Context) {
+	subscriptionID := c.Param("subscriptionID")
+	if subscriptionID == "" {
+		AddResponseToContext(c, http.StatusBadRequest, "subscriptionID is mandatory", nil)
+		return
+	}
+
+	resp, err := h.webhookSvc.GetDeliveriesBySubscriptionID(subscriptionID)
+	if err != nil {
+		AddResponseToContext(c, statusCodeFromError(err), err.Error(), nil)
+		return
+	}
+
+	AddResponseToContext(c, http.StatusOK, "success", resp)
+}
+
+func (h *webhookHandler) ReplayDelivery(c *gin.Context) {
+	deliveryID := c.Param("deliveryID")
+	if deliveryID == "" {
+		AddResponseToContext(c, http.StatusBadRequest, "deliveryID is mandatory", nil)
+		return
+	}
+
+	resp, err := h.webhookSvc.ReplayDelivery(deliveryID)
+	if err != nil {
+		AddResponseToContext(c, statusCodeFromError(err), err.Error(), nil)
+		return
+	}
+
+	AddResponseToContext(c, http.StatusAccepted, "Webhook delivery will be sent again", resp)
+}
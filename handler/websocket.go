@@ -0,0 +1,371 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/wisnuanggoro/go-getstream/getstream"
+	"github.com/wisnuanggoro/go-getstream/pubsub"
+	"github.com/wisnuanggoro/go-getstream/realtime"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+)
+
+const (
+	// Client messages are small commands, anything bigger is abuse
+	websocketMaxMessageSize = 4096
+	// How long a single write to the client may take
+	websocketWriteTimeout = 10 * time.Second
+)
+
+var (
+	errTooManySubscriptions = errors.New("too many subscriptions")
+	errUnknownMessageType   = errors.New("unknown message type")
+)
+
+type websocketHandler struct {
+	getstreamSvc     getstream.Service
+	broker           *pubsub.Broker
+	upgrader         websocket.Upgrader
+	maxSubscriptions int
+	sendBufferSize   int
+	pingInterval     time.Duration
+
+	shutdown     chan struct{}
+	shutdownOnce sync.Once
+	conns        sync.WaitGroup
+}
+
+type WebsocketHandler interface {
+	Serve(c *gin.Context)
+	// Close tells connected clients the server is going away and waits
+	// for their connections to close
+	Close()
+}
+
+// NewWebsocketHandler only accepts browsers from allowedOrigins, or from
+// the same origin when it's empty
+func NewWebsocketHandler(getstreamSvc getstream.Service, broker *pubsub.Broker, allowedOrigins []string, maxSubscriptions, sendBufferSize int, pingInterval time.Duration) WebsocketHandler {
+	upgrader := websocket.Upgrader{}
+	if len(allowedOrigins) > 0 {
+		upgrader.CheckOrigin = func(r *http.Request) bool {
+			// Only browsers send an origin, other clients can't be checked
+			origin := r.Header.Get("Origin")
+			return origin == "" || slices.Contains(allowedOrigins, "*") || slices.Contains(allowedOrigins, origin)
+		}
+	}
+
+	return &websocketHandler{
+		getstreamSvc:     getstreamSvc,
+		broker:           broker,
+		upgrader:         upgrader,
+		maxSubscriptions: maxSubscriptions,
+		sendBufferSize:   sendBufferSize,
+		pingInterval:     pingInterval,
+		shutdown:         make(chan struct{}),
+	}
+}
+
+// websocketClientMessage is a command from the client, one of `subscribe`,
+// `unsubscribe` or `ping`
+type websocketClientMessage struct {
+	Type        string `json:"type"`
+	Feed        string `json:"feed"`
+	LastEventID string `json:"lastEventID"`
+}
+
+// websocketServerMessage is one of `subscribed`, `unsubscribed`, `event`,
+// `error` or `pong`. Event IDs are strings, they don't fit a JavaScript number.
+type websocketServerMessage struct {
+	Type  string          `json:"type"`
+	Feed  string          `json:"feed,omitempty"`
+	ID    string          `json:"id,omitempty"`
+	Event string          `json:"event,omitempty"`
+	Data  json.RawMessage `json:"data,omitempty"`
+	Error string          `json:"error,omitempty"`
+}
+
+// Serve upgrades to a WebSocket on which the client subscribes to the
+// feeds it wants, `timeline:<userSerial>`, `notification:<userSerial>` or
+// `post:<postID>`. Clients too slow to keep up are disconnected with code
+// 1013 and resume by subscribing again with the last event ID of each feed.
+//
+// This is not authorization: the connected user is whoever the
+// `userSerial` query param names, so any client can read any timeline by
+// naming its user. Like the rest of the API, it relies on a gateway in
+// front of it to authenticate clients.
+func (h *websocketHandler) Serve(c *gin.Context) {
+	userSerial := c.Query("userSerial")
+	if userSerial == "" {
+		AddResponseToContext(c, http.StatusBadRequest, "userSerial is mandatory", nil)
+		return
+	}
+
+	select {
+	case <-h.shutdown:
+		AddResponseToContext(c, http.StatusServiceUnavailable, "server is shutting down", nil)
+		return
+	default:
+	}
+
+	// The upgrader answers failed handshakes itself
+	conn, err := h.upgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		return
+	}
+
+	h.conns.Add(1)
+	defer h.conns.Done()
+
+	wsConn := &websocketConn{
+		ctx:           c.Request.Context(),
+		handler:       h,
+		conn:          conn,
+		userSerial:    userSerial,
+		send:          make(chan websocketServerMessage, h.sendBufferSize),
+		subscriptions: map[string]*pubsub.Subscription{},
+		closing:       make(chan struct{}),
+	}
+	wsConn.run()
+}
+
+func (h *websocketHandler) Close() {
+	h.shutdownOnce.Do(func() {
+		close(h.shutdown)
+	})
+	h.conns.Wait()
+}
+
+// websocketConn is a connected client, a single goroutine writes to it
+type websocketConn struct {
+	ctx        context.Context
+	handler    *websocketHandler
+	conn       *websocket.Conn
+	userSerial string
+	send       chan websocketServerMessage
+
+	mu            sync.Mutex
+	subscriptions map[string]*pubsub.Subscription
+
+	closing   chan struct{}
+	closeOnce sync.Once
+	closeCode int
+	closeText string
+}
+
+func (w *websocketConn) run() {
+	written := make(chan struct{})
+	go func() {
+		defer close(written)
+		w.writeLoop()
+	}()
+
+	w.readLoop()
+	w.close(websocket.CloseNormalClosure, "")
+	<-written
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for feed, subscription := range w.subscriptions {
+		subscription.Close()
+		delete(w.subscriptions, feed)
+	}
+}
+
+func (w *websocketConn) readLoop() {
+	// Clients that stop answering pings are gone
+	pongTimeout := 2 * w.handler.pingInterval
+	w.conn.SetReadLimit(websocketMaxMessageSize)
+	_ = w.conn.SetReadDeadline(time.Now().Add(pongTimeout))
+	w.conn.SetPongHandler(func(string) error {
+		return w.conn.SetReadDeadline(time.Now().Add(pongTimeout))
+	})
+
+	for {
+		_, payload, err := w.conn.ReadMessage()
+		if err != nil {
+			return
+		}
+		_ = w.conn.SetReadDeadline(time.Now().Add(pongTimeout))
+
+		var message websocketClientMessage
+		if err := json.Unmarshal(payload, &message); err != nil {
+			w.enqueue(websocketServerMessage{Type: "error", Error: "message must be a JSON object"})
+			continue
+		}
+
+		err = w.handle(message)
+		if err != nil {
+			w.enqueue(websocketServerMessage{Type: "error", Feed: message.Feed, Error: err.Error()})
+		}
+	}
+}
+
+func (w *websocketConn) handle(message websocketClientMessage) error {
+	switch message.Type {
+	case "subscribe":
+		return w.subscribe(message.Feed, message.LastEventID)
+	case "unsubscribe":
+		w.unsubscribe(message.Feed)
+		return nil
+	case "ping":
+		w.enqueue(websocketServerMessage{Type: "pong"})
+		return nil
+	default:
+		return errUnknownMessageType
+	}
+}
+
+func (w *websocketConn) subscribe(feed, lastEventIDString string) error {
+	err := realtime.Authorize(w.userSerial, feed)
+	if err != nil {
+		return err
+	}
+	lastEventID, _ := strconv.ParseUint(lastEventIDString, 10, 64)
+
+	// Fetched before locking, so other commands don't wait on the Service
+	muted := w.mutedActors(feed)
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if _, ok := w.subscriptions[feed]; ok {
+		w.enqueue(websocketServerMessage{Type: "subscribed", Feed: feed})
+		return nil
+	}
+	if len(w.subscriptions) >= w.handler.maxSubscriptions {
+		return errTooManySubscriptions
+	}
+
+	subscription, missed := w.handler.broker.Subscribe(feed, lastEventID)
+	w.subscriptions[feed] = subscription
+
+	// Missed events are queued before live ones start flowing
+	w.enqueue(websocketServerMessage{Type: "subscribed", Feed: feed})
+	for _, event := range missed {
+		w.forward(feed, event, muted)
+	}
+	go w.forwardAll(feed, subscription)
+	return nil
+}
+
+func (w *websocketConn) unsubscribe(feed string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if subscription, ok := w.subscriptions[feed]; ok {
+		subscription.Close()
+		delete(w.subscriptions, feed)
+	}
+	w.enqueue(websocketServerMessage{Type: "unsubscribed", Feed: feed})
+}
+
+func (w *websocketConn) forwardAll(feed string, subscription *pubsub.Subscription) {
+	// Mutes may change while connected, each live event gets the current ones
+	for event := range subscription.Events() {
+		if !w.forward(feed, event, w.mutedActors(feed)) {
+			return
+		}
+	}
+
+	// The broker drops subscribers falling behind, the client resumes
+	w.mu.Lock()
+	dropped := w.subscriptions[feed] == subscription
+	w.mu.Unlock()
+	if dropped {
+		w.close(websocket.CloseTryAgainLater, "falling behind")
+	}
+}
+
+// mutedActors returns the users muted by the client when feed is a timeline
+func (w *websocketConn) mutedActors(feed string) []string {
+	if !strings.HasPrefix(feed, "timeline:") {
+		return nil
+	}
+	return mutedUserSerials(w.ctx, w.handler.getstreamSvc, w.userSerial)
+}
+
+// forward queues event unless it's an activity of a muted actor, returning
+// false once the connection is closing
+func (w *websocketConn) forward(feed string, event pubsub.Event, muted []string) bool {
+	if isMutedActor(muted, event) {
+		return true
+	}
+
+	return w.enqueue(websocketServerMessage{
+		Type:  "event",
+		Feed:  feed,
+		ID:    strconv.FormatUint(event.ID, 10),
+		Event: event.Type,
+		Data:  event.Data,
+	})
+}
+
+// enqueue never blocks, a client whose queue is full is disconnected
+func (w *websocketConn) enqueue(message websocketServerMessage) bool {
+	select {
+	case <-w.closing:
+		return false
+	default:
+	}
+
+	select {
+	case w.send <- message:
+		return true
+	default:
+		w.close(websocket.CloseTryAgainLater, "falling behind")
+		return false
+	}
+}
+
+// close makes the write loop send a close frame and drop the connection
+func (w *websocketConn) close(code int, text string) {
+	w.closeOnce.Do(func() {
+		w.closeCode = code
+		w.closeText = text
+		close(w.closing)
+	})
+}
+
+func (w *websocketConn) writeLoop() {
+	// Unblocks the read loop whatever made the writes stop
+	defer w.conn.Close()
+
+	ping := time.NewTicker(w.handler.pingInterval)
+	defer ping.Stop()
+
+	for {
+		select {
+		case message := <-w.send:
+			_ = w.conn.SetWriteDeadline(time.Now().Add(websocketWriteTimeout))
+			if err := w.conn.WriteJSON(message); err != nil {
+				return
+			}
+		case <-ping.C:
+			err := w.conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(websocketWriteTimeout))
+			if err != nil {
+				return
+			}
+		case <-w.handler.shutdown:
+			w.close(websocket.CloseGoingAway, "server shutting down")
+			w.writeClose()
+			return
+		case <-w.closing:
+			w.writeClose()
+			return
+		}
+	}
+}
+
+func (w *websocketConn) writeClose() {
+	closeMessage := websocket.FormatCloseMessage(w.closeCode, w.closeText)
+	_ = w.conn.WriteControl(websocket.CloseMessage, closeMessage, time.Now().Add(websocketWriteTimeout))
+}
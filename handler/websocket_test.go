@@ -0,0 +1,217 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+
+	"github.com/wisnuanggoro/go-getstream/getstream"
+	"github.com/wisnuanggoro/go-getstream/pubsub"
+)
+
+// muteService mutes user 3 for everyone
+type muteService struct {
+	getstream.Service
+}
+
+func (muteService) GetMutedUsersByUserSerial(ctx context.Context, userSerial string) ([]string, error) {
+	return []string{"3"}, nil
+}
+
+// newWebsocketServer serves the gateway over broker, accepting at most two
+// subscriptions per connection
+func newWebsocketServer(t *testing.T, broker *pubsub.Broker, allowedOrigins []string) (string, WebsocketHandler) {
+	h := NewWebsocketHandler(muteService{}, broker, allowedOrigins, 2, 16, time.Minute)
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/ws", h.Serve)
+
+	server := httptest.NewServer(router)
+	t.Cleanup(server.Close)
+	return "ws" + strings.TrimPrefix(server.URL, "http") + "/ws", h
+}
+
+func dialWebsocket(t *testing.T, url string) *websocket.Conn {
+	conn, resp, err := websocket.DefaultDialer.Dial(url, nil)
+	if err != nil {
+		t.Fatalf("dial %s: %v (%v)", url, err, resp)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func send(t *testing.T, conn *websocket.Conn, message websocketClientMessage) {
+	if err := conn.WriteJSON(message); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func receive(t *testing.T, conn *websocket.Conn) websocketServerMessage {
+	_ = conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	var message websocketServerMessage
+	if err := conn.ReadJSON(&message); err != nil {
+		t.Fatal(err)
+	}
+	return message
+}
+
+func TestWebsocketSubscribe(t *testing.T) {
+	broker := pubsub.NewBroker(10, time.Minute, 16)
+	url, _ := newWebsocketServer(t, broker, nil)
+	conn := dialWebsocket(t, url+"?userSerial=1")
+
+	send(t, conn, websocketClientMessage{Type: "subscribe", Feed: "timeline:1"})
+	if message := receive(t, conn); message.Type != "subscribed" || message.Feed != "timeline:1" {
+		t.Fatalf("message = %+v, want subscribed", message)
+	}
+
+	// Activities of muted users are skipped
+	broker.Publish("timeline:1", "activity", []byte(`{"actor":"user:3"}`))
+	published := broker.Publish("timeline:1", "activity", []byte(`{"actor":"user:2"}`))
+	message := receive(t, conn)
+	if message.Type != "event" || message.ID != strconv.FormatUint(published.ID, 10) || string(message.Data) != `{"actor":"user:2"}` {
+		t.Errorf("message = %+v, want the activity of user 2", message)
+	}
+
+	send(t, conn, websocketClientMessage{Type: "ping"})
+	if message := receive(t, conn); message.Type != "pong" {
+		t.Errorf("message = %+v, want pong", message)
+	}
+
+	// Nothing is received after unsubscribing
+	send(t, conn, websocketClientMessage{Type: "unsubscribe", Feed: "timeline:1"})
+	if message := receive(t, conn); message.Type != "unsubscribed" {
+		t.Errorf("message = %+v, want unsubscribed", message)
+	}
+	broker.Publish("timeline:1", "activity", []byte(`{"actor":"user:2"}`))
+	send(t, conn, websocketClientMessage{Type: "ping"})
+	if message := receive(t, conn); message.Type != "pong" {
+		t.Errorf("message = %+v, want pong without the event", message)
+	}
+}
+
+func TestWebsocketResume(t *testing.T) {
+	broker := pubsub.NewBroker(10, time.Minute, 16)
+	url, _ := newWebsocketServer(t, broker, nil)
+
+	seen := broker.Publish("post:p1", "reaction", []byte(`{"delta":1}`))
+	missed := broker.Publish("post:p1", "reaction", []byte(`{"delta":-1}`))
+
+	conn := dialWebsocket(t, url+"?userSerial=2")
+	send(t, conn, websocketClientMessage{Type: "subscribe", Feed: "post:p1", LastEventID: strconv.FormatUint(seen.ID, 10)})
+	if message := receive(t, conn); message.Type != "subscribed" {
+		t.Fatalf("message = %+v, want subscribed", message)
+	}
+	if message := receive(t, conn); message.Type != "event" || message.ID != strconv.FormatUint(missed.ID, 10) {
+		t.Errorf("message = %+v, want the missed event", message)
+	}
+}
+
+func TestWebsocketRejectedCommands(t *testing.T) {
+	tests := []struct {
+		name      string
+		message   websocketClientMessage
+		wantError string
+	}{
+		{name: "timeline of another user", message: websocketClientMessage{Type: "subscribe", Feed: "timeline:2"}, wantError: "feed belongs to another user"},
+		{name: "unknown feed", message: websocketClientMessage{Type: "subscribe", Feed: "aggregated:1"}, wantError: "unknown feed"},
+		{name: "too many subscriptions", message: websocketClientMessage{Type: "subscribe", Feed: "post:p3"}, wantError: errTooManySubscriptions.Error()},
+		{name: "unknown command", message: websocketClientMessage{Type: "publish", Feed: "post:p1"}, wantError: errUnknownMessageType.Error()},
+	}
+
+	broker := pubsub.NewBroker(10, time.Minute, 16)
+	url, _ := newWebsocketServer(t, broker, nil)
+	conn := dialWebsocket(t, url+"?userSerial=1")
+	for _, feed := range []string{"post:p1", "post:p2"} {
+		send(t, conn, websocketClientMessage{Type: "subscribe", Feed: feed})
+		if message := receive(t, conn); message.Type != "subscribed" {
+			t.Fatalf("message = %+v, want subscribed", message)
+		}
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			send(t, conn, tt.message)
+			if message := receive(t, conn); message.Type != "error" || message.Error != tt.wantError {
+				t.Errorf("message = %+v, want error %q", message, tt.wantError)
+			}
+		})
+	}
+}
+
+func TestWebsocketHandshake(t *testing.T) {
+	broker := pubsub.NewBroker(10, time.Minute, 16)
+	url, _ := newWebsocketServer(t, broker, []string{"https://app.example"})
+
+	tests := []struct {
+		name     string
+		query    string
+		origin   string
+		wantCode int
+	}{
+		{name: "allowed origin", query: "?userSerial=1", origin: "https://app.example", wantCode: http.StatusSwitchingProtocols},
+		{name: "client without origin", query: "?userSerial=1", wantCode: http.StatusSwitchingProtocols},
+		{name: "other origin", query: "?userSerial=1", origin: "https://evil.example", wantCode: http.StatusForbidden},
+		{name: "missing user", origin: "https://app.example", wantCode: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header := http.Header{}
+			if tt.origin != "" {
+				header.Set("Origin", tt.origin)
+			}
+			conn, resp, err := websocket.DefaultDialer.Dial(url+tt.query, header)
+			if conn != nil {
+				conn.Close()
+			}
+			if resp == nil {
+				t.Fatalf("no handshake response: %v", err)
+			}
+			if resp.StatusCode != tt.wantCode {
+				t.Errorf("code = %d, want %d", resp.StatusCode, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestWebsocketClose(t *testing.T) {
+	broker := pubsub.NewBroker(10, time.Minute, 16)
+	url, h := newWebsocketServer(t, broker, nil)
+	conn := dialWebsocket(t, url+"?userSerial=1")
+	send(t, conn, websocketClientMessage{Type: "ping"})
+	receive(t, conn)
+
+	// Clients are told the server is going away, then closed
+	closed := make(chan struct{})
+	go func() {
+		h.Close()
+		close(closed)
+	}()
+	_ = conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	_, _, err := conn.ReadMessage()
+	var closeErr *websocket.CloseError
+	if !errors.As(err, &closeErr) || closeErr.Code != websocket.CloseGoingAway {
+		t.Errorf("err = %v, want close 1001", err)
+	}
+	conn.Close()
+	select {
+	case <-closed:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Close kept waiting for the closed connection")
+	}
+
+	// New clients are turned away
+	_, resp, _ := websocket.DefaultDialer.Dial(url+"?userSerial=1", nil)
+	if resp == nil || resp.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("response = %v, want 503", resp)
+	}
+}
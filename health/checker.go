@@ -0,0 +1,126 @@
+package health
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+const (
+	StatusUp   = "up"
+	StatusDown = "down"
+)
+
+// Check reports whether a dependency is usable, returning nil when it is
+type Check func() error
+
+// Result is the outcome of a single dependency check
+type Result struct {
+	Status    string    `json:"status"`
+	Error     string    `json:"error,omitempty"`
+	Duration  string    `json:"duration"`
+	CheckedAt time.Time `json:"checkedAt"`
+}
+
+// Report is the outcome of every registered check
+type Report struct {
+	Status       string            `json:"status"`
+	Dependencies map[string]Result `json:"dependencies"`
+}
+
+// Checker runs registered checks with a timeout and reuses the last report
+// for a while, so frequent probes don't put load on the dependencies
+type Checker struct {
+	timeout  time.Duration
+	cacheTTL time.Duration
+
+	mu        sync.Mutex
+	names     []string
+	checks    map[string]Check
+	report    Report
+	expiresAt time.Time
+}
+
+func NewChecker(timeout, cacheTTL time.Duration) *Checker {
+	return &Checker{
+		timeout:  timeout,
+		cacheTTL: cacheTTL,
+		checks:   map[string]Check{},
+	}
+}
+
+// Register adds a dependency check under name
+func (c *Checker) Register(name string, check Check) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.names = append(c.names, name)
+	c.checks[name] = check
+	c.expiresAt = time.Time{}
+}
+
+// Check returns the cached report, running every check again once it expired
+func (c *Checker) Check() Report {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if time.Now().Before(c.expiresAt) {
+		return c.report
+	}
+
+	// Checks run concurrently so one slow dependency doesn't delay the others
+	results := make([]Result, len(c.names))
+	var wg sync.WaitGroup
+	for i, name := range c.names {
+		wg.Add(1)
+		go func(i int, check Check) {
+			defer wg.Done()
+			results[i] = c.run(check)
+		}(i, c.checks[name])
+	}
+	wg.Wait()
+
+	report := Report{
+		Status:       StatusUp,
+		Dependencies: map[string]Result{},
+	}
+	for i, name := range c.names {
+		report.Dependencies[name] = results[i]
+		if results[i].Status != StatusUp {
+			report.Status = StatusDown
+		}
+	}
+
+	c.report = report
+	c.expiresAt = time.Now().Add(c.cacheTTL)
+	return report
+}
+
+func (c *Checker) run(check Check) Result {
+	start := time.Now()
+
+	// The check keeps running in the background after a timeout, the
+	// buffered channel lets it finish without blocking
+	done := make(chan error, 1)
+	go func() {
+		done <- check()
+	}()
+
+	var err error
+	select {
+	case err = <-done:
+	case <-time.After(c.timeout):
+		err = fmt.Errorf("timed out after %s", c.timeout)
+	}
+
+	result := Result{
+		Status:    StatusUp,
+		Duration:  time.Since(start).String(),
+		CheckedAt: start.UTC(),
+	}
+	if err != nil {
+		result.Status = StatusDown
+		result.Error = err.Error()
+	}
+	return result
+}
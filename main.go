@@ -1,62 +1,237 @@
 package main
 
 import (
-	"log"
+	"context"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
 
 	"github.com/gin-gonic/gin"
-	stream "gopkg.in/GetStream/stream-go2.v3"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
 
+	"github.com/wisnuanggoro/go-getstream/cache"
 	"github.com/wisnuanggoro/go-getstream/config"
+	"github.com/wisnuanggoro/go-getstream/eventbus"
 	"github.com/wisnuanggoro/go-getstream/getstream"
+	"github.com/wisnuanggoro/go-getstream/grpcapi"
 	"github.com/wisnuanggoro/go-getstream/handler"
+	"github.com/wisnuanggoro/go-getstream/health"
+	"github.com/wisnuanggoro/go-getstream/logging"
+	"github.com/wisnuanggoro/go-getstream/middleware"
+	"github.com/wisnuanggoro/go-getstream/openapi"
+	"github.com/wisnuanggoro/go-getstream/ratelimit"
+	"github.com/wisnuanggoro/go-getstream/server"
+	"github.com/wisnuanggoro/go-getstream/tenant"
+	"github.com/wisnuanggoro/go-getstream/tracing"
 )
 
 func main() {
 	// Get configuration
 	cfg := config.Get()
 
-	// Initialize Getstream Client
-	getstreamClient, err := stream.NewClient(
-		cfg.GoStreamAPIKey,
-		cfg.GoStreamAPISecret,
-		stream.WithAPIRegion(cfg.GoStreamAPIRegion),
+	// Initialize structured logger, also used by the standard library logger
+	logger := logging.New(cfg.LogLevel)
+	slog.SetDefault(logger)
+
+	// Refuse to start on an invalid configuration, it can't change while running
+	err := cfg.Validate()
+	if err != nil {
+		logger.Error("validate configuration", slog.String("error", err.Error()))
+		os.Exit(1)
+	}
+
+	// Stop gracefully on SIGINT and SIGTERM
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	// Initialize feed topology, shared by the Stream apps of every tenant
+	topology, err := loadTopology(cfg)
+	if err != nil {
+		logger.Error("initialize feed topology", slog.String("error", err.Error()))
+		os.Exit(1)
+	}
+
+	// Initialize tenants, each with its own Stream app
+	tenants, tenantFiles, err := loadTenants(cfg)
+	if err != nil {
+		logger.Error("initialize tenants", slog.String("error", err.Error()))
+		os.Exit(1)
+	}
+
+	// Initialize tracing, flushing pending spans on exit
+	shutdownTracing, err := tracing.Setup(ctx, cfg.TracingExporter, cfg.TracingServiceName, cfg.TracingSampleRatio)
+	if err != nil {
+		logger.Error("initialize tracing", slog.String("error", err.Error()))
+		os.Exit(1)
+	}
+	defer shutdownTracing(context.Background())
+
+	// Initialize metrics registry
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
 	)
+
+	// Initialize what tenants share
+	var sharedCache cache.Cache
+	if cfg.CacheBackend != "none" {
+		sharedCache, err = cache.New(cfg.CacheBackend, cfg.CacheMemorySize, cfg.RedisAddr, cfg.RedisPassword, cfg.RedisDB)
+		if err != nil {
+			logger.Error("initialize cache", slog.String("error", err.Error()))
+			os.Exit(1)
+		}
+	}
+
+	var eventPublisher eventbus.Publisher
+	if cfg.EventBusBackend != "none" {
+		eventPublisher, err = eventbus.NewPublisher(cfg.EventBusBackend, cfg.NATSURL, cfg.NATSSubjectPrefix, cfg.KafkaBrokers, cfg.KafkaTopic)
+		if err != nil {
+			logger.Error("initialize event publisher", slog.String("error", err.Error()))
+			os.Exit(1)
+		}
+		defer eventPublisher.Close()
+	}
+
+	// Initialize rate limits
+	userRateLimits, err := ratelimit.ParseRules(cfg.RateLimits)
+	if err != nil {
+		logger.Error("parse RATE_LIMITS", slog.String("error", err.Error()))
+		os.Exit(1)
+	}
+	ipRateLimits, err := ratelimit.ParseRules(cfg.RateLimitsIP)
 	if err != nil {
-		log.Fatalf(err.Error())
-		panic(err)
+		logger.Error("parse RATE_LIMITS_IP", slog.String("error", err.Error()))
+		os.Exit(1)
+	}
+
+	// Initialize the app of each tenant and its readiness check
+	healthChecker := health.NewChecker(cfg.HealthCheckTimeout, cfg.HealthCheckCacheTTL)
+
+	deps := tenantDeps{
+		cfg:            cfg,
+		topology:       topology,
+		logger:         logger,
+		registry:       registry,
+		cache:          sharedCache,
+		eventPublisher: eventPublisher,
+		userRateLimits: userRateLimits,
+		ipRateLimits:   ipRateLimits,
+		tenantFiles:    tenantFiles,
+	}
+	var apps []*tenantApp
+	tenantRouters := map[string]http.Handler{}
+	tenantServices := map[string]getstream.Service{}
+	for _, t := range tenants.Tenants() {
+		app, err := newTenantApp(deps, t)
+		if err != nil {
+			logger.Error("initialize tenant", slog.String("tenant", t.ID), slog.String("error", err.Error()))
+			os.Exit(1)
+		}
+		app.run(ctx)
+
+		apps = append(apps, app)
+		tenantRouters[t.ID] = app.router
+		tenantServices[t.ID] = app.getstreamSvc
+		healthChecker.Register("getstream:"+t.ID, func() error {
+			return app.getstreamSvc.Ping(tenant.WithID(logging.WithRequestID(ctx, "readiness"), app.tenant.ID))
+		})
 	}
+	healthHandler := handler.NewHealthHandler(healthChecker)
+
+	// Initialize gin router
+	router := newRouter(cfg, logger, registry, healthHandler, tenants, tenantRouters)
 
-	// Initialize services
-	getstreamSvc := getstream.NewService(getstreamClient)
+	// Run gRPC server next to the HTTP one, failing to serve stops both
+	grpcDone := make(chan struct{})
+	if cfg.GRPCPort != "" {
+		grpcServer := grpcapi.New(net.JoinHostPort(cfg.Host, cfg.GRPCPort), tenant.NewPool(tenantServices), tenants, cfg.TenantHeader, cfg.ServerShutdownTimeout)
+		go func() {
+			defer close(grpcDone)
+			err := grpcServer.Run(ctx)
+			if err != nil {
+				logger.Error("run grpc server", slog.String("error", err.Error()))
+				stop()
+			}
+		}()
+	} else {
+		close(grpcDone)
+	}
 
-	// Initialize handlers
-	getstreamHandler := handler.NewGetstreamHandler(getstreamSvc)
+	// Run server until a shutdown signal arrives, WebSockets aren't drained
+	// by the server as they're hijacked connections
+	err = server.New(cfg, router).Run(ctx)
+	for _, app := range apps {
+		app.websocketHandler.Close()
+	}
+	if err != nil {
+		logger.Error("run server", slog.String("error", err.Error()))
+		os.Exit(1)
+	}
+	<-grpcDone
+}
 
-	// Initialize and run gin server
-	router := gin.Default()
-	v1 := router.Group("/api/v1")
-	{
-		// Posting
-		v1.POST("/post", getstreamHandler.AddPostByUserSerial)
-		v1.GET("/post/:userSerial/summary", getstreamHandler.GetPostByUserSerial)
-		v1.GET("/post/:userSerial/detail", getstreamHandler.GetPostDetailByUserSerial)
-		v1.DELETE("/post", getstreamHandler.DeletePostByPostID)
+// loadTenants reads the tenants file, or makes the GOSTREAM_* app the single
+// default tenant. tenantFiles reports whether store files are per tenant.
+func loadTenants(cfg config.Config) (registry *tenant.Registry, tenantFiles bool, err error) {
+	if cfg.TenantsFile != "" {
+		registry, err = tenant.LoadRegistry(cfg.TenantsFile)
+		return registry, true, err
+	}
 
-		// Timeline
-		v1.GET("/timeline/:userSerial/summary", getstreamHandler.GetTimelineByUserSerial)
-		v1.GET("/timeline/:userSerial/detail", getstreamHandler.GetDetailTimelineByUserSerial)
+	registry, err = tenant.NewRegistry([]tenant.Tenant{{
+		ID:        tenant.DefaultID,
+		APIKey:    cfg.GoStreamAPIKey,
+		APISecret: cfg.GoStreamAPISecret,
+		APIRegion: cfg.GoStreamAPIRegion,
+	}})
+	return registry, false, err
+}
 
-		// Follower
-		v1.POST("/user/follow", getstreamHandler.Follow)
-		v1.POST("/user/unfollow", getstreamHandler.Unfollow)
-		v1.GET("/user/follower/:userSerial", getstreamHandler.GetFeedFollowersByUserSerial)
-		v1.GET("/user/followed/:userSerial", getstreamHandler.GetFollowedFeedsByUserSerial)
+// loadTopology reads the feed groups and follow rules of the Stream apps
+func loadTopology(cfg config.Config) (getstream.Topology, error) {
+	followRules, err := getstream.ParseFollowRules(cfg.FeedFollowRules)
+	if err != nil {
+		return getstream.Topology{}, err
+	}
 
-		// Like Reaction
-		v1.POST("/like", getstreamHandler.AddLikeToPostID)
-		v1.GET("/like/:postID", getstreamHandler.RetrieveLikeDetailOnPostID)
-		v1.GET("/like/:postID/:nextLikeID", getstreamHandler.RetrieveLikeDetailOnPostIDWithPagination)
-		v1.DELETE("/like/:reactionID", getstreamHandler.RemoveLikeByReactionID)
+	topology := getstream.Topology{
+		PostGroup:         cfg.FeedGroupPost,
+		TimelineGroup:     cfg.FeedGroupTimeline,
+		NotificationGroup: cfg.FeedGroupNotification,
+		AggregatedGroup:   cfg.FeedGroupAggregated,
+		PostVerb:          cfg.FeedPostVerb,
+		FollowRules:       followRules,
 	}
-	router.Run()
+	return topology, topology.Validate()
+}
+
+// newRouter serves the process wide routes, the others are served by the
+// router of the request's tenant
+func newRouter(cfg config.Config, logger *slog.Logger, registry *prometheus.Registry, healthHandler handler.HealthHandler, tenants *tenant.Registry, tenantRouters map[string]http.Handler) *gin.Engine {
+	router := gin.New()
+	router.Use(
+		gin.Recovery(),
+		middleware.RequestID(),
+	)
+	global := router.Group("/")
+	global.Use(
+		otelgin.Middleware(cfg.TracingServiceName),
+		middleware.Logger(logger),
+		middleware.Metrics(prometheus.WrapRegistererWith(prometheus.Labels{"tenant": ""}, registry)),
+	)
+	global.GET("/healthz", healthHandler.Liveness)
+	global.GET("/readyz", healthHandler.Readiness)
+	global.GET("/metrics", gin.WrapH(promhttp.HandlerFor(registry, promhttp.HandlerOpts{})))
+	global.GET("/openapi.json", openapi.Handler)
+	global.GET("/docs", openapi.UIHandler("/openapi.json", "/docs/assets"))
+	global.GET("/docs/assets/*file", openapi.AssetsHandler)
+	router.NoRoute(middleware.Tenant(tenants, cfg.TenantHeader, tenantRouters))
+	return router
 }
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"log/slog"
+	"net/http"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/wisnuanggoro/go-getstream/config"
+	"github.com/wisnuanggoro/go-getstream/handler"
+	"github.com/wisnuanggoro/go-getstream/health"
+	"github.com/wisnuanggoro/go-getstream/openapi"
+	"github.com/wisnuanggoro/go-getstream/tenant"
+)
+
+// TestRoutesMatchOpenAPI fails when the gin routes and the OpenAPI document
+// drift apart. GraphQL has its own schema, the others aren't part of the API.
+func TestRoutesMatchOpenAPI(t *testing.T) {
+	cfg := config.Get()
+	topology, err := loadTopology(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	registry := prometheus.NewRegistry()
+	testTenant := tenant.Tenant{ID: "test", APIKey: "key", APISecret: "secret"}
+	tenants, err := tenant.NewRegistry([]tenant.Tenant{testTenant})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	app, err := newTenantApp(tenantDeps{
+		cfg:      cfg,
+		topology: topology,
+		logger:   slog.Default(),
+		registry: registry,
+	}, testTenant)
+	if err != nil {
+		t.Fatal(err)
+	}
+	healthHandler := handler.NewHealthHandler(health.NewChecker(cfg.HealthCheckTimeout, cfg.HealthCheckCacheTTL))
+	router := newRouter(cfg, slog.Default(), registry, healthHandler, tenants, map[string]http.Handler{testTenant.ID: app.router})
+
+	routes := append(router.Routes(), app.router.Routes()...)
+	err = openapi.CheckRoutes(routes, "/metrics", "/openapi.json", "/docs", "/docs/assets/*file", "/graphql")
+	if err != nil {
+		t.Fatal(err)
+	}
+}
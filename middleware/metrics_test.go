@@ -0,0 +1,45 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestMetrics(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(Metrics(registry))
+	router.GET("/post/:userSerial/summary", func(c *gin.Context) {
+		if c.Param("userSerial") == "0" {
+			c.Status(http.StatusBadRequest)
+			return
+		}
+		c.Status(http.StatusOK)
+	})
+
+	for _, path := range []string{"/post/1/summary", "/post/2/summary", "/post/0/summary", "/wp-login.php", "/.env"} {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	// Series are labeled by route template, unknown paths share one
+	want := `
+# HELP http_requests_total HTTP requests handled, by method, route and status.
+# TYPE http_requests_total counter
+http_requests_total{method="GET",route="/post/:userSerial/summary",status="200"} 2
+http_requests_total{method="GET",route="/post/:userSerial/summary",status="400"} 1
+http_requests_total{method="GET",route="unmatched",status="404"} 2
+`
+	if err := testutil.GatherAndCompare(registry, strings.NewReader(want), "http_requests_total"); err != nil {
+		t.Error(err)
+	}
+	if got, err := testutil.GatherAndCount(registry, "http_request_duration_seconds"); err != nil || got != 3 {
+		t.Errorf("duration series = %d, %v, want 3", got, err)
+	}
+}
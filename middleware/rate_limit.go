@@ -0,0 +1,83 @@
+package middleware
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/wisnuanggoro/go-getstream/ratelimit"
+	"github.com/wisnuanggoro/go-getstream/response"
+)
+
+// The API has no authentication, write routes name the acting user in
+// one of these query params instead
+var actingUserParams = []string{"ownUserSerial", "likerUserSerial", "userSerial"}
+
+// RateLimit limits requests per acting user and per client IP with token
+// buckets. Limits are keyed by `<METHOD> <route template>`, routes without
+// one aren't limited. The stricter of both buckets is reported in the
+// `RateLimit-*` headers.
+func RateLimit(limiter *ratelimit.Limiter, userLimits, ipLimits map[string]ratelimit.Limit) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		route := c.Request.Method + " " + c.FullPath()
+
+		results := []ratelimit.Result{}
+		if limit, ok := userLimits[route]; ok {
+			if userSerial := actingUser(c); userSerial != "" {
+				results = append(results, limiter.Allow("user:"+userSerial+":"+route, limit))
+			}
+		}
+		if limit, ok := ipLimits[route]; ok {
+			results = append(results, limiter.Allow("ip:"+c.ClientIP()+":"+route, limit))
+		}
+		if len(results) == 0 {
+			c.Next()
+			return
+		}
+
+		result := strictest(results)
+		c.Header("RateLimit-Limit", strconv.Itoa(result.Limit))
+		c.Header("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+		c.Header("RateLimit-Reset", ceilSeconds(result.Reset))
+
+		if !result.Allowed {
+			c.Header("Retry-After", ceilSeconds(result.RetryAfter))
+			response.AddToContext(c, http.StatusTooManyRequests, "rate limit exceeded, retry in "+ceilSeconds(result.RetryAfter)+"s", nil)
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+func actingUser(c *gin.Context) string {
+	for _, param := range actingUserParams {
+		if userSerial := c.Query(param); userSerial != "" {
+			return userSerial
+		}
+	}
+	return ""
+}
+
+// strictest returns a denied result if there is one, otherwise the one with
+// the fewest remaining requests
+func strictest(results []ratelimit.Result) ratelimit.Result {
+	strictest := results[0]
+	for _, result := range results[1:] {
+		switch {
+		case strictest.Allowed && !result.Allowed:
+			strictest = result
+		case strictest.Allowed == result.Allowed && result.Remaining < strictest.Remaining:
+			strictest = result
+		}
+	}
+	return strictest
+}
+
+func ceilSeconds(d time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(d.Seconds())))
+}
@@ -0,0 +1,126 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/wisnuanggoro/go-getstream/ratelimit"
+)
+
+func newRateLimitedRouter(userLimits, ipLimits map[string]ratelimit.Limit) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(RateLimit(ratelimit.NewLimiter(), userLimits, ipLimits))
+	router.POST("/post", func(c *gin.Context) { c.Status(http.StatusOK) })
+	router.DELETE("/like/:reactionID", func(c *gin.Context) { c.Status(http.StatusOK) })
+	router.GET("/timeline", func(c *gin.Context) { c.Status(http.StatusOK) })
+	return router
+}
+
+func serve(router *gin.Engine, method, target, remoteAddr string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, nil)
+	req.RemoteAddr = remoteAddr
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestRateLimit(t *testing.T) {
+	perMinute := func(n int) ratelimit.Limit { return ratelimit.Limit{Requests: n, Period: time.Minute} }
+
+	type request struct {
+		method, target, remoteAddr string
+		wantCode                   int
+	}
+	tests := []struct {
+		name       string
+		userLimits map[string]ratelimit.Limit
+		ipLimits   map[string]ratelimit.Limit
+		requests   []request
+	}{
+		{
+			name:       "per user",
+			userLimits: map[string]ratelimit.Limit{"POST /post": perMinute(1)},
+			requests: []request{
+				{"POST", "/post?userSerial=1", "10.0.0.1:1", http.StatusOK},
+				{"POST", "/post?userSerial=1", "10.0.0.2:1", http.StatusTooManyRequests},
+				{"POST", "/post?userSerial=2", "10.0.0.1:1", http.StatusOK},
+				// Without an acting user only IP limits apply
+				{"POST", "/post", "10.0.0.1:1", http.StatusOK},
+			},
+		},
+		{
+			name:     "per IP",
+			ipLimits: map[string]ratelimit.Limit{"POST /post": perMinute(1)},
+			requests: []request{
+				{"POST", "/post?userSerial=1", "10.0.0.1:1", http.StatusOK},
+				{"POST", "/post?userSerial=2", "10.0.0.1:2", http.StatusTooManyRequests},
+				{"POST", "/post?userSerial=1", "10.0.0.2:1", http.StatusOK},
+			},
+		},
+		{
+			name:       "stricter bucket wins",
+			userLimits: map[string]ratelimit.Limit{"POST /post": perMinute(5)},
+			ipLimits:   map[string]ratelimit.Limit{"POST /post": perMinute(1)},
+			requests: []request{
+				{"POST", "/post?userSerial=1", "10.0.0.1:1", http.StatusOK},
+				{"POST", "/post?userSerial=1", "10.0.0.1:1", http.StatusTooManyRequests},
+			},
+		},
+		{
+			name:     "route templates with params",
+			ipLimits: map[string]ratelimit.Limit{"DELETE /like/:reactionID": perMinute(1)},
+			requests: []request{
+				{"DELETE", "/like/a", "10.0.0.1:1", http.StatusOK},
+				{"DELETE", "/like/b", "10.0.0.1:1", http.StatusTooManyRequests},
+			},
+		},
+		{
+			name:     "unlimited routes",
+			ipLimits: map[string]ratelimit.Limit{"POST /post": perMinute(1)},
+			requests: []request{
+				{"GET", "/timeline", "10.0.0.1:1", http.StatusOK},
+				{"GET", "/timeline", "10.0.0.1:1", http.StatusOK},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := newRateLimitedRouter(tt.userLimits, tt.ipLimits)
+			for i, r := range tt.requests {
+				if w := serve(router, r.method, r.target, r.remoteAddr); w.Code != r.wantCode {
+					t.Errorf("request %d: %s %s from %s = %d, want %d", i, r.method, r.target, r.remoteAddr, w.Code, r.wantCode)
+				}
+			}
+		})
+	}
+}
+
+func TestRateLimitHeaders(t *testing.T) {
+	router := newRateLimitedRouter(nil, map[string]ratelimit.Limit{"POST /post": {Requests: 2, Period: time.Minute}})
+
+	w := serve(router, "POST", "/post", "10.0.0.1:1")
+	wantHeaders := map[string]string{"RateLimit-Limit": "2", "RateLimit-Remaining": "1", "RateLimit-Reset": "30", "Retry-After": ""}
+	for header, want := range wantHeaders {
+		if got := w.Header().Get(header); got != want {
+			t.Errorf("allowed: %s = %q, want %q", header, got, want)
+		}
+	}
+
+	serve(router, "POST", "/post", "10.0.0.1:1")
+	w = serve(router, "POST", "/post", "10.0.0.1:1")
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("code = %d, want 429", w.Code)
+	}
+	wantHeaders = map[string]string{"RateLimit-Limit": "2", "RateLimit-Remaining": "0", "RateLimit-Reset": "60", "Retry-After": "30"}
+	for header, want := range wantHeaders {
+		if got := w.Header().Get(header); got != want {
+			t.Errorf("denied: %s = %q, want %q", header, got, want)
+		}
+	}
+}
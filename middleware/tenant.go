@@ -0,0 +1,35 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/wisnuanggoro/go-getstream/response"
+	"github.com/wisnuanggoro/go-getstream/tenant"
+)
+
+// Tenant resolves the tenant served on the request host, else the one named
+// in header, and hands the request over to the router of that tenant
+func Tenant(registry *tenant.Registry, header string, routers map[string]http.Handler) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(header)
+		t, err := registry.Resolve(id, c.Request.Host)
+		if err != nil {
+			switch {
+			case errors.Is(err, tenant.ErrTenantMismatch):
+				response.AddToContext(c, http.StatusForbidden, header+" doesn't match the tenant of this host", nil)
+			case id != "":
+				response.AddToContext(c, http.StatusNotFound, "unknown tenant "+id, nil)
+			default:
+				response.AddToContext(c, http.StatusBadRequest, header+" is mandatory on this host", nil)
+			}
+			c.Abort()
+			return
+		}
+
+		c.Request = c.Request.WithContext(tenant.WithID(c.Request.Context(), t.ID))
+		routers[t.ID].ServeHTTP(c.Writer, c.Request)
+	}
+}
@@ -0,0 +1,69 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/wisnuanggoro/go-getstream/tenant"
+)
+
+func TestTenant(t *testing.T) {
+	registry, err := tenant.NewRegistry([]tenant.Tenant{
+		{ID: "acme", APIKey: "key", APISecret: "secret", Hosts: []string{"acme.example.com"}},
+		{ID: "globex", APIKey: "key", APISecret: "secret"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Each tenant router answers with its tenant, setting the status as
+	// gin has already set the 404 of NoRoute
+	routers := map[string]http.Handler{}
+	for _, id := range []string{"acme", "globex"} {
+		routers[id] = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(tenant.ID(r.Context())))
+		})
+	}
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.NoRoute(Tenant(registry, "X-Tenant-ID", routers))
+
+	tests := []struct {
+		name       string
+		host       string
+		header     string
+		wantCode   int
+		wantTenant string
+	}{
+		{name: "host", host: "acme.example.com", wantCode: http.StatusOK, wantTenant: "acme"},
+		{name: "header matching the host", host: "acme.example.com", header: "acme", wantCode: http.StatusOK, wantTenant: "acme"},
+		{name: "header overriding the host", host: "acme.example.com", header: "globex", wantCode: http.StatusForbidden},
+		{name: "header on an unmapped host", host: "localhost", header: "globex", wantCode: http.StatusOK, wantTenant: "globex"},
+		{name: "unknown tenant", host: "localhost", header: "umbrella", wantCode: http.StatusNotFound},
+		{name: "no tenant", host: "localhost", wantCode: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/api/v1/timeline", nil)
+			req.Host = tt.host
+			if tt.header != "" {
+				req.Header.Set("X-Tenant-ID", tt.header)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.wantCode {
+				t.Fatalf("code = %d, want %d", w.Code, tt.wantCode)
+			}
+			if tt.wantTenant != "" && w.Body.String() != tt.wantTenant {
+				t.Errorf("served by %q, want %q", w.Body.String(), tt.wantTenant)
+			}
+		})
+	}
+}
@@ -0,0 +1,10 @@
+#!/bin/sh
+# Vendors the Swagger UI assets served under /docs, run through
+# `go generate ./openapi` after bumping swagger-ui/VERSION
+set -eu
+
+cd "$(dirname "$0")/swagger-ui"
+version=$(cat VERSION)
+for file in swagger-ui.css swagger-ui-bundle.js LICENSE; do
+	curl -fsSL -o "$file" "https://unpkg.com/swagger-ui-dist@${version}/${file}"
+done
@@ -0,0 +1,119 @@
+package openapi
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+//go:embed openapi.json
+var spec []byte
+
+//go:embed swagger.html
+var swaggerUI []byte
+
+//go:generate sh fetch_swagger_ui.sh
+
+// Swagger UI assets, vendored so `/docs` works offline and under a strict CSP
+//
+//go:embed swagger-ui
+var swaggerAssets embed.FS
+
+var swaggerAssetsFS, _ = fs.Sub(swaggerAssets, "swagger-ui")
+
+// Spec returns the OpenAPI document describing the routes
+func Spec() []byte {
+	return spec
+}
+
+// Handler serves the OpenAPI document
+func Handler(c *gin.Context) {
+	c.Data(http.StatusOK, "application/json", spec)
+}
+
+// UIHandler serves Swagger UI pointing at specURL, loading its assets from
+// assetsURL where AssetsHandler serves them
+func UIHandler(specURL, assetsURL string) gin.HandlerFunc {
+	// A checkout that didn't run `go generate` yet falls back to the CDN
+	if _, err := fs.Stat(swaggerAssetsFS, "swagger-ui-bundle.js"); err != nil {
+		version, _ := fs.ReadFile(swaggerAssetsFS, "VERSION")
+		assetsURL = "https://unpkg.com/swagger-ui-dist@" + strings.TrimSpace(string(version))
+	}
+
+	page := strings.NewReplacer("{{SPEC_URL}}", specURL, "{{ASSETS_URL}}", assetsURL).Replace(string(swaggerUI))
+	return func(c *gin.Context) {
+		c.Data(http.StatusOK, "text/html; charset=utf-8", []byte(page))
+	}
+}
+
+// AssetsHandler serves the vendored Swagger UI asset named by the `file`
+// path parameter
+func AssetsHandler(c *gin.Context) {
+	c.FileFromFS(strings.TrimPrefix(c.Param("file"), "/"), http.FS(swaggerAssetsFS))
+}
+
+// CheckRoutes returns an error listing the registered routes missing from
+// the document and the documented operations no route serves. Paths in
+// undocumented, e.g. `/metrics`, are left out of the comparison.
+func CheckRoutes(routes gin.RoutesInfo, undocumented ...string) error {
+	var document struct {
+		Paths map[string]map[string]json.RawMessage `json:"paths"`
+	}
+	if err := json.Unmarshal(spec, &document); err != nil {
+		return fmt.Errorf("parse OpenAPI document: %w", err)
+	}
+
+	skip := map[string]bool{}
+	for _, path := range undocumented {
+		skip[path] = true
+	}
+
+	documented := map[string]bool{}
+	for path, operations := range document.Paths {
+		for method := range operations {
+			documented[strings.ToUpper(method)+" "+path] = true
+		}
+	}
+
+	missing := []string{}
+	for _, route := range routes {
+		if skip[route.Path] {
+			continue
+		}
+		operation := route.Method + " " + openAPIPath(route.Path)
+		if !documented[operation] {
+			missing = append(missing, operation)
+		}
+		delete(documented, operation)
+	}
+
+	stale := []string{}
+	for operation := range documented {
+		stale = append(stale, operation)
+	}
+
+	if len(missing) == 0 && len(stale) == 0 {
+		return nil
+	}
+	sort.Strings(missing)
+	sort.Strings(stale)
+	return fmt.Errorf("OpenAPI document is out of date, undocumented routes: [%s], documented but not routed: [%s]",
+		strings.Join(missing, ", "), strings.Join(stale, ", "))
+}
+
+// openAPIPath turns gin params like `:userSerial` into `{userSerial}`
+func openAPIPath(path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if strings.HasPrefix(segment, ":") || strings.HasPrefix(segment, "*") {
+			segments[i] = "{" + segment[1:] + "}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
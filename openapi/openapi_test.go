@@ -0,0 +1,102 @@
+package openapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestCheckRoutes(t *testing.T) {
+	// Routes of every documented operation
+	documented := gin.RoutesInfo{}
+	for _, operation := range documentedOperations(t) {
+		method, path, _ := strings.Cut(operation, " ")
+		documented = append(documented, gin.RouteInfo{Method: method, Path: ginPath(path)})
+	}
+
+	tests := []struct {
+		name    string
+		routes  gin.RoutesInfo
+		wantErr string
+	}{
+		{name: "in sync", routes: documented},
+		{
+			name:    "undocumented route",
+			routes:  append(documented[:len(documented):len(documented)], gin.RouteInfo{Method: "GET", Path: "/api/v1/undocumented/:id"}),
+			wantErr: "GET /api/v1/undocumented/{id}",
+		},
+		{
+			name:    "documented operation without route",
+			routes:  documented[1:],
+			wantErr: "documented but not routed: [" + documented[0].Method + " " + openAPIPath(documented[0].Path) + "]",
+		},
+		{
+			name:   "skipped paths are ignored",
+			routes: append(documented[:len(documented):len(documented)], gin.RouteInfo{Method: "GET", Path: "/metrics"}),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := CheckRoutes(tt.routes, "/metrics")
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("err = %v, want it to mention %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestUIHandler(t *testing.T) {
+	router := gin.New()
+	router.GET("/docs", UIHandler("/openapi.json", "/docs/assets"))
+	router.GET("/docs/assets/*file", AssetsHandler)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/docs", nil))
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `url: "/openapi.json"`) {
+		t.Fatalf("docs page: %d %s", w.Code, w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/docs/assets/VERSION", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("asset: %d", w.Code)
+	}
+}
+
+func documentedOperations(t *testing.T) []string {
+	t.Helper()
+
+	var document struct {
+		Paths map[string]map[string]json.RawMessage `json:"paths"`
+	}
+	if err := json.Unmarshal(Spec(), &document); err != nil {
+		t.Fatal(err)
+	}
+
+	operations := []string{}
+	for path, methods := range document.Paths {
+		for method := range methods {
+			operations = append(operations, strings.ToUpper(method)+" "+path)
+		}
+	}
+	sort.Strings(operations)
+	return operations
+}
+
+// ginPath turns `/like/{postID}` into `/like/:postID`
+func ginPath(path string) string {
+	path = strings.ReplaceAll(path, "{", ":")
+	return strings.ReplaceAll(path, "}", "")
+}
@@ -0,0 +1,167 @@
+package pubsub
+
+import (
+	"sync"
+	"time"
+)
+
+// Event is a message published to a topic. IDs increase across every topic
+// and start from the boot time in nanoseconds, so they keep increasing
+// across restarts and can be used to resume a subscription.
+type Event struct {
+	ID   uint64
+	Type string
+	Data []byte
+	Time time.Time
+}
+
+// Subscription receives the events published to a topic after it was
+// created. Events is closed when the subscription is closed, or when the
+// subscriber falls behind by more than the buffer size; it should then
+// subscribe again from the last event it received.
+type Subscription struct {
+	broker *Broker
+	topic  string
+	events chan Event
+	closed bool
+}
+
+func (s *Subscription) Events() <-chan Event {
+	return s.events
+}
+
+func (s *Subscription) Close() {
+	s.broker.mu.Lock()
+	defer s.broker.mu.Unlock()
+
+	s.broker.unsubscribe(s)
+}
+
+type topic struct {
+	history     []Event
+	subscribers map[*Subscription]struct{}
+}
+
+// Broker is an in-process pub/sub keeping the recent events of each topic,
+// so subscribers reconnecting shortly after a drop don't miss any
+type Broker struct {
+	historySize int
+	historyTTL  time.Duration
+	bufferSize  int
+
+	mu        sync.Mutex
+	lastID    uint64
+	topics    map[string]*topic
+	lastSweep time.Time
+}
+
+// NewBroker keeps up to historySize events per topic for historyTTL and
+// buffers up to bufferSize events per subscriber
+func NewBroker(historySize int, historyTTL time.Duration, bufferSize int) *Broker {
+	now := time.Now()
+	return &Broker{
+		historySize: historySize,
+		historyTTL:  historyTTL,
+		bufferSize:  bufferSize,
+		lastID:      uint64(now.UnixNano()),
+		topics:      map[string]*topic{},
+		lastSweep:   now,
+	}
+}
+
+// Publish sends an event to every subscriber of topicName without blocking
+func (b *Broker) Publish(topicName, eventType string, data []byte) Event {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := time.Now()
+	b.sweep(now)
+
+	b.lastID++
+	event := Event{ID: b.lastID, Type: eventType, Data: data, Time: now}
+
+	t := b.topic(topicName)
+	t.history = append(t.history, event)
+	if len(t.history) > b.historySize {
+		t.history = t.history[len(t.history)-b.historySize:]
+	}
+
+	for subscription := range t.subscribers {
+		select {
+		case subscription.events <- event:
+		default:
+			// A slow subscriber must not hold back the others
+			b.unsubscribe(subscription)
+		}
+	}
+	return event
+}
+
+// Subscribe returns a subscription to topicName along with the events kept
+// in history after lastEventID, none when lastEventID is zero
+func (b *Broker) Subscribe(topicName string, lastEventID uint64) (*Subscription, []Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	t := b.topic(topicName)
+	missed := []Event{}
+	if lastEventID > 0 {
+		for _, event := range t.history {
+			if event.ID > lastEventID && time.Since(event.Time) < b.historyTTL {
+				missed = append(missed, event)
+			}
+		}
+	}
+
+	subscription := &Subscription{
+		broker: b,
+		topic:  topicName,
+		events: make(chan Event, b.bufferSize),
+	}
+	t.subscribers[subscription] = struct{}{}
+	return subscription, missed
+}
+
+func (b *Broker) topic(topicName string) *topic {
+	t, ok := b.topics[topicName]
+	if !ok {
+		t = &topic{subscribers: map[*Subscription]struct{}{}}
+		b.topics[topicName] = t
+	}
+	return t
+}
+
+func (b *Broker) unsubscribe(subscription *Subscription) {
+	if subscription.closed {
+		return
+	}
+	subscription.closed = true
+	close(subscription.events)
+
+	if t, ok := b.topics[subscription.topic]; ok {
+		delete(t.subscribers, subscription)
+	}
+}
+
+// sweep drops expired history and topics nobody listens to anymore, at most
+// once per history TTL
+func (b *Broker) sweep(now time.Time) {
+	if now.Sub(b.lastSweep) < b.historyTTL {
+		return
+	}
+	b.lastSweep = now
+
+	for name, t := range b.topics {
+		kept := t.history[:0]
+		for _, event := range t.history {
+			if now.Sub(event.Time) < b.historyTTL {
+				kept = append(kept, event)
+			}
+		}
+		t.history = kept
+
+		if len(t.history) == 0 && len(t.subscribers) == 0 {
+			delete(b.topics, name)
+		}
+	}
+}
@@ -0,0 +1,133 @@
+package pubsub
+
+import (
+	"testing"
+	"time"
+)
+
+// publishAll publishes one event per type to topic and returns their IDs
+func publishAll(b *Broker, topic string, types ...string) []uint64 {
+	ids := []uint64{}
+	for _, eventType := range types {
+		ids = append(ids, b.Publish(topic, eventType, nil).ID)
+	}
+	return ids
+}
+
+func eventTypes(events []Event) []string {
+	types := []string{}
+	for _, event := range events {
+		types = append(types, event.Type)
+	}
+	return types
+}
+
+func equal(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestBrokerResume(t *testing.T) {
+	tests := []struct {
+		name        string
+		historySize int
+		// resumeAfter is the index of the last received event, -1 for none
+		resumeAfter int
+		wantMissed  []string
+	}{
+		{name: "new subscription gets no history", historySize: 10, resumeAfter: -1, wantMissed: []string{}},
+		{name: "resume gets what came after", historySize: 10, resumeAfter: 0, wantMissed: []string{"b", "c", "d"}},
+		{name: "resume from the latest gets nothing", historySize: 10, resumeAfter: 3, wantMissed: []string{}},
+		{name: "history is capped", historySize: 2, resumeAfter: 0, wantMissed: []string{"c", "d"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := NewBroker(tt.historySize, time.Minute, 10)
+			ids := publishAll(b, "timeline:1", "a", "b", "c", "d")
+			publishAll(b, "timeline:2", "other")
+
+			var lastEventID uint64
+			if tt.resumeAfter >= 0 {
+				lastEventID = ids[tt.resumeAfter]
+			}
+			subscription, missed := b.Subscribe("timeline:1", lastEventID)
+			defer subscription.Close()
+
+			if got := eventTypes(missed); !equal(got, tt.wantMissed) {
+				t.Errorf("missed = %v, want %v", got, tt.wantMissed)
+			}
+		})
+	}
+}
+
+func TestBrokerHistoryTTL(t *testing.T) {
+	b := NewBroker(10, 20*time.Millisecond, 10)
+	ids := publishAll(b, "timeline:1", "old")
+	time.Sleep(30 * time.Millisecond)
+	publishAll(b, "timeline:1", "new")
+
+	subscription, missed := b.Subscribe("timeline:1", ids[0]-1)
+	defer subscription.Close()
+	if got := eventTypes(missed); !equal(got, []string{"new"}) {
+		t.Errorf("missed = %v, want only the event within the TTL", got)
+	}
+}
+
+func TestBrokerIDsIncrease(t *testing.T) {
+	b := NewBroker(10, time.Minute, 10)
+	ids := append(publishAll(b, "timeline:1", "a"), publishAll(b, "timeline:2", "b")...)
+
+	if ids[1] <= ids[0] {
+		t.Errorf("IDs %v don't increase across topics", ids)
+	}
+	// IDs start from the boot time so they keep increasing across restarts
+	if restarted := NewBroker(10, time.Minute, 10).Publish("timeline:1", "c", nil); restarted.ID <= ids[1] {
+		t.Errorf("ID %d after a restart isn't after %d", restarted.ID, ids[1])
+	}
+}
+
+func TestBrokerSubscription(t *testing.T) {
+	b := NewBroker(10, time.Minute, 2)
+	fast, _ := b.Subscribe("timeline:1", 0)
+	slow, _ := b.Subscribe("timeline:1", 0)
+	other, _ := b.Subscribe("timeline:2", 0)
+	defer other.Close()
+
+	// The slow subscriber's buffer of 2 overflows, the fast one keeps reading
+	for _, eventType := range []string{"a", "b", "c"} {
+		b.Publish("timeline:1", eventType, nil)
+		if event := <-fast.Events(); event.Type != eventType {
+			t.Fatalf("fast got %q, want %q", event.Type, eventType)
+		}
+	}
+
+	received := []string{}
+	for event := range slow.Events() {
+		received = append(received, event.Type)
+	}
+	if !equal(received, []string{"a", "b"}) {
+		t.Errorf("slow got %v before being dropped, want [a b]", received)
+	}
+
+	select {
+	case event := <-other.Events():
+		t.Errorf("other topic got %q", event.Type)
+	default:
+	}
+
+	// Closing twice, or after being dropped, is harmless
+	fast.Close()
+	fast.Close()
+	slow.Close()
+	if _, ok := <-fast.Events(); ok {
+		t.Error("closed subscription still receives")
+	}
+}
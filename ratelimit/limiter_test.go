@@ -0,0 +1,108 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseRules(t *testing.T) {
+	tests := []struct {
+		name    string
+		rules   []string
+		want    map[string]Limit
+		wantErr bool
+	}{
+		{
+			name:  "routes with params",
+			rules: []string{"POST /api/v1/post=10/1m", " DELETE /api/v1/like/:reactionID=5/1s"},
+			want: map[string]Limit{
+				"POST /api/v1/post":               {Requests: 10, Period: time.Minute},
+				"DELETE /api/v1/like/:reactionID": {Requests: 5, Period: time.Second},
+			},
+		},
+		{name: "no rules", rules: nil, want: map[string]Limit{}},
+		{name: "missing limit", rules: []string{"POST /api/v1/post"}, wantErr: true},
+		{name: "missing method", rules: []string{"/api/v1/post=10/1m"}, wantErr: true},
+		{name: "unknown method", rules: []string{"SEND /api/v1/post=10/1m"}, wantErr: true},
+		{name: "relative route", rules: []string{"POST api/v1/post=10/1m"}, wantErr: true},
+		{name: "duplicate route", rules: []string{"POST /api/v1/post=10/1m", "POST /api/v1/post=20/1m"}, wantErr: true},
+		{name: "no requests", rules: []string{"POST /api/v1/post=0/1m"}, wantErr: true},
+		{name: "no period", rules: []string{"POST /api/v1/post=10"}, wantErr: true},
+		{name: "negative period", rules: []string{"POST /api/v1/post=10/-1m"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseRules(tt.rules)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, want error %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %v, want %v", got, tt.want)
+			}
+			for route, limit := range tt.want {
+				if got[route] != limit {
+					t.Errorf("%s = %v, want %v", route, got[route], limit)
+				}
+			}
+		})
+	}
+}
+
+func TestLimiterAllow(t *testing.T) {
+	limiter := NewLimiter()
+	limit := Limit{Requests: 2, Period: 100 * time.Millisecond}
+
+	for i, wantRemaining := range []int{1, 0} {
+		result := limiter.Allow("a", limit)
+		if !result.Allowed || result.Remaining != wantRemaining || result.Limit != 2 {
+			t.Fatalf("request %d: got %+v, want allowed with %d remaining", i, result, wantRemaining)
+		}
+	}
+
+	result := limiter.Allow("a", limit)
+	if result.Allowed {
+		t.Fatal("empty bucket allowed a request")
+	}
+	if result.RetryAfter <= 0 || result.RetryAfter > 50*time.Millisecond {
+		t.Errorf("retry after = %v, want within one token's refill of 50ms", result.RetryAfter)
+	}
+	if result.Reset <= 0 || result.Reset > limit.Period {
+		t.Errorf("reset = %v, want within the period", result.Reset)
+	}
+
+	// Buckets are per key
+	if !limiter.Allow("b", limit).Allowed {
+		t.Fatal("another key shared the empty bucket")
+	}
+
+	// A token refills every 50ms
+	time.Sleep(60 * time.Millisecond)
+	if !limiter.Allow("a", limit).Allowed {
+		t.Fatal("bucket didn't refill")
+	}
+	if limiter.Allow("a", limit).Allowed {
+		t.Fatal("bucket refilled more than one token")
+	}
+}
+
+func TestLimiterSweep(t *testing.T) {
+	limiter := NewLimiter()
+	limit := Limit{Requests: 1, Period: 20 * time.Millisecond}
+
+	limiter.Allow("idle", limit)
+	time.Sleep(30 * time.Millisecond)
+	limiter.Allow("active", limit)
+
+	limiter.mu.Lock()
+	defer limiter.mu.Unlock()
+	if _, ok := limiter.buckets["idle"]; ok {
+		t.Error("idle bucket wasn't swept")
+	}
+	if _, ok := limiter.buckets["active"]; !ok {
+		t.Error("active bucket was swept")
+	}
+}
@@ -0,0 +1,88 @@
+package realtime
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+
+	"github.com/wisnuanggoro/go-getstream/getstream"
+	"github.com/wisnuanggoro/go-getstream/pubsub"
+)
+
+// Hub turns the events of the Service into pub/sub events of the topics
+// realtime clients subscribe to
+type Hub struct {
+	getstreamSvc getstream.Service
+	broker       *pubsub.Broker
+}
+
+func NewHub(getstreamSvc getstream.Service, broker *pubsub.Broker) *Hub {
+	return &Hub{
+		getstreamSvc: getstreamSvc,
+		broker:       broker,
+	}
+}
+
+// OnEvent is a getstream.EventListener
+func (h *Hub) OnEvent(ctx context.Context, event getstream.Event) {
+	switch event.Type {
+	case getstream.EventPostCreated:
+		if event.Activity == nil {
+			return
+		}
+		// Looking up followers can take a while, the post is published already
+		go h.publishToTimelines(context.WithoutCancel(ctx), event.UserSerial, EventActivityAdded, event.Activity)
+	case getstream.EventPostDeleted:
+		go h.publishToTimelines(context.WithoutCancel(ctx), event.UserSerial, EventActivityRemoved, ActivityRemoved{
+			PostID:     event.PostID,
+			UserSerial: event.UserSerial,
+		})
+	case getstream.EventLikeAdded, getstream.EventLikeRemoved:
+		change := ReactionChange{
+			PostID:     event.PostID,
+			ReactionID: event.ReactionID,
+			UserSerial: event.UserSerial,
+			Kind:       "like",
+			Delta:      1,
+		}
+		if event.Type == getstream.EventLikeRemoved {
+			change.Delta = -1
+		}
+		h.publish(ctx, PostTopic(event.PostID), EventReaction, change)
+
+		// Authors aren't notified of their own likes
+		if event.Type == getstream.EventLikeAdded && event.TargetSerial != event.UserSerial {
+			h.publish(ctx, NotificationTopic(event.TargetSerial), EventNotification, event)
+		}
+	case getstream.EventFollowed, getstream.EventFollowRequested:
+		h.publish(ctx, NotificationTopic(event.TargetSerial), EventNotification, event)
+	}
+}
+
+func (h *Hub) publish(ctx context.Context, topic, eventType string, v any) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		slog.WarnContext(ctx, "encode realtime event", slog.String("topic", topic), slog.String("error", err.Error()))
+		return
+	}
+	h.broker.Publish(topic, eventType, data)
+}
+
+// publishToTimelines sends v to the timeline of every follower of userSerial
+func (h *Hub) publishToTimelines(ctx context.Context, userSerial, eventType string, v any) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		slog.WarnContext(ctx, "encode realtime event", slog.String("type", eventType), slog.String("error", err.Error()))
+		return
+	}
+
+	followers, err := h.getstreamSvc.GetTimelineFollowersByUserSerial(ctx, userSerial)
+	if err != nil {
+		slog.WarnContext(ctx, "publish realtime event", slog.String("type", eventType), slog.String("userSerial", userSerial), slog.String("error", err.Error()))
+		return
+	}
+
+	for _, follower := range followers {
+		h.broker.Publish(TimelineTopic(follower), eventType, data)
+	}
+}
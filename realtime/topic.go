@@ -0,0 +1,76 @@
+package realtime
+
+import (
+	"errors"
+	"strings"
+)
+
+var (
+	ErrUnknownFeed   = errors.New("unknown feed")
+	ErrFeedForbidden = errors.New("feed belongs to another user")
+)
+
+// Types of the events published to the topics
+const (
+	// Timeline topics, data is the activity
+	EventActivityAdded = "activity"
+	// Timeline topics, data is an ActivityRemoved
+	EventActivityRemoved = "activity_removed"
+	// Post topics, data is a ReactionChange
+	EventReaction = "reaction"
+	// Notification topics, data is the getstream.Event
+	EventNotification = "notification"
+)
+
+// ActivityRemoved tells timelines a post they showed is gone
+type ActivityRemoved struct {
+	PostID     string `json:"postID"`
+	UserSerial string `json:"userSerial"`
+}
+
+// ReactionChange updates the reaction count of a post by Delta
+type ReactionChange struct {
+	PostID     string `json:"postID"`
+	ReactionID string `json:"reactionID"`
+	UserSerial string `json:"userSerial"`
+	Kind       string `json:"kind"`
+	Delta      int    `json:"delta"`
+}
+
+// TimelineTopic carries the new and removed activities of a user's timeline
+func TimelineTopic(userSerial string) string {
+	return "timeline:" + userSerial
+}
+
+// NotificationTopic carries follows, follow requests and likes a user receives
+func NotificationTopic(userSerial string) string {
+	return "notification:" + userSerial
+}
+
+// PostTopic carries the reaction changes of a post
+func PostTopic(postID string) string {
+	return "post:" + postID
+}
+
+// Authorize reports whether userSerial may subscribe to topic. Timelines
+// and notifications are private to their user, reactions of a post are
+// public like the likes endpoint. It trusts userSerial, callers must have
+// authenticated it for this to protect anything.
+func Authorize(userSerial, topic string) error {
+	group, id, _ := strings.Cut(topic, ":")
+	if id == "" {
+		return ErrUnknownFeed
+	}
+
+	switch group {
+	case "timeline", "notification":
+		if id != userSerial {
+			return ErrFeedForbidden
+		}
+		return nil
+	case "post":
+		return nil
+	default:
+		return ErrUnknownFeed
+	}
+}
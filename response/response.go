@@ -0,0 +1,20 @@
+package response
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AddToContext writes the JSON envelope every API response is wrapped in
+func AddToContext(ctx *gin.Context, code int, detail string, data interface{}) {
+	ctx.JSON(
+		code,
+		gin.H{
+			"data":    data,
+			"status":  code,
+			"message": http.StatusText(code),
+			"detail":  detail,
+		},
+	)
+}
@@ -0,0 +1,30 @@
+package schedule
+
+import "time"
+
+// Status is the lifecycle state of a scheduled post.
+type Status string
+
+const (
+	StatusPending    Status = "pending"
+	StatusPublishing Status = "publishing"
+	StatusPublished  Status = "published"
+	StatusCancelled  Status = "cancelled"
+	StatusFailed     Status = "failed"
+)
+
+// Post is a post waiting to be published to a user feed at PublishAt.
+type Post struct {
+	ID          string    `json:"id"`
+	UserSerial  string    `json:"userSerial"`
+	PostContent string    `json:"postContent"`
+	PostType    string    `json:"postType"`
+	PublishAt   time.Time `json:"publishAt"`
+	Status      Status    `json:"status"`
+	PostID      string    `json:"postID,omitempty"`
+	// Tries counts publish attempts, Error is the reason the last one failed
+	Tries     int       `json:"tries,omitempty"`
+	Error     string    `json:"error,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
@@ -0,0 +1,126 @@
+package schedule
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// fileStore keeps scheduled posts in memory and writes a JSON snapshot to
+// disk after every mutation, so pending posts survive restarts
+type fileStore struct {
+	mu   sync.Mutex
+	path string
+	mem  *memoryStore
+}
+
+// NewFileStore returns a Store backed by the JSON file at path
+func NewFileStore(path string) (Store, error) {
+	s := &fileStore{
+		path: path,
+		mem:  newMemoryStore(),
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var posts []Post
+	if err := json.Unmarshal(data, &posts); err != nil {
+		return nil, err
+	}
+	for _, post := range posts {
+		// A post left `publishing` was interrupted by a restart, try it again
+		// as publishing it twice keeps a single post
+		if post.Status == StatusPublishing {
+			post.Status = StatusPending
+		}
+		s.mem.posts[post.ID] = post
+	}
+
+	return s, nil
+}
+
+func (f *fileStore) Create(post Post) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.flush(f.snapshot(post)); err != nil {
+		return err
+	}
+	return f.mem.Create(post)
+}
+
+func (f *fileStore) Get(id string) (Post, error) {
+	return f.mem.Get(id)
+}
+
+func (f *fileStore) Update(id string, fn func(post *Post) error) (Post, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	// Change a copy, memory only takes it once it's on disk
+	post, err := f.mem.Get(id)
+	if err != nil {
+		return Post{}, err
+	}
+	if err := fn(&post); err != nil {
+		return Post{}, err
+	}
+	if err := f.flush(f.snapshot(post)); err != nil {
+		return Post{}, err
+	}
+	return f.mem.Update(id, func(stored *Post) error {
+		*stored = post
+		return nil
+	})
+}
+
+func (f *fileStore) ListByUserSerial(userSerial string) ([]Post, error) {
+	return f.mem.ListByUserSerial(userSerial)
+}
+
+func (f *fileStore) ListDue(now time.Time) ([]Post, error) {
+	return f.mem.ListDue(now)
+}
+
+// snapshot lists the stored posts with post in place of the stored one
+func (f *fileStore) snapshot(post Post) []Post {
+	posts := f.mem.filter(func(stored Post) bool { return stored.ID != post.ID })
+	return append(posts, post)
+}
+
+// flush writes posts to a temporary file and renames it over the previous
+// snapshot so a crash never leaves a half-written file behind
+func (f *fileStore) flush(posts []Post) error {
+	data, err := json.Marshal(posts)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(f.path), filepath.Base(f.path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), f.path)
+}
@@ -0,0 +1,90 @@
+package schedule
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestFileStoreReload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "schedule.json")
+	store, err := NewFileStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	publishAt := time.Now().Add(-time.Minute).UTC().Truncate(time.Second)
+	for _, post := range []Post{
+		{ID: "pending", PublishAt: publishAt, Status: StatusPending},
+		{ID: "publishing", PublishAt: publishAt, Status: StatusPending},
+		{ID: "published", PublishAt: publishAt, Status: StatusPending},
+	} {
+		if err := store.Create(post); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for id, status := range map[string]Status{"publishing": StatusPublishing, "published": StatusPublished} {
+		if _, err := store.Update(id, func(post *Post) error {
+			post.Status = status
+			return nil
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	reloaded, err := NewFileStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Posts left publishing by a restart are tried again
+	wantStatus := map[string]Status{"pending": StatusPending, "publishing": StatusPending, "published": StatusPublished}
+	for id, want := range wantStatus {
+		post, err := reloaded.Get(id)
+		if err != nil {
+			t.Fatalf("%s: %v", id, err)
+		}
+		if post.Status != want || !post.PublishAt.Equal(publishAt) {
+			t.Errorf("%s: got %q at %v, want %q at %v", id, post.Status, post.PublishAt, want, publishAt)
+		}
+	}
+}
+
+func TestFileStoreFailedFlush(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "schedule")
+	if err := os.Mkdir(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	store, err := NewFileStore(filepath.Join(dir, "schedule.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Create(Post{ID: "1", Status: StatusPending}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Snapshots can't be written anymore
+	if err := os.RemoveAll(dir); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := store.Update("1", func(post *Post) error {
+		post.Status = StatusCancelled
+		return nil
+	}); err == nil {
+		t.Fatal("update succeeded without its snapshot")
+	}
+	if err := store.Create(Post{ID: "2", Status: StatusPending}); err == nil {
+		t.Fatal("create succeeded without its snapshot")
+	}
+
+	post, err := store.Get("1")
+	if err != nil || post.Status != StatusPending {
+		t.Errorf("got %q, %v, want the post still pending", post.Status, err)
+	}
+	if _, err := store.Get("2"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("err = %v, want ErrNotFound for the post that wasn't saved", err)
+	}
+}
@@ -0,0 +1,116 @@
+package schedule
+
+import (
+	"errors"
+	"time"
+
+	"github.com/wisnuanggoro/go-getstream/uid"
+)
+
+var ErrPublishAtInPast = errors.New("publishAt must be in the future")
+
+type service struct {
+	store Store
+}
+
+type Service interface {
+	SchedulePost(userSerial, postContent, postType string, publishAt time.Time) (*Post, error)
+	GetScheduledPostsByUserSerial(userSerial string) ([]Post, error)
+	CancelScheduledPost(userSerial, scheduleID string) (*Post, error)
+	ReschedulePost(userSerial, scheduleID string, publishAt time.Time) (*Post, error)
+}
+
+func NewService(store Store) Service {
+	return &service{
+		store: store,
+	}
+}
+
+func (s *service) SchedulePost(userSerial, postContent, postType string, publishAt time.Time) (*Post, error) {
+	now := time.Now()
+	if !publishAt.After(now) {
+		return nil, ErrPublishAtInPast
+	}
+
+	post := Post{
+		ID:          uid.New(),
+		UserSerial:  userSerial,
+		PostContent: postContent,
+		PostType:    postType,
+		PublishAt:   publishAt.UTC(),
+		Status:      StatusPending,
+		CreatedAt:   now.UTC(),
+		UpdatedAt:   now.UTC(),
+	}
+	if err := s.store.Create(post); err != nil {
+		return nil, err
+	}
+
+	return &post, nil
+}
+
+func (s *service) GetScheduledPostsByUserSerial(userSerial string) ([]Post, error) {
+	// Only posts still waiting to be published
+	posts, err := s.store.ListByUserSerial(userSerial)
+	if err != nil {
+		return nil, err
+	}
+
+	pending := []Post{}
+	for _, post := range posts {
+		if post.Status == StatusPending {
+			pending = append(pending, post)
+		}
+	}
+	return pending, nil
+}
+
+func (s *service) CancelScheduledPost(userSerial, scheduleID string) (*Post, error) {
+	post, err := s.store.Update(scheduleID, func(post *Post) error {
+		if err := checkPending(post, userSerial); err != nil {
+			return err
+		}
+
+		post.Status = StatusCancelled
+		post.UpdatedAt = time.Now().UTC()
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &post, nil
+}
+
+func (s *service) ReschedulePost(userSerial, scheduleID string, publishAt time.Time) (*Post, error) {
+	if !publishAt.After(time.Now()) {
+		return nil, ErrPublishAtInPast
+	}
+
+	post, err := s.store.Update(scheduleID, func(post *Post) error {
+		if err := checkPending(post, userSerial); err != nil {
+			return err
+		}
+
+		post.PublishAt = publishAt.UTC()
+		post.UpdatedAt = time.Now().UTC()
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &post, nil
+}
+
+// checkPending makes sure the post belongs to userSerial and can still be changed
+func checkPending(post *Post, userSerial string) error {
+	// Don't reveal scheduled posts of other users
+	if post.UserSerial != userSerial {
+		return ErrNotFound
+	}
+	if post.Status != StatusPending {
+		return ErrNotPending
+	}
+	return nil
+}
@@ -0,0 +1,118 @@
+package schedule
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+var (
+	ErrNotFound   = errors.New("scheduled post not found")
+	ErrNotPending = errors.New("scheduled post is no longer pending")
+)
+
+// Store persists scheduled posts
+type Store interface {
+	Create(post Post) error
+	Get(id string) (Post, error)
+	// Update applies fn to the stored post atomically and saves the result
+	// unless fn returns an error
+	Update(id string, fn func(post *Post) error) (Post, error)
+	ListByUserSerial(userSerial string) ([]Post, error)
+	// ListDue returns pending posts whose PublishAt is not after now
+	ListDue(now time.Time) ([]Post, error)
+}
+
+// NewStore builds the store selected by kind, either `memory` or `file`
+func NewStore(kind, filePath string) (Store, error) {
+	switch kind {
+	case "", "memory":
+		return NewMemoryStore(), nil
+	case "file":
+		return NewFileStore(filePath)
+	default:
+		return nil, fmt.Errorf("unknown schedule store %q", kind)
+	}
+}
+
+type memoryStore struct {
+	mu    sync.RWMutex
+	posts map[string]Post
+}
+
+// NewMemoryStore returns a Store that keeps scheduled posts in memory only
+func NewMemoryStore() Store {
+	return newMemoryStore()
+}
+
+func newMemoryStore() *memoryStore {
+	return &memoryStore{
+		posts: map[string]Post{},
+	}
+}
+
+func (m *memoryStore) Create(post Post) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.posts[post.ID] = post
+	return nil
+}
+
+func (m *memoryStore) Get(id string) (Post, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	post, ok := m.posts[id]
+	if !ok {
+		return Post{}, ErrNotFound
+	}
+	return post, nil
+}
+
+func (m *memoryStore) Update(id string, fn func(post *Post) error) (Post, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	post, ok := m.posts[id]
+	if !ok {
+		return Post{}, ErrNotFound
+	}
+	if err := fn(&post); err != nil {
+		return Post{}, err
+	}
+	m.posts[id] = post
+	return post, nil
+}
+
+func (m *memoryStore) ListByUserSerial(userSerial string) ([]Post, error) {
+	return m.filter(func(post Post) bool {
+		return post.UserSerial == userSerial
+	}), nil
+}
+
+func (m *memoryStore) ListDue(now time.Time) ([]Post, error) {
+	return m.filter(func(post Post) bool {
+		return post.Status == StatusPending && !post.PublishAt.After(now)
+	}), nil
+}
+
+func (m *memoryStore) filter(keep func(post Post) bool) []Post {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	posts := []Post{}
+	for _, post := range m.posts {
+		if keep(post) {
+			posts = append(posts, post)
+		}
+	}
+
+	// Earliest publish time first
+	sort.Slice(posts, func(i, j int) bool {
+		return posts[i].PublishAt.Before(posts[j].PublishAt)
+	})
+	return posts
+}
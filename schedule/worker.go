@@ -0,0 +1,111 @@
+package schedule
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/wisnuanggoro/go-getstream/getstream"
+	"github.com/wisnuanggoro/go-getstream/logging"
+)
+
+// Worker publishes scheduled posts once their publish time has come, trying
+// failed ones again on the next polls up to maxAttempts times
+type Worker struct {
+	store        Store
+	getstreamSvc getstream.Service
+	interval     time.Duration
+	maxAttempts  int
+}
+
+func NewWorker(store Store, getstreamSvc getstream.Service, interval time.Duration, maxAttempts int) *Worker {
+	return &Worker{
+		store:        store,
+		getstreamSvc: getstreamSvc,
+		interval:     interval,
+		maxAttempts:  max(maxAttempts, 1),
+	}
+}
+
+// Run polls the store every interval until ctx is cancelled
+func (w *Worker) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		w.publishDue(ctx, time.Now())
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (w *Worker) publishDue(ctx context.Context, now time.Time) {
+	posts, err := w.store.ListDue(now)
+	if err != nil {
+		slog.ErrorContext(ctx, "list due scheduled posts", slog.String("error", err.Error()))
+		return
+	}
+
+	for _, post := range posts {
+		// The schedule ID ties the Service call logs to the scheduled post
+		w.publish(logging.WithRequestID(ctx, "schedule-"+post.ID), post.ID)
+	}
+}
+
+func (w *Worker) publish(ctx context.Context, scheduleID string) {
+	// Claim the post so a concurrent cancel or reschedule can't race the publish
+	post, err := w.store.Update(scheduleID, func(post *Post) error {
+		if post.Status != StatusPending {
+			return ErrNotPending
+		}
+		// Rescheduled to later since it was listed as due
+		if post.PublishAt.After(time.Now()) {
+			return ErrNotPending
+		}
+
+		// Restarts while publishing count as attempts too
+		post.UpdatedAt = time.Now().UTC()
+		if post.Tries >= w.maxAttempts {
+			post.Status = StatusFailed
+			return nil
+		}
+		post.Tries++
+		post.Status = StatusPublishing
+		return nil
+	})
+	if err != nil || post.Status != StatusPublishing {
+		return
+	}
+
+	// Publishing is idempotent, a post left `publishing` by a failed update
+	// or a restart is published again without being duplicated
+	resp, publishErr := w.getstreamSvc.AddScheduledPostByUserSerial(ctx, post.UserSerial, post.ID, post.PostContent, post.PostType, post.PublishAt)
+
+	_, err = w.store.Update(scheduleID, func(post *Post) error {
+		post.UpdatedAt = time.Now().UTC()
+		switch {
+		case publishErr == nil:
+			post.Status = StatusPublished
+			post.PostID = resp.ID
+			post.Error = ""
+		// Shutting down isn't Stream's fault, try again after the restart
+		case ctx.Err() != nil:
+			post.Status = StatusPending
+			post.Tries--
+		case post.Tries >= w.maxAttempts:
+			post.Status = StatusFailed
+			post.Error = publishErr.Error()
+		default:
+			post.Status = StatusPending
+			post.Error = publishErr.Error()
+		}
+		return nil
+	})
+	if err != nil {
+		slog.ErrorContext(ctx, "update scheduled post", slog.String("schedule_id", scheduleID), slog.String("error", err.Error()))
+	}
+}
@@ -0,0 +1,238 @@
+package schedule
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	stream "gopkg.in/GetStream/stream-go2.v3"
+
+	"github.com/wisnuanggoro/go-getstream/getstream"
+)
+
+// publishingService records the posts published by the worker, failing
+// with the next error of errs first
+type publishingService struct {
+	getstream.Service
+
+	mu    sync.Mutex
+	posts []string
+	errs  []error
+}
+
+func (s *publishingService) AddScheduledPostByUserSerial(ctx context.Context, userSerial, scheduleID, postContent, postType string, publishAt time.Time) (*stream.AddActivityResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.errs) > 0 {
+		err := s.errs[0]
+		s.errs = s.errs[1:]
+		return nil, err
+	}
+	s.posts = append(s.posts, "schedule:"+scheduleID)
+	resp := &stream.AddActivityResponse{}
+	resp.ID = "activity-" + postContent
+	return resp, nil
+}
+
+func TestWorkerPublishDue(t *testing.T) {
+	now := time.Now()
+	due := now.Add(-time.Minute)
+	down := errors.New("stream is down")
+
+	tests := []struct {
+		name string
+		post Post
+		// publishErrs fail the Service calls, the worker tries twice
+		publishErrs   []error
+		wantStatus    Status
+		wantTries     int
+		wantPublished bool
+		wantPostID    string
+		wantError     string
+	}{
+		{
+			name:          "due post is published",
+			post:          Post{ID: "1", PostContent: "due", PublishAt: due, Status: StatusPending},
+			wantStatus:    StatusPublished,
+			wantTries:     1,
+			wantPublished: true,
+			wantPostID:    "activity-due",
+		},
+		{
+			name:        "failed publish waits for the next poll",
+			post:        Post{ID: "1", PostContent: "due", PublishAt: due, Status: StatusPending},
+			publishErrs: []error{down},
+			wantStatus:  StatusPending,
+			wantTries:   1,
+			wantError:   "stream is down",
+		},
+		{
+			name:        "last failed attempt is kept with its error",
+			post:        Post{ID: "1", PostContent: "due", PublishAt: due, Status: StatusPending, Tries: 1},
+			publishErrs: []error{down},
+			wantStatus:  StatusFailed,
+			wantTries:   2,
+			wantError:   "stream is down",
+		},
+		{
+			name:       "post out of attempts after restarts",
+			post:       Post{ID: "1", PostContent: "due", PublishAt: due, Status: StatusPending, Tries: 2, Error: "stream is down"},
+			wantStatus: StatusFailed,
+			wantTries:  2,
+			wantError:  "stream is down",
+		},
+		{
+			name:       "future post waits",
+			post:       Post{ID: "1", PostContent: "later", PublishAt: now.Add(time.Hour), Status: StatusPending},
+			wantStatus: StatusPending,
+		},
+		{
+			name:       "cancelled post is skipped",
+			post:       Post{ID: "1", PostContent: "cancelled", PublishAt: due, Status: StatusCancelled},
+			wantStatus: StatusCancelled,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := NewMemoryStore()
+			if err := store.Create(tt.post); err != nil {
+				t.Fatal(err)
+			}
+			svc := &publishingService{errs: tt.publishErrs}
+
+			NewWorker(store, svc, time.Minute, 2).publishDue(context.Background(), now)
+
+			post, err := store.Get(tt.post.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if post.Status != tt.wantStatus || post.Tries != tt.wantTries || post.PostID != tt.wantPostID || post.Error != tt.wantError {
+				t.Errorf("got status %q, tries %d, post ID %q, error %q, want %q, %d, %q, %q", post.Status, post.Tries, post.PostID, post.Error, tt.wantStatus, tt.wantTries, tt.wantPostID, tt.wantError)
+			}
+			if published := len(svc.posts) > 0; published != tt.wantPublished {
+				t.Errorf("published = %v, want %v", published, tt.wantPublished)
+			}
+		})
+	}
+}
+
+func TestWorkerRetriesFailedPublish(t *testing.T) {
+	store := NewMemoryStore()
+	if err := store.Create(Post{ID: "1", PostContent: "due", PublishAt: time.Now().Add(-time.Minute), Status: StatusPending}); err != nil {
+		t.Fatal(err)
+	}
+	down := errors.New("stream is down")
+	svc := &publishingService{errs: []error{down, down}}
+	worker := NewWorker(store, svc, time.Minute, 3)
+
+	for i := 0; i < 3; i++ {
+		worker.publishDue(context.Background(), time.Now())
+	}
+
+	post, err := store.Get("1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if post.Status != StatusPublished || post.Tries != 3 || post.Error != "" {
+		t.Errorf("got status %q, tries %d, error %q, want published on the third try", post.Status, post.Tries, post.Error)
+	}
+	if len(svc.posts) != 1 {
+		t.Errorf("published %d times, want once", len(svc.posts))
+	}
+}
+
+func TestWorkerShutdownKeepsAttempt(t *testing.T) {
+	store := NewMemoryStore()
+	if err := store.Create(Post{ID: "1", PostContent: "due", PublishAt: time.Now().Add(-time.Minute), Status: StatusPending}); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	svc := &publishingService{errs: []error{context.Canceled}}
+
+	NewWorker(store, svc, time.Minute, 1).publishDue(ctx, time.Now())
+
+	post, err := store.Get("1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if post.Status != StatusPending || post.Tries != 0 {
+		t.Errorf("got status %q, tries %d, want pending with no attempt used", post.Status, post.Tries)
+	}
+}
+
+func TestWorkerRepublishesAfterRestart(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "schedule.json")
+	store, err := NewFileStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The process stopped after publishing, before saving the outcome
+	post := Post{ID: "1", PostContent: "due", PublishAt: time.Now().Add(-time.Minute), Status: StatusPublishing, Tries: 1}
+	if err := store.Create(post); err != nil {
+		t.Fatal(err)
+	}
+	store, err = NewFileStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	svc := &publishingService{}
+
+	NewWorker(store, svc, time.Minute, 3).publishDue(context.Background(), time.Now())
+
+	// Published again under the same foreign ID, Stream keeps a single post
+	if len(svc.posts) != 1 || svc.posts[0] != "schedule:1" {
+		t.Fatalf("published %v, want schedule:1", svc.posts)
+	}
+	post, err = store.Get("1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if post.Status != StatusPublished || post.Tries != 2 {
+		t.Errorf("got status %q, tries %d, want published on the second try", post.Status, post.Tries)
+	}
+}
+
+func TestWorkerPublishRechecksClaim(t *testing.T) {
+	tests := []struct {
+		name   string
+		change func(post *Post)
+	}{
+		{name: "rescheduled after listing", change: func(post *Post) { post.PublishAt = time.Now().Add(time.Hour) }},
+		{name: "cancelled after listing", change: func(post *Post) { post.Status = StatusCancelled }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := NewMemoryStore()
+			if err := store.Create(Post{ID: "1", PublishAt: time.Now().Add(-time.Minute), Status: StatusPending}); err != nil {
+				t.Fatal(err)
+			}
+			svc := &publishingService{}
+			worker := NewWorker(store, svc, time.Minute, 1)
+
+			// The post was listed as due, then changed before the worker claimed it
+			due, err := store.ListDue(time.Now())
+			if err != nil || len(due) != 1 {
+				t.Fatalf("due = %v, %v, want the post", due, err)
+			}
+			if _, err := store.Update("1", func(post *Post) error {
+				tt.change(post)
+				return nil
+			}); err != nil {
+				t.Fatal(err)
+			}
+			worker.publish(context.Background(), due[0].ID)
+
+			if len(svc.posts) != 0 {
+				t.Fatal("changed post was published")
+			}
+		})
+	}
+}
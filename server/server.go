@@ -0,0 +1,74 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/wisnuanggoro/go-getstream/config"
+)
+
+// Server is the HTTP server of this service, draining connections on shutdown
+type Server struct {
+	httpServer      *http.Server
+	tlsCertFile     string
+	tlsKeyFile      string
+	shutdownTimeout time.Duration
+}
+
+func New(cfg config.Config, handler http.Handler) *Server {
+	return &Server{
+		httpServer: &http.Server{
+			Addr:              net.JoinHostPort(cfg.Host, cfg.Port),
+			Handler:           handler,
+			ReadTimeout:       cfg.ServerReadTimeout,
+			ReadHeaderTimeout: cfg.ServerReadTimeout,
+			WriteTimeout:      cfg.ServerWriteTimeout,
+			IdleTimeout:       cfg.ServerIdleTimeout,
+		},
+		tlsCertFile:     cfg.TLSCertFile,
+		tlsKeyFile:      cfg.TLSKeyFile,
+		shutdownTimeout: cfg.ServerShutdownTimeout,
+	}
+}
+
+// Run serves until ctx is cancelled, then waits up to the shutdown timeout
+// for in-flight requests to finish
+func (s *Server) Run(ctx context.Context) error {
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- s.listenAndServe()
+	}()
+
+	select {
+	case err := <-serveErr:
+		return err
+	case <-ctx.Done():
+	}
+
+	slog.Info("shutting down server, draining connections", slog.Duration("timeout", s.shutdownTimeout))
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), s.shutdownTimeout)
+	defer cancel()
+
+	return s.httpServer.Shutdown(shutdownCtx)
+}
+
+func (s *Server) listenAndServe() error {
+	var err error
+	if s.tlsCertFile != "" && s.tlsKeyFile != "" {
+		slog.Info("server listening", slog.String("addr", s.httpServer.Addr), slog.Bool("tls", true))
+		err = s.httpServer.ListenAndServeTLS(s.tlsCertFile, s.tlsKeyFile)
+	} else {
+		slog.Info("server listening", slog.String("addr", s.httpServer.Addr), slog.Bool("tls", false))
+		err = s.httpServer.ListenAndServe()
+	}
+
+	// Shutdown makes the listener return ErrServerClosed, that's not a failure
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,147 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/wisnuanggoro/go-getstream/config"
+)
+
+func TestNewListenAddress(t *testing.T) {
+	tests := []struct {
+		host string
+		want string
+	}{
+		{host: "", want: ":8080"},
+		{host: "127.0.0.1", want: "127.0.0.1:8080"},
+		{host: "::1", want: "[::1]:8080"},
+	}
+
+	for _, tt := range tests {
+		s := New(config.Config{Host: tt.host, Port: "8080"}, http.NotFoundHandler())
+		if s.httpServer.Addr != tt.want {
+			t.Errorf("host %q: addr = %q, want %q", tt.host, s.httpServer.Addr, tt.want)
+		}
+	}
+}
+
+// freePort returns a port nothing listens on
+func freePort(t *testing.T) string {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	return strconv.Itoa(listener.Addr().(*net.TCPAddr).Port)
+}
+
+// slowServer runs a server whose requests wait for release, it returns the
+// outcome of Run and a channel closed once a request is in flight
+func slowServer(t *testing.T, ctx context.Context, shutdownTimeout time.Duration, release <-chan struct{}) (addr string, runErr <-chan error, inFlight <-chan struct{}) {
+	started := make(chan struct{})
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+		io.WriteString(w, "done")
+	})
+
+	cfg := config.Config{Host: "127.0.0.1", Port: freePort(t), ServerShutdownTimeout: shutdownTimeout}
+	errs := make(chan error, 1)
+	go func() { errs <- New(cfg, handler).Run(ctx) }()
+
+	addr = net.JoinHostPort(cfg.Host, cfg.Port)
+	for i := 0; ; i++ {
+		conn, err := net.Dial("tcp", addr)
+		if err == nil {
+			conn.Close()
+			break
+		}
+		if i == 100 {
+			t.Fatalf("server isn't listening: %v", err)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	return addr, errs, started
+}
+
+func TestRunDrainsInFlightRequests(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	release := make(chan struct{})
+	addr, runErr, inFlight := slowServer(t, ctx, 5*time.Second, release)
+
+	type result struct {
+		body string
+		err  error
+	}
+	results := make(chan result, 1)
+	go func() {
+		resp, err := http.Get("http://" + addr + "/")
+		if err != nil {
+			results <- result{err: err}
+			return
+		}
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		results <- result{body: string(body), err: err}
+	}()
+	<-inFlight
+
+	// Shutting down waits for the request
+	cancel()
+	select {
+	case err := <-runErr:
+		t.Fatalf("Run returned %v with a request in flight", err)
+	case <-time.After(100 * time.Millisecond):
+	}
+	if _, err := net.Dial("tcp", addr); err == nil {
+		t.Error("new connections are accepted while shutting down")
+	}
+
+	close(release)
+	if r := <-results; r.err != nil || r.body != "done" {
+		t.Errorf("in-flight request got %q, %v, want done", r.body, r.err)
+	}
+	if err := <-runErr; err != nil {
+		t.Errorf("Run = %v, want a clean shutdown", err)
+	}
+}
+
+func TestRunShutdownTimeout(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	release := make(chan struct{})
+	defer close(release)
+	addr, runErr, inFlight := slowServer(t, ctx, 50*time.Millisecond, release)
+
+	go http.Get("http://" + addr + "/")
+	<-inFlight
+
+	cancel()
+	select {
+	case err := <-runErr:
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("Run = %v, want the shutdown timeout", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Run kept waiting past the shutdown timeout")
+	}
+}
+
+func TestRunListenError(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	port := strconv.Itoa(listener.Addr().(*net.TCPAddr).Port)
+
+	cfg := config.Config{Host: "127.0.0.1", Port: port, ServerShutdownTimeout: time.Second}
+	if err := New(cfg, http.NotFoundHandler()).Run(context.Background()); err == nil {
+		t.Error("Run served on a port already in use")
+	}
+}
@@ -0,0 +1,79 @@
+package streamwebhook
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// Dispatcher hands each event to the handlers registered for its type.
+// Stream retries callbacks it couldn't deliver, so handlers may see an
+// event more than once.
+type Dispatcher struct {
+	mu              sync.RWMutex
+	activityAdded   []func(ctx context.Context, event ActivityAdded) error
+	activityRemoved []func(ctx context.Context, event ActivityRemoved) error
+	reactionAdded   []func(ctx context.Context, event ReactionAdded) error
+	reactionRemoved []func(ctx context.Context, event ReactionRemoved) error
+}
+
+func NewDispatcher() *Dispatcher {
+	return &Dispatcher{}
+}
+
+func (d *Dispatcher) OnActivityAdded(handler func(ctx context.Context, event ActivityAdded) error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.activityAdded = append(d.activityAdded, handler)
+}
+
+func (d *Dispatcher) OnActivityRemoved(handler func(ctx context.Context, event ActivityRemoved) error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.activityRemoved = append(d.activityRemoved, handler)
+}
+
+func (d *Dispatcher) OnReactionAdded(handler func(ctx context.Context, event ReactionAdded) error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.reactionAdded = append(d.reactionAdded, handler)
+}
+
+func (d *Dispatcher) OnReactionRemoved(handler func(ctx context.Context, event ReactionRemoved) error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.reactionRemoved = append(d.reactionRemoved, handler)
+}
+
+// Dispatch runs every handler of every event, a failing handler doesn't
+// keep the others from running. The errors are returned joined.
+func (d *Dispatcher) Dispatch(ctx context.Context, events []any) error {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	errs := []error{}
+	for _, event := range events {
+		switch event := event.(type) {
+		case ActivityAdded:
+			errs = append(errs, run(ctx, d.activityAdded, event)...)
+		case ActivityRemoved:
+			errs = append(errs, run(ctx, d.activityRemoved, event)...)
+		case ReactionAdded:
+			errs = append(errs, run(ctx, d.reactionAdded, event)...)
+		case ReactionRemoved:
+			errs = append(errs, run(ctx, d.reactionRemoved, event)...)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func run[T any](ctx context.Context, handlers []func(ctx context.Context, event T) error, event T) []error {
+	errs := []error{}
+	for _, handler := range handlers {
+		if err := handler(ctx, event); err != nil {
+			errs = append(errs, fmt.Errorf("%T: %w", event, err))
+		}
+	}
+	return errs
+}
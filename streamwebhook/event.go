@@ -0,0 +1,79 @@
+package streamwebhook
+
+import (
+	"encoding/json"
+	"time"
+
+	stream "gopkg.in/GetStream/stream-go2.v3"
+)
+
+// Types of the reaction callbacks, feed updates have no type
+const (
+	typeReactionNew     = "reaction.new"
+	typeReactionDeleted = "reaction.deleted"
+)
+
+// ActivityAdded is an activity Stream added to a feed, including the
+// timelines it fanned out to
+type ActivityAdded struct {
+	Feed        string
+	Activity    stream.Activity
+	PublishedAt time.Time
+}
+
+// ActivityRemoved is an activity Stream removed from a feed
+type ActivityRemoved struct {
+	Feed        string
+	ActivityID  string
+	PublishedAt time.Time
+}
+
+type ReactionAdded struct {
+	Reaction stream.Reaction
+}
+
+type ReactionRemoved struct {
+	Reaction stream.Reaction
+}
+
+// message is one entry of the JSON array Stream posts, either the
+// update of a feed or a reaction callback
+type message struct {
+	Type        string            `json:"type"`
+	Feed        string            `json:"feed"`
+	New         []stream.Activity `json:"new"`
+	Deleted     []string          `json:"deleted"`
+	PublishedAt stream.Time       `json:"published_at"`
+	Reaction    *stream.Reaction  `json:"reaction"`
+}
+
+// Parse turns the body of a callback into typed events, in the order
+// Stream sent them. Unknown callback types are skipped.
+func Parse(body []byte) ([]any, error) {
+	var messages []message
+	if err := json.Unmarshal(body, &messages); err != nil {
+		return nil, err
+	}
+
+	events := []any{}
+	for _, m := range messages {
+		switch m.Type {
+		case "":
+			for _, activity := range m.New {
+				events = append(events, ActivityAdded{Feed: m.Feed, Activity: activity, PublishedAt: m.PublishedAt.Time})
+			}
+			for _, activityID := range m.Deleted {
+				events = append(events, ActivityRemoved{Feed: m.Feed, ActivityID: activityID, PublishedAt: m.PublishedAt.Time})
+			}
+		case typeReactionNew:
+			if m.Reaction != nil {
+				events = append(events, ReactionAdded{Reaction: *m.Reaction})
+			}
+		case typeReactionDeleted:
+			if m.Reaction != nil {
+				events = append(events, ReactionRemoved{Reaction: *m.Reaction})
+			}
+		}
+	}
+	return events, nil
+}
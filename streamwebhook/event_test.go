@@ -0,0 +1,56 @@
+package streamwebhook
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	stream "gopkg.in/GetStream/stream-go2.v3"
+)
+
+func TestParse(t *testing.T) {
+	publishedAt := time.Date(2024, 5, 1, 10, 30, 0, 0, time.UTC)
+	like := stream.Reaction{ID: "r1", Kind: "like", ActivityID: "a1", UserID: "2"}
+
+	tests := []struct {
+		name    string
+		body    string
+		want    []any
+		wantErr bool
+	}{
+		{
+			name: "feed update",
+			body: `[{"feed":"timeline:2","new":[{"id":"a1","actor":"user:1","verb":"post"}],"deleted":["a0"],"published_at":"2024-05-01T10:30:00"}]`,
+			want: []any{
+				ActivityAdded{Feed: "timeline:2", Activity: stream.Activity{ID: "a1", Actor: "user:1", Verb: "post"}, PublishedAt: publishedAt},
+				ActivityRemoved{Feed: "timeline:2", ActivityID: "a0", PublishedAt: publishedAt},
+			},
+		},
+		{
+			name: "reactions in order",
+			body: `[{"type":"reaction.new","reaction":{"id":"r1","kind":"like","activity_id":"a1","user_id":"2"}},` +
+				`{"type":"reaction.deleted","reaction":{"id":"r1","kind":"like","activity_id":"a1","user_id":"2"}}]`,
+			want: []any{ReactionAdded{Reaction: like}, ReactionRemoved{Reaction: like}},
+		},
+		{
+			name: "unknown types and reactions without a body are skipped",
+			body: `[{"type":"reaction.updated","reaction":{"id":"r1"}},{"type":"reaction.new"}]`,
+			want: []any{},
+		},
+		{name: "empty batch", body: `[]`, want: []any{}},
+		{name: "not an array", body: `{"feed":"user:1"}`, wantErr: true},
+		{name: "bad time", body: `[{"feed":"user:1","published_at":"yesterday"}]`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse([]byte(tt.body))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, want error %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
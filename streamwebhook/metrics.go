@@ -0,0 +1,33 @@
+package streamwebhook
+
+import (
+	"context"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// RegisterMetrics counts the events dispatched by d, by type
+func RegisterMetrics(d *Dispatcher, registerer prometheus.Registerer) {
+	events := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "stream_webhook_events_total",
+		Help: "Events received from Stream callbacks, by type.",
+	}, []string{"type"})
+	registerer.MustRegister(events)
+
+	d.OnActivityAdded(func(ctx context.Context, event ActivityAdded) error {
+		events.WithLabelValues("activity_added").Inc()
+		return nil
+	})
+	d.OnActivityRemoved(func(ctx context.Context, event ActivityRemoved) error {
+		events.WithLabelValues("activity_removed").Inc()
+		return nil
+	})
+	d.OnReactionAdded(func(ctx context.Context, event ReactionAdded) error {
+		events.WithLabelValues("reaction_added").Inc()
+		return nil
+	})
+	d.OnReactionRemoved(func(ctx context.Context, event ReactionRemoved) error {
+		events.WithLabelValues("reaction_removed").Inc()
+		return nil
+	})
+}
@@ -0,0 +1,41 @@
+package streamwebhook
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSign(t *testing.T) {
+	// echo -n '[{"feed":"user:1"}]' | openssl dgst -sha256 -hmac secret
+	want := "b0b2f91dda6fca8bcc874aeb2599c0ec5a564cf6f680d5aefd249803015af726"
+	if got := Sign("secret", []byte(`[{"feed":"user:1"}]`)); got != want {
+		t.Errorf("Sign = %s, want %s", got, want)
+	}
+}
+
+func TestVerifySignature(t *testing.T) {
+	body := []byte(`[{"feed":"user:1"}]`)
+	const signature = "b0b2f91dda6fca8bcc874aeb2599c0ec5a564cf6f680d5aefd249803015af726"
+
+	tests := []struct {
+		name      string
+		secret    string
+		body      []byte
+		signature string
+		want      bool
+	}{
+		{name: "matching", secret: "secret", body: body, signature: signature, want: true},
+		{name: "other secret", secret: "other", body: body, signature: signature},
+		{name: "tampered body", secret: "secret", body: []byte(`[{"feed":"user:2"}]`), signature: signature},
+		{name: "uppercase hex isn't what Stream sends", secret: "secret", body: body, signature: strings.ToUpper(signature)},
+		{name: "missing", secret: "secret", body: body, signature: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := VerifySignature(tt.secret, tt.body, tt.signature); got != tt.want {
+				t.Errorf("VerifySignature = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
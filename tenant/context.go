@@ -0,0 +1,18 @@
+package tenant
+
+import (
+	"context"
+
+	"github.com/wisnuanggoro/go-getstream/logging"
+)
+
+// WithID returns a copy of ctx carrying the tenant ID, logs written with
+// it are tagged with the tenant
+func WithID(ctx context.Context, id string) context.Context {
+	return logging.WithTenant(ctx, id)
+}
+
+// ID returns the tenant ID carried by ctx, empty when there is none
+func ID(ctx context.Context) string {
+	return logging.Tenant(ctx)
+}
@@ -0,0 +1,295 @@
+package tenant
+
+import (
+	"context"
+	"time"
+
+	stream "gopkg.in/GetStream/stream-go2.v3"
+
+	"github.com/wisnuanggoro/go-getstream/getstream"
+)
+
+// pool routes every Service call to the Service of the tenant carried by
+// ctx, for APIs serving every tenant on one server
+type pool struct {
+	services map[string]getstream.Service
+}
+
+// NewPool takes the Service of each tenant by tenant ID
+func NewPool(services map[string]getstream.Service) getstream.Service {
+	return &pool{
+		services: services,
+	}
+}
+
+func (p *pool) service(ctx context.Context) (getstream.Service, error) {
+	svc, ok := p.services[ID(ctx)]
+	if !ok {
+		return nil, ErrUnknownTenant
+	}
+	return svc, nil
+}
+
+func (p *pool) AddPostByUserSerial(ctx context.Context, userSerial, postContent, postType string) (*stream.AddActivityResponse, error) {
+	svc, err := p.service(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return svc.AddPostByUserSerial(ctx, userSerial, postContent, postType)
+}
+
+func (p *pool) AddScheduledPostByUserSerial(ctx context.Context, userSerial, scheduleID, postContent, postType string, publishAt time.Time) (*stream.AddActivityResponse, error) {
+	svc, err := p.service(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return svc.AddScheduledPostByUserSerial(ctx, userSerial, scheduleID, postContent, postType, publishAt)
+}
+
+func (p *pool) GetPostByUserSerial(ctx context.Context, viewerUserSerial, userSerial string) (*stream.FlatFeedResponse, error) {
+	svc, err := p.service(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return svc.GetPostByUserSerial(ctx, viewerUserSerial, userSerial)
+}
+
+func (p *pool) GetPostDetailByUserSerial(ctx context.Context, viewerUserSerial, userSerial string) (*stream.EnrichedFlatFeedResponse, error) {
+	svc, err := p.service(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return svc.GetPostDetailByUserSerial(ctx, viewerUserSerial, userSerial)
+}
+
+func (p *pool) EditPostByPostID(ctx context.Context, userSerial, postID, postContent, postType string) (*stream.UpdateActivityResponse, error) {
+	svc, err := p.service(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return svc.EditPostByPostID(ctx, userSerial, postID, postContent, postType)
+}
+
+func (p *pool) GetPostHistoryByPostID(ctx context.Context, viewerUserSerial, postID string) ([]getstream.PostRevision, error) {
+	svc, err := p.service(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return svc.GetPostHistoryByPostID(ctx, viewerUserSerial, postID)
+}
+
+func (p *pool) DeletePostByPostID(ctx context.Context, userSerial, postID string) error {
+	svc, err := p.service(ctx)
+	if err != nil {
+		return err
+	}
+	return svc.DeletePostByPostID(ctx, userSerial, postID)
+}
+
+func (p *pool) GetTimelineByUserSerial(ctx context.Context, userSerial string) (*stream.FlatFeedResponse, error) {
+	svc, err := p.service(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return svc.GetTimelineByUserSerial(ctx, userSerial)
+}
+
+func (p *pool) GetDetailTimelineByUserSerial(ctx context.Context, userSerial string) (*stream.EnrichedFlatFeedResponse, error) {
+	svc, err := p.service(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return svc.GetDetailTimelineByUserSerial(ctx, userSerial)
+}
+
+func (p *pool) GetFeedFollowersByUserSerial(ctx context.Context, userSerial string) (*stream.FollowersResponse, error) {
+	svc, err := p.service(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return svc.GetFeedFollowersByUserSerial(ctx, userSerial)
+}
+
+func (p *pool) GetFollowedFeedsByUserSerial(ctx context.Context, userSerial string) (*stream.FollowingResponse, error) {
+	svc, err := p.service(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return svc.GetFollowedFeedsByUserSerial(ctx, userSerial)
+}
+
+func (p *pool) GetTimelineFollowersByUserSerial(ctx context.Context, userSerial string) ([]string, error) {
+	svc, err := p.service(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return svc.GetTimelineFollowersByUserSerial(ctx, userSerial)
+}
+
+func (p *pool) Follow(ctx context.Context, ownUserSerial, targetUserSerial string) (*getstream.FollowRequest, error) {
+	svc, err := p.service(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return svc.Follow(ctx, ownUserSerial, targetUserSerial)
+}
+
+func (p *pool) Unfollow(ctx context.Context, ownUserSerial, targetUserSerial string) error {
+	svc, err := p.service(ctx)
+	if err != nil {
+		return err
+	}
+	return svc.Unfollow(ctx, ownUserSerial, targetUserSerial)
+}
+
+func (p *pool) AddLikeToPostID(ctx context.Context, likerUserSerial, postID string) (*stream.Reaction, error) {
+	svc, err := p.service(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return svc.AddLikeToPostID(ctx, likerUserSerial, postID)
+}
+
+func (p *pool) RetrieveLikeDetailOnPostID(ctx context.Context, postID string, limit int) (*stream.FilterReactionResponse, error) {
+	svc, err := p.service(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return svc.RetrieveLikeDetailOnPostID(ctx, postID, limit)
+}
+
+func (p *pool) RetrieveLikeDetailOnPostIDWithPagination(ctx context.Context, postID, nextLikeID string, limit int) (*stream.FilterReactionResponse, error) {
+	svc, err := p.service(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return svc.RetrieveLikeDetailOnPostIDWithPagination(ctx, postID, nextLikeID, limit)
+}
+
+func (p *pool) RemoveLikeByReactionID(ctx context.Context, reactionID string) error {
+	svc, err := p.service(ctx)
+	if err != nil {
+		return err
+	}
+	return svc.RemoveLikeByReactionID(ctx, reactionID)
+}
+
+func (p *pool) BlockUser(ctx context.Context, ownUserSerial, targetUserSerial string) error {
+	svc, err := p.service(ctx)
+	if err != nil {
+		return err
+	}
+	return svc.BlockUser(ctx, ownUserSerial, targetUserSerial)
+}
+
+func (p *pool) UnblockUser(ctx context.Context, ownUserSerial, targetUserSerial string) error {
+	svc, err := p.service(ctx)
+	if err != nil {
+		return err
+	}
+	return svc.UnblockUser(ctx, ownUserSerial, targetUserSerial)
+}
+
+func (p *pool) GetBlockedUsersByUserSerial(ctx context.Context, userSerial string) ([]string, error) {
+	svc, err := p.service(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return svc.GetBlockedUsersByUserSerial(ctx, userSerial)
+}
+
+func (p *pool) MuteUser(ctx context.Context, ownUserSerial, targetUserSerial string) error {
+	svc, err := p.service(ctx)
+	if err != nil {
+		return err
+	}
+	return svc.MuteUser(ctx, ownUserSerial, targetUserSerial)
+}
+
+func (p *pool) UnmuteUser(ctx context.Context, ownUserSerial, targetUserSerial string) error {
+	svc, err := p.service(ctx)
+	if err != nil {
+		return err
+	}
+	return svc.UnmuteUser(ctx, ownUserSerial, targetUserSerial)
+}
+
+func (p *pool) GetMutedUsersByUserSerial(ctx context.Context, userSerial string) ([]string, error) {
+	svc, err := p.service(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return svc.GetMutedUsersByUserSerial(ctx, userSerial)
+}
+
+func (p *pool) SetPrivateAccount(ctx context.Context, userSerial string, private bool) error {
+	svc, err := p.service(ctx)
+	if err != nil {
+		return err
+	}
+	return svc.SetPrivateAccount(ctx, userSerial, private)
+}
+
+func (p *pool) GetIncomingFollowRequests(ctx context.Context, userSerial string) ([]getstream.FollowRequest, error) {
+	svc, err := p.service(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return svc.GetIncomingFollowRequests(ctx, userSerial)
+}
+
+func (p *pool) GetOutgoingFollowRequests(ctx context.Context, userSerial string) ([]getstream.FollowRequest, error) {
+	svc, err := p.service(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return svc.GetOutgoingFollowRequests(ctx, userSerial)
+}
+
+func (p *pool) ApproveFollowRequest(ctx context.Context, userSerial, requestID string) (*getstream.FollowRequest, error) {
+	svc, err := p.service(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return svc.ApproveFollowRequest(ctx, userSerial, requestID)
+}
+
+func (p *pool) RejectFollowRequest(ctx context.Context, userSerial, requestID string) (*getstream.FollowRequest, error) {
+	svc, err := p.service(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return svc.RejectFollowRequest(ctx, userSerial, requestID)
+}
+
+func (p *pool) GetFollowSuggestionsByUserSerial(ctx context.Context, userSerial string, limit int) ([]getstream.FollowSuggestion, error) {
+	svc, err := p.service(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return svc.GetFollowSuggestionsByUserSerial(ctx, userSerial, limit)
+}
+
+func (p *pool) GetRelationship(ctx context.Context, userSerial, otherSerial string) (*getstream.Relationship, error) {
+	svc, err := p.service(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return svc.GetRelationship(ctx, userSerial, otherSerial)
+}
+
+func (p *pool) GetRelationships(ctx context.Context, userSerial string, otherSerials []string) ([]getstream.Relationship, error) {
+	svc, err := p.service(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return svc.GetRelationships(ctx, userSerial, otherSerials)
+}
+
+func (p *pool) Ping(ctx context.Context) error {
+	svc, err := p.service(ctx)
+	if err != nil {
+		return err
+	}
+	return svc.Ping(ctx)
+}
@@ -0,0 +1,120 @@
+package tenant
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"regexp"
+	"strings"
+)
+
+var (
+	ErrUnknownTenant  = errors.New("unknown tenant")
+	ErrTenantMismatch = errors.New("tenant doesn't match the host")
+)
+
+// DefaultID names the single tenant configured by the GOSTREAM_* variables
+const DefaultID = "default"
+
+// IDs end up in file names, metric labels and logs
+var validID = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,62}$`)
+
+// Tenant is a brand with its own Stream app
+type Tenant struct {
+	ID        string `json:"id"`
+	APIKey    string `json:"apiKey"`
+	APISecret string `json:"apiSecret"`
+	APIRegion string `json:"apiRegion"`
+	// Hosts serving the tenant, requests to them belong to the tenant
+	// whatever tenant they name
+	Hosts []string `json:"hosts"`
+}
+
+// Registry holds the configured tenants
+type Registry struct {
+	tenants []Tenant
+	byID    map[string]Tenant
+	byHost  map[string]Tenant
+}
+
+func NewRegistry(tenants []Tenant) (*Registry, error) {
+	if len(tenants) == 0 {
+		return nil, errors.New("at least one tenant is needed")
+	}
+
+	r := &Registry{
+		tenants: tenants,
+		byID:    map[string]Tenant{},
+		byHost:  map[string]Tenant{},
+	}
+	for _, t := range tenants {
+		if !validID.MatchString(t.ID) {
+			return nil, fmt.Errorf("tenant ID %q must be lowercase letters, digits, `-` or `_`", t.ID)
+		}
+		if _, ok := r.byID[t.ID]; ok {
+			return nil, fmt.Errorf("tenant %q is configured twice", t.ID)
+		}
+		if t.APIKey == "" || t.APISecret == "" {
+			return nil, fmt.Errorf("tenant %q needs an apiKey and an apiSecret", t.ID)
+		}
+		r.byID[t.ID] = t
+
+		for _, host := range t.Hosts {
+			host = strings.ToLower(host)
+			if other, ok := r.byHost[host]; ok {
+				return nil, fmt.Errorf("host %q is served by tenants %q and %q", host, other.ID, t.ID)
+			}
+			r.byHost[host] = t
+		}
+	}
+	return r, nil
+}
+
+// LoadRegistry reads the tenants from a JSON array in the file at path
+func LoadRegistry(path string) (*Registry, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var tenants []Tenant
+	if err := json.Unmarshal(data, &tenants); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	return NewRegistry(tenants)
+}
+
+// Tenants returns every tenant in the order they were configured
+func (r *Registry) Tenants() []Tenant {
+	return r.tenants
+}
+
+// Resolve returns the tenant served on host, else the one named by id, else
+// the only tenant there is. The client names id, so it can't pick another
+// tenant than the one of the host.
+func (r *Registry) Resolve(id, host string) (Tenant, error) {
+	if hostname, _, err := net.SplitHostPort(host); err == nil {
+		host = hostname
+	}
+	if t, ok := r.byHost[strings.ToLower(host)]; ok {
+		if id != "" && id != t.ID {
+			return Tenant{}, ErrTenantMismatch
+		}
+		return t, nil
+	}
+
+	if id != "" {
+		t, ok := r.byID[id]
+		if !ok {
+			return Tenant{}, ErrUnknownTenant
+		}
+		return t, nil
+	}
+
+	if len(r.tenants) == 1 {
+		return r.tenants[0], nil
+	}
+	return Tenant{}, ErrUnknownTenant
+}
@@ -0,0 +1,50 @@
+package tenant
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestRegistryResolve(t *testing.T) {
+	acme := Tenant{ID: "acme", APIKey: "key", APISecret: "secret", Hosts: []string{"acme.example.com"}}
+	globex := Tenant{ID: "globex", APIKey: "key", APISecret: "secret", Hosts: []string{"Globex.example.com"}}
+	initech := Tenant{ID: "initech", APIKey: "key", APISecret: "secret"}
+
+	tests := []struct {
+		name    string
+		tenants []Tenant
+		id      string
+		host    string
+		want    string
+		wantErr error
+	}{
+		{name: "host", tenants: []Tenant{acme, globex}, host: "acme.example.com", want: "acme"},
+		{name: "host with port in any case", tenants: []Tenant{acme, globex}, host: "GLOBEX.example.com:8080", want: "globex"},
+		{name: "header naming the host's tenant", tenants: []Tenant{acme, globex}, id: "acme", host: "acme.example.com", want: "acme"},
+		{name: "header naming another tenant than the host's", tenants: []Tenant{acme, globex}, id: "globex", host: "acme.example.com:8080", wantErr: ErrTenantMismatch},
+		{name: "header naming an unknown tenant on a mapped host", tenants: []Tenant{acme, globex}, id: "umbrella", host: "acme.example.com", wantErr: ErrTenantMismatch},
+		{name: "header on an unmapped host", tenants: []Tenant{acme, globex, initech}, id: "initech", host: "api.example.com", want: "initech"},
+		{name: "header naming a host mapped tenant on an unmapped host", tenants: []Tenant{acme, globex}, id: "globex", host: "localhost:8080", want: "globex"},
+		{name: "unknown tenant", tenants: []Tenant{acme, globex}, id: "umbrella", host: "localhost", wantErr: ErrUnknownTenant},
+		{name: "no header on an unmapped host", tenants: []Tenant{acme, globex}, host: "localhost", wantErr: ErrUnknownTenant},
+		{name: "single tenant", tenants: []Tenant{initech}, host: "localhost", want: "initech"},
+		{name: "unknown tenant with a single tenant", tenants: []Tenant{initech}, id: "umbrella", host: "localhost", wantErr: ErrUnknownTenant},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			registry, err := NewRegistry(tt.tenants)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := registry.Resolve(tt.id, tt.host)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if got.ID != tt.want {
+				t.Errorf("tenant = %q, want %q", got.ID, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,69 @@
+package tracing
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestSetup(t *testing.T) {
+	previous := otel.GetTracerProvider()
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	tests := []struct {
+		exporter string
+		wantErr  bool
+		// wantSampled tells whether spans are sampled after Setup
+		wantSampled bool
+	}{
+		{exporter: "none"},
+		{exporter: "stdout", wantSampled: true},
+		{exporter: "zipkin", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.exporter, func(t *testing.T) {
+			otel.SetTracerProvider(previous)
+			shutdown, err := Setup(context.Background(), tt.exporter, "test", 1)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, want error %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			defer func() {
+				if err := shutdown(context.Background()); err != nil {
+					t.Errorf("shutdown: %v", err)
+				}
+			}()
+
+			_, span := otel.Tracer("test").Start(context.Background(), "call")
+			span.End()
+			if sampled := span.SpanContext().IsSampled(); sampled != tt.wantSampled {
+				t.Errorf("sampled = %v, want %v", sampled, tt.wantSampled)
+			}
+		})
+	}
+}
+
+func TestSetupPropagatesTraceContext(t *testing.T) {
+	shutdown, err := Setup(context.Background(), "none", "test", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer shutdown(context.Background())
+
+	// Incoming traceparent headers are honoured even without an exporter
+	header := http.Header{}
+	header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	ctx := otel.GetTextMapPropagator().Extract(context.Background(), propagation.HeaderCarrier(header))
+
+	spanContext := trace.SpanContextFromContext(ctx)
+	if spanContext.TraceID().String() != "4bf92f3577b34da6a3ce929d0e0e4736" || !spanContext.IsRemote() {
+		t.Errorf("span context = %+v, want the caller's trace", spanContext)
+	}
+}
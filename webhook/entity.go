@@ -0,0 +1,51 @@
+package webhook
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Subscription sends the events of EventTypes to URL, signed with Secret
+type Subscription struct {
+	ID         string   `json:"id"`
+	URL        string   `json:"url"`
+	EventTypes []string `json:"eventTypes"`
+	// Only returned when the subscription is created
+	Secret    string    `json:"secret,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// DeliveryStatus is the lifecycle state of a delivery
+type DeliveryStatus string
+
+const (
+	DeliveryPending    DeliveryStatus = "pending"
+	DeliveryDelivering DeliveryStatus = "delivering"
+	DeliverySucceeded  DeliveryStatus = "succeeded"
+	DeliveryFailed     DeliveryStatus = "failed"
+)
+
+// Delivery is an event on its way to a subscription, retried until the
+// partner answers with a 2xx status or the attempts run out
+type Delivery struct {
+	ID             string          `json:"id"`
+	SubscriptionID string          `json:"subscriptionID"`
+	EventID        string          `json:"eventID"`
+	EventType      string          `json:"eventType"`
+	Payload        json.RawMessage `json:"payload"`
+	Status         DeliveryStatus  `json:"status"`
+	// Tries counts the attempts since the delivery was created or replayed
+	Tries         int       `json:"tries"`
+	NextAttemptAt time.Time `json:"nextAttemptAt"`
+	Attempts      []Attempt `json:"attempts"`
+	CreatedAt     time.Time `json:"createdAt"`
+	UpdatedAt     time.Time `json:"updatedAt"`
+}
+
+// Attempt is the log of one request sent to the partner
+type Attempt struct {
+	At         time.Time `json:"at"`
+	StatusCode int       `json:"statusCode,omitempty"`
+	Error      string    `json:"error,omitempty"`
+	Duration   string    `json:"duration"`
+}
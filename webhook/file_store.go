@@ -0,0 +1,160 @@
+package webhook
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// fileStore keeps subscriptions and deliveries in memory and writes a JSON
+// snapshot to disk after every mutation, so queued deliveries survive
+// restarts
+type fileStore struct {
+	mu   sync.Mutex
+	path string
+	mem  *memoryStore
+}
+
+type fileSnapshot struct {
+	Subscriptions []Subscription `json:"subscriptions"`
+	Deliveries    []Delivery     `json:"deliveries"`
+}
+
+// NewFileStore returns a Store backed by the JSON file at path
+func NewFileStore(path string) (Store, error) {
+	s := &fileStore{
+		path: path,
+		mem:  newMemoryStore(),
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var snapshot fileSnapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return nil, err
+	}
+	for _, subscription := range snapshot.Subscriptions {
+		s.mem.subscriptions[subscription.ID] = subscription
+	}
+	for _, delivery := range snapshot.Deliveries {
+		// A delivery left `delivering` was interrupted by a restart, send it again
+		if delivery.Status == DeliveryDelivering {
+			delivery.Status = DeliveryPending
+		}
+		s.mem.deliveries[delivery.ID] = delivery
+	}
+
+	return s, nil
+}
+
+func (f *fileStore) CreateSubscription(subscription Subscription) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.mem.CreateSubscription(subscription); err != nil {
+		return err
+	}
+	return f.flush()
+}
+
+func (f *fileStore) GetSubscription(id string) (Subscription, error) {
+	return f.mem.GetSubscription(id)
+}
+
+func (f *fileStore) ListSubscriptions() ([]Subscription, error) {
+	return f.mem.ListSubscriptions()
+}
+
+func (f *fileStore) DeleteSubscription(id string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.mem.DeleteSubscription(id); err != nil {
+		return err
+	}
+	return f.flush()
+}
+
+func (f *fileStore) CreateDeliveries(deliveries []Delivery) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.mem.CreateDeliveries(deliveries); err != nil {
+		return err
+	}
+	return f.flush()
+}
+
+func (f *fileStore) GetDelivery(id string) (Delivery, error) {
+	return f.mem.GetDelivery(id)
+}
+
+func (f *fileStore) UpdateDelivery(id string, fn func(delivery *Delivery) error) (Delivery, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	delivery, err := f.mem.UpdateDelivery(id, fn)
+	if err != nil {
+		return Delivery{}, err
+	}
+	return delivery, f.flush()
+}
+
+func (f *fileStore) ListDeliveriesBySubscriptionID(subscriptionID string) ([]Delivery, error) {
+	return f.mem.ListDeliveriesBySubscriptionID(subscriptionID)
+}
+
+func (f *fileStore) ListDue(now time.Time) ([]Delivery, error) {
+	return f.mem.ListDue(now)
+}
+
+func (f *fileStore) DeleteFinishedBefore(t time.Time) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	deleted, err := f.mem.DeleteFinishedBefore(t)
+	if err != nil || deleted == 0 {
+		return deleted, err
+	}
+	return deleted, f.flush()
+}
+
+// flush writes the whole store to a temporary file and renames it over the
+// previous snapshot so a crash never leaves a half-written file behind
+func (f *fileStore) flush() error {
+	subscriptions, err := f.mem.ListSubscriptions()
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(fileSnapshot{
+		Subscriptions: subscriptions,
+		Deliveries:    f.mem.filter(func(Delivery) bool { return true }),
+	})
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(f.path), filepath.Base(f.path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), f.path)
+}
@@ -0,0 +1,167 @@
+package webhook
+
+import (
+	"encoding/json"
+	"errors"
+	"net/url"
+	"slices"
+	"time"
+
+	"github.com/wisnuanggoro/go-getstream/getstream"
+	"github.com/wisnuanggoro/go-getstream/uid"
+)
+
+var (
+	ErrInvalidURL         = errors.New("url must be an absolute http or https URL")
+	ErrInvalidEventTypes  = errors.New("eventTypes must list known event types")
+	ErrDeliveryInProgress = errors.New("webhook delivery is being sent")
+)
+
+// EventTypes partners can subscribe to
+var EventTypes = []string{
+	getstream.EventPostCreated,
+	getstream.EventPostDeleted,
+	getstream.EventFollowed,
+	getstream.EventFollowRequested,
+	getstream.EventUnfollowed,
+	getstream.EventLikeAdded,
+	getstream.EventLikeRemoved,
+}
+
+type service struct {
+	store Store
+}
+
+type Service interface {
+	CreateSubscription(url string, eventTypes []string) (*Subscription, error)
+	GetSubscriptions() ([]Subscription, error)
+	DeleteSubscription(subscriptionID string) error
+	GetDeliveriesBySubscriptionID(subscriptionID string) ([]Delivery, error)
+	// ReplayDelivery sends a delivery again whatever its outcome was
+	ReplayDelivery(deliveryID string) (*Delivery, error)
+	// Enqueue queues event for every subscription of its type
+	Enqueue(event getstream.Event) error
+}
+
+func NewService(store Store) Service {
+	return &service{
+		store: store,
+	}
+}
+
+func (s *service) CreateSubscription(rawURL string, eventTypes []string) (*Subscription, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, ErrInvalidURL
+	}
+	if len(eventTypes) == 0 {
+		return nil, ErrInvalidEventTypes
+	}
+	for _, eventType := range eventTypes {
+		if !slices.Contains(EventTypes, eventType) {
+			return nil, ErrInvalidEventTypes
+		}
+	}
+
+	secret, err := newSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	subscription := Subscription{
+		ID:         uid.New(),
+		URL:        u.String(),
+		EventTypes: eventTypes,
+		Secret:     secret,
+		CreatedAt:  time.Now().UTC(),
+	}
+	if err := s.store.CreateSubscription(subscription); err != nil {
+		return nil, err
+	}
+
+	return &subscription, nil
+}
+
+func (s *service) GetSubscriptions() ([]Subscription, error) {
+	subscriptions, err := s.store.ListSubscriptions()
+	if err != nil {
+		return nil, err
+	}
+
+	// Secrets are only shown once
+	for i := range subscriptions {
+		subscriptions[i].Secret = ""
+	}
+	return subscriptions, nil
+}
+
+func (s *service) DeleteSubscription(subscriptionID string) error {
+	return s.store.DeleteSubscription(subscriptionID)
+}
+
+func (s *service) GetDeliveriesBySubscriptionID(subscriptionID string) ([]Delivery, error) {
+	// Deliveries of a deleted subscription are gone from the API as well
+	_, err := s.store.GetSubscription(subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.store.ListDeliveriesBySubscriptionID(subscriptionID)
+}
+
+func (s *service) ReplayDelivery(deliveryID string) (*Delivery, error) {
+	delivery, err := s.store.UpdateDelivery(deliveryID, func(delivery *Delivery) error {
+		if delivery.Status == DeliveryDelivering {
+			return ErrDeliveryInProgress
+		}
+
+		now := time.Now().UTC()
+		delivery.Status = DeliveryPending
+		delivery.Tries = 0
+		delivery.NextAttemptAt = now
+		delivery.UpdatedAt = now
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &delivery, nil
+}
+
+func (s *service) Enqueue(event getstream.Event) error {
+	subscriptions, err := s.store.ListSubscriptions()
+	if err != nil {
+		return err
+	}
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().UTC()
+	deliveries := []Delivery{}
+	for _, subscription := range subscriptions {
+		if !slices.Contains(subscription.EventTypes, event.Type) {
+			continue
+		}
+		deliveries = append(deliveries, Delivery{
+			ID:             uid.New(),
+			SubscriptionID: subscription.ID,
+			EventID:        event.ID,
+			EventType:      event.Type,
+			Payload:        payload,
+			Status:         DeliveryPending,
+			NextAttemptAt:  now,
+			Attempts:       []Attempt{},
+			CreatedAt:      now,
+			UpdatedAt:      now,
+		})
+	}
+	if len(deliveries) == 0 {
+		return nil
+	}
+
+	return s.store.CreateDeliveries(deliveries)
+}
@@ -0,0 +1,38 @@
+package webhook
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSign(t *testing.T) {
+	// echo -n '1700000000.{"id":"1"}' | openssl dgst -sha256 -hmac whsec_test
+	want := "t=1700000000,v1=11bf4466ea17c3df3fd743af0b435368e16b7a05eb8eced85e8c4670767bdec5"
+	if got := Sign("whsec_test", time.Unix(1700000000, 0), []byte(`{"id":"1"}`)); got != want {
+		t.Errorf("Sign = %s, want %s", got, want)
+	}
+
+	// The timestamp is signed, so it can't be swapped for a fresher one
+	if Sign("whsec_test", time.Unix(1700000001, 0), []byte(`{"id":"1"}`))[13:] == want[13:] {
+		t.Error("signature doesn't cover the timestamp")
+	}
+}
+
+func TestNewSecret(t *testing.T) {
+	a, err := newSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := newSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.HasPrefix(a, "whsec_") || len(a) != len("whsec_")+64 {
+		t.Errorf("secret %q isn't whsec_ and 32 hex bytes", a)
+	}
+	if a == b {
+		t.Error("secrets repeat")
+	}
+}
@@ -0,0 +1,196 @@
+package webhook
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+var (
+	ErrSubscriptionNotFound = errors.New("webhook subscription not found")
+	ErrDeliveryNotFound     = errors.New("webhook delivery not found")
+)
+
+// Store persists subscriptions and the queue of deliveries
+type Store interface {
+	CreateSubscription(subscription Subscription) error
+	GetSubscription(id string) (Subscription, error)
+	ListSubscriptions() ([]Subscription, error)
+	DeleteSubscription(id string) error
+
+	CreateDeliveries(deliveries []Delivery) error
+	GetDelivery(id string) (Delivery, error)
+	// UpdateDelivery applies fn to the stored delivery atomically and saves
+	// the result unless fn returns an error
+	UpdateDelivery(id string, fn func(delivery *Delivery) error) (Delivery, error)
+	ListDeliveriesBySubscriptionID(subscriptionID string) ([]Delivery, error)
+	// ListDue returns pending deliveries whose NextAttemptAt is not after now
+	ListDue(now time.Time) ([]Delivery, error)
+	// DeleteFinishedBefore drops succeeded and failed deliveries last
+	// updated before t and returns how many there were
+	DeleteFinishedBefore(t time.Time) (int, error)
+}
+
+// NewStore builds the store selected by kind, either `memory` or `file`
+func NewStore(kind, filePath string) (Store, error) {
+	switch kind {
+	case "", "memory":
+		return NewMemoryStore(), nil
+	case "file":
+		return NewFileStore(filePath)
+	default:
+		return nil, fmt.Errorf("unknown webhook store %q", kind)
+	}
+}
+
+type memoryStore struct {
+	mu            sync.RWMutex
+	subscriptions map[string]Subscription
+	deliveries    map[string]Delivery
+}
+
+// NewMemoryStore returns a Store that loses undelivered events on restart
+func NewMemoryStore() Store {
+	return newMemoryStore()
+}
+
+func newMemoryStore() *memoryStore {
+	return &memoryStore{
+		subscriptions: map[string]Subscription{},
+		deliveries:    map[string]Delivery{},
+	}
+}
+
+func (m *memoryStore) CreateSubscription(subscription Subscription) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.subscriptions[subscription.ID] = subscription
+	return nil
+}
+
+func (m *memoryStore) GetSubscription(id string) (Subscription, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	subscription, ok := m.subscriptions[id]
+	if !ok {
+		return Subscription{}, ErrSubscriptionNotFound
+	}
+	return subscription, nil
+}
+
+func (m *memoryStore) ListSubscriptions() ([]Subscription, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	subscriptions := []Subscription{}
+	for _, subscription := range m.subscriptions {
+		subscriptions = append(subscriptions, subscription)
+	}
+
+	// Oldest first
+	sort.Slice(subscriptions, func(i, j int) bool {
+		return subscriptions[i].CreatedAt.Before(subscriptions[j].CreatedAt)
+	})
+	return subscriptions, nil
+}
+
+func (m *memoryStore) DeleteSubscription(id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.subscriptions[id]; !ok {
+		return ErrSubscriptionNotFound
+	}
+	delete(m.subscriptions, id)
+	return nil
+}
+
+func (m *memoryStore) CreateDeliveries(deliveries []Delivery) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, delivery := range deliveries {
+		m.deliveries[delivery.ID] = delivery
+	}
+	return nil
+}
+
+func (m *memoryStore) GetDelivery(id string) (Delivery, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	delivery, ok := m.deliveries[id]
+	if !ok {
+		return Delivery{}, ErrDeliveryNotFound
+	}
+	return delivery, nil
+}
+
+func (m *memoryStore) UpdateDelivery(id string, fn func(delivery *Delivery) error) (Delivery, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delivery, ok := m.deliveries[id]
+	if !ok {
+		return Delivery{}, ErrDeliveryNotFound
+	}
+	// fn gets its own log so a rejected update leaves the stored one alone
+	delivery.Attempts = append([]Attempt(nil), delivery.Attempts...)
+	if err := fn(&delivery); err != nil {
+		return Delivery{}, err
+	}
+	m.deliveries[id] = delivery
+	return delivery, nil
+}
+
+func (m *memoryStore) ListDeliveriesBySubscriptionID(subscriptionID string) ([]Delivery, error) {
+	return m.filter(func(delivery Delivery) bool {
+		return delivery.SubscriptionID == subscriptionID
+	}), nil
+}
+
+func (m *memoryStore) ListDue(now time.Time) ([]Delivery, error) {
+	return m.filter(func(delivery Delivery) bool {
+		return delivery.Status == DeliveryPending && !delivery.NextAttemptAt.After(now)
+	}), nil
+}
+
+func (m *memoryStore) DeleteFinishedBefore(t time.Time) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	deleted := 0
+	for id, delivery := range m.deliveries {
+		if isFinished(delivery) && delivery.UpdatedAt.Before(t) {
+			delete(m.deliveries, id)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+func (m *memoryStore) filter(keep func(delivery Delivery) bool) []Delivery {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	deliveries := []Delivery{}
+	for _, delivery := range m.deliveries {
+		if keep(delivery) {
+			deliveries = append(deliveries, delivery)
+		}
+	}
+
+	// Oldest first, partners mostly get events in the order they happened
+	sort.Slice(deliveries, func(i, j int) bool {
+		return deliveries[i].CreatedAt.Before(deliveries[j].CreatedAt)
+	})
+	return deliveries
+}
+
+func isFinished(delivery Delivery) bool {
+	return delivery.Status == DeliverySucceeded || delivery.Status == DeliveryFailed
+}
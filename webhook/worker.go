@@ -0,0 +1,198 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math/rand"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/wisnuanggoro/go-getstream/logging"
+)
+
+// Partners only need to acknowledge, their answer isn't read past this
+const maxResponseBodySize = 64 << 10
+
+// DeliveryPolicy bounds how deliveries are sent and retried
+type DeliveryPolicy struct {
+	PollInterval time.Duration
+	Timeout      time.Duration
+	Concurrency  int
+	// Attempts before a delivery is given up as failed
+	MaxAttempts    int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	// How long finished deliveries are kept for their logs
+	Retention time.Duration
+}
+
+// Worker sends queued deliveries to the partners
+type Worker struct {
+	store  Store
+	client *http.Client
+	policy DeliveryPolicy
+}
+
+func NewWorker(store Store, policy DeliveryPolicy) *Worker {
+	return &Worker{
+		store:  store,
+		client: &http.Client{Timeout: policy.Timeout},
+		policy: policy,
+	}
+}
+
+// Run polls the store every interval until ctx is cancelled
+func (w *Worker) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.policy.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		now := time.Now()
+		w.deliverDue(ctx, now)
+		w.prune(ctx, now)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (w *Worker) deliverDue(ctx context.Context, now time.Time) {
+	deliveries, err := w.store.ListDue(now)
+	if err != nil {
+		slog.ErrorContext(ctx, "list due webhook deliveries", slog.String("error", err.Error()))
+		return
+	}
+
+	// A slow partner only holds up one slot
+	slots := make(chan struct{}, max(w.policy.Concurrency, 1))
+	var wg sync.WaitGroup
+	for _, delivery := range deliveries {
+		if ctx.Err() != nil {
+			break
+		}
+
+		slots <- struct{}{}
+		wg.Add(1)
+		go func(deliveryID string) {
+			defer func() {
+				<-slots
+				wg.Done()
+			}()
+			// The delivery ID ties the logs to the delivery
+			w.deliver(logging.WithRequestID(ctx, "webhook-"+deliveryID), deliveryID)
+		}(delivery.ID)
+	}
+	wg.Wait()
+}
+
+func (w *Worker) deliver(ctx context.Context, deliveryID string) {
+	// Claim the delivery so a concurrent replay can't send it twice
+	delivery, err := w.store.UpdateDelivery(deliveryID, func(delivery *Delivery) error {
+		if delivery.Status != DeliveryPending {
+			return ErrDeliveryInProgress
+		}
+
+		delivery.Status = DeliveryDelivering
+		return nil
+	})
+	if err != nil {
+		return
+	}
+
+	attempt := Attempt{At: time.Now().UTC()}
+	subscription, err := w.store.GetSubscription(delivery.SubscriptionID)
+	if err == nil {
+		attempt.StatusCode, err = w.send(ctx, subscription, delivery)
+	}
+	attempt.Duration = time.Since(attempt.At).String()
+
+	// Shutting down isn't the partner's fault, try again after the restart
+	if ctx.Err() != nil {
+		w.update(ctx, deliveryID, func(delivery *Delivery) {
+			delivery.Status = DeliveryPending
+		})
+		return
+	}
+
+	w.update(ctx, deliveryID, func(delivery *Delivery) {
+		delivery.Tries++
+		switch {
+		case err == nil:
+			delivery.Status = DeliverySucceeded
+		case errors.Is(err, ErrSubscriptionNotFound) || delivery.Tries >= w.policy.MaxAttempts:
+			delivery.Status = DeliveryFailed
+		default:
+			delivery.Status = DeliveryPending
+			delivery.NextAttemptAt = time.Now().UTC().Add(w.backoff(delivery.Tries))
+		}
+		if err != nil {
+			attempt.Error = err.Error()
+		}
+		delivery.Attempts = append(delivery.Attempts, attempt)
+	})
+}
+
+// send posts the payload and returns the status code the partner answered with
+func (w *Worker) send(ctx context.Context, subscription Subscription, delivery Delivery) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, subscription.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "go-getstream-webhooks")
+	req.Header.Set("X-Webhook-ID", delivery.ID)
+	req.Header.Set("X-Webhook-Event", delivery.EventType)
+	req.Header.Set(SignatureHeader, Sign(subscription.Secret, time.Now(), delivery.Payload))
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, maxResponseBodySize))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("partner answered %s", resp.Status)
+	}
+	return resp.StatusCode, nil
+}
+
+func (w *Worker) update(ctx context.Context, deliveryID string, fn func(delivery *Delivery)) {
+	_, err := w.store.UpdateDelivery(deliveryID, func(delivery *Delivery) error {
+		fn(delivery)
+		delivery.UpdatedAt = time.Now().UTC()
+		return nil
+	})
+	if err != nil {
+		slog.ErrorContext(ctx, "update webhook delivery", slog.String("delivery_id", deliveryID), slog.String("error", err.Error()))
+	}
+}
+
+// backoff returns how long to wait after the given number of tries, with
+// full jitter over an exponentially growing window
+func (w *Worker) backoff(tries int) time.Duration {
+	window := w.policy.InitialBackoff << (tries - 1)
+	if window <= 0 || window > w.policy.MaxBackoff {
+		window = w.policy.MaxBackoff
+	}
+	return time.Duration(rand.Int63n(int64(window) + 1))
+}
+
+func (w *Worker) prune(ctx context.Context, now time.Time) {
+	deleted, err := w.store.DeleteFinishedBefore(now.Add(-w.policy.Retention))
+	if err != nil {
+		slog.ErrorContext(ctx, "prune webhook deliveries", slog.String("error", err.Error()))
+		return
+	}
+	if deleted > 0 {
+		slog.DebugContext(ctx, "pruned webhook deliveries", slog.Int("count", deleted))
+	}
+}
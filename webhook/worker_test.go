@@ -0,0 +1,159 @@
+package webhook
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testDeliveryPolicy() DeliveryPolicy {
+	return DeliveryPolicy{
+		PollInterval:   time.Minute,
+		Timeout:        time.Second,
+		Concurrency:    2,
+		MaxAttempts:    3,
+		InitialBackoff: time.Minute,
+		MaxBackoff:     time.Hour,
+		Retention:      time.Hour,
+	}
+}
+
+func TestWorkerDeliver(t *testing.T) {
+	tests := []struct {
+		name string
+		// partnerStatus is what the partner answers, zero for no subscription
+		partnerStatus int
+		tries         int
+		status        DeliveryStatus
+		wantStatus    DeliveryStatus
+		wantSent      bool
+		wantTries     int
+		wantRetry     bool
+		wantError     string
+	}{
+		{name: "acknowledged", partnerStatus: http.StatusNoContent, status: DeliveryPending, wantStatus: DeliverySucceeded, wantSent: true, wantTries: 1},
+		{name: "partner error is retried later", partnerStatus: http.StatusInternalServerError, status: DeliveryPending, wantStatus: DeliveryPending, wantSent: true, wantTries: 1, wantRetry: true, wantError: "partner answered 500 Internal Server Error"},
+		{name: "last attempt fails", partnerStatus: http.StatusBadGateway, tries: 2, status: DeliveryPending, wantStatus: DeliveryFailed, wantSent: true, wantTries: 3, wantError: "partner answered 502 Bad Gateway"},
+		{name: "deleted subscription fails at once", status: DeliveryPending, wantStatus: DeliveryFailed, wantTries: 1, wantError: ErrSubscriptionNotFound.Error()},
+		{name: "claimed delivery is left alone", partnerStatus: http.StatusOK, status: DeliveryDelivering, wantStatus: DeliveryDelivering},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var sent *http.Request
+			var sentBody string
+			partner := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, _ := io.ReadAll(r.Body)
+				sent, sentBody = r, string(body)
+				w.WriteHeader(tt.partnerStatus)
+			}))
+			defer partner.Close()
+
+			store := NewMemoryStore()
+			if tt.partnerStatus != 0 {
+				if err := store.CreateSubscription(Subscription{ID: "s1", URL: partner.URL, Secret: "whsec_test"}); err != nil {
+					t.Fatal(err)
+				}
+			}
+			now := time.Now().UTC()
+			payload := `{"id":"e1","type":"post.created"}`
+			if err := store.CreateDeliveries([]Delivery{{
+				ID: "d1", SubscriptionID: "s1", EventID: "e1", EventType: "post.created", Payload: []byte(payload),
+				Status: tt.status, Tries: tt.tries, NextAttemptAt: now,
+			}}); err != nil {
+				t.Fatal(err)
+			}
+
+			NewWorker(store, testDeliveryPolicy()).deliverDue(context.Background(), now)
+
+			delivery, err := store.GetDelivery("d1")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if delivery.Status != tt.wantStatus || delivery.Tries != tt.wantTries {
+				t.Errorf("got %q after %d tries, want %q after %d", delivery.Status, delivery.Tries, tt.wantStatus, tt.wantTries)
+			}
+			if retry := delivery.NextAttemptAt.After(now); retry != tt.wantRetry {
+				t.Errorf("next attempt at %v, want a later retry %v", delivery.NextAttemptAt, tt.wantRetry)
+			}
+			if tt.wantTries > 0 {
+				if len(delivery.Attempts) != 1 || delivery.Attempts[0].Error != tt.wantError {
+					t.Errorf("attempts = %+v, want one with error %q", delivery.Attempts, tt.wantError)
+				}
+			}
+
+			if (sent != nil) != tt.wantSent {
+				t.Fatalf("sent = %v, want %v", sent != nil, tt.wantSent)
+			}
+			if sent == nil {
+				return
+			}
+			if sentBody != payload {
+				t.Errorf("body = %s, want %s", sentBody, payload)
+			}
+			if sent.Header.Get("X-Webhook-ID") != "d1" || sent.Header.Get("X-Webhook-Event") != "post.created" {
+				t.Errorf("headers = %v", sent.Header)
+			}
+			// Partners check the signature against the timestamp it carries
+			signature := sent.Header.Get(SignatureHeader)
+			timestamp, _, _ := strings.Cut(strings.TrimPrefix(signature, "t="), ",")
+			sentAt, err := strconv.ParseInt(timestamp, 10, 64)
+			if err != nil {
+				t.Fatalf("signature %q has no timestamp", signature)
+			}
+			if want := Sign("whsec_test", time.Unix(sentAt, 0), []byte(payload)); signature != want {
+				t.Errorf("signature = %s, want %s", signature, want)
+			}
+		})
+	}
+}
+
+func TestWorkerBackoff(t *testing.T) {
+	w := NewWorker(NewMemoryStore(), DeliveryPolicy{InitialBackoff: time.Second, MaxBackoff: 10 * time.Second})
+
+	tests := []struct {
+		tries int
+		max   time.Duration
+	}{
+		{tries: 1, max: time.Second},
+		{tries: 3, max: 4 * time.Second},
+		{tries: 5, max: 10 * time.Second},
+		// Shifting past the width of a Duration wraps, it must stay capped
+		{tries: 80, max: 10 * time.Second},
+	}
+
+	for _, tt := range tests {
+		for i := 0; i < 50; i++ {
+			if got := w.backoff(tt.tries); got < 0 || got > tt.max {
+				t.Fatalf("backoff(%d) = %v, want within [0, %v]", tt.tries, got, tt.max)
+			}
+		}
+	}
+}
+
+func TestWorkerPrune(t *testing.T) {
+	store := NewMemoryStore()
+	old := time.Now().UTC().Add(-2 * time.Hour)
+	if err := store.CreateDeliveries([]Delivery{
+		{ID: "succeeded", Status: DeliverySucceeded, UpdatedAt: old},
+		{ID: "failed", Status: DeliveryFailed, UpdatedAt: old},
+		{ID: "pending", Status: DeliveryPending, UpdatedAt: old},
+		{ID: "recent", Status: DeliverySucceeded, UpdatedAt: time.Now().UTC()},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	NewWorker(store, testDeliveryPolicy()).prune(context.Background(), time.Now())
+
+	for id, wantKept := range map[string]bool{"succeeded": false, "failed": false, "pending": true, "recent": true} {
+		_, err := store.GetDelivery(id)
+		if kept := err == nil; kept != wantKept {
+			t.Errorf("%s kept = %v, want %v", id, kept, wantKept)
+		}
+	}
+}